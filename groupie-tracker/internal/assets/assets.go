@@ -0,0 +1,214 @@
+// internal/assets/assets.go
+package assets
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"io"
+	"io/fs"
+	"net/http"
+	"path"
+	"strings"
+	"sync"
+
+	"groupie-tracker/internal/models"
+)
+
+// hashLength is the number of hex digits of the content hash put in URLs
+const hashLength = 10
+
+// Cache-Control values for fingerprinted and plain asset URLs
+const (
+	immutableCache = "public, max-age=31536000, immutable"
+	revalidate     = "no-cache"
+)
+
+// Assets serves the templates and static files of the site. In production
+// mode everything is read once from the given file system and static URLs
+// carry a content hash; in dev mode files are re-read on every request.
+type Assets struct {
+	fsys fs.FS
+	dev  bool
+
+	mutex     sync.RWMutex
+	hashes    map[string]string
+	templates map[string]*template.Template
+}
+
+// New loads the assets from fsys, which must contain the "templates" and
+// "static" directories
+func New(fsys fs.FS, dev bool) (*Assets, error) {
+	a := &Assets{
+		fsys:      fsys,
+		dev:       dev,
+		hashes:    map[string]string{},
+		templates: map[string]*template.Template{},
+	}
+	if dev {
+		return a, nil
+	}
+
+	if err := a.hashStatic(); err != nil {
+		return nil, err
+	}
+
+	names, err := fs.Glob(fsys, "templates/*.html")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list templates: %w", err)
+	}
+	for _, name := range names {
+		tpl, err := a.parse(path.Base(name))
+		if err != nil {
+			return nil, err
+		}
+		a.templates[path.Base(name)] = tpl
+	}
+
+	return a, nil
+}
+
+// Template returns the named template, e.g. "index.html"
+func (a *Assets) Template(name string) (*template.Template, error) {
+	if a.dev {
+		return a.parse(name)
+	}
+
+	tpl, ok := a.templates[name]
+	if !ok {
+		return nil, fmt.Errorf("template %q not found", name)
+	}
+	return tpl, nil
+}
+
+// URL returns the public URL of a static file such as "css/styles.css",
+// fingerprinted with its content hash outside dev mode
+func (a *Assets) URL(name string) string {
+	name = strings.TrimPrefix(name, "/")
+	if a.dev {
+		return "/static/" + name
+	}
+
+	a.mutex.RLock()
+	hash, ok := a.hashes[name]
+	a.mutex.RUnlock()
+	if !ok {
+		return "/static/" + name
+	}
+
+	ext := path.Ext(name)
+	return "/static/" + strings.TrimSuffix(name, ext) + "." + hash + ext
+}
+
+// Handler serves static files under /static/. Fingerprinted URLs are cached
+// for a year; plain URLs must be revalidated.
+func (a *Assets) Handler() http.Handler {
+	return http.StripPrefix("/static/", http.HandlerFunc(a.serveStatic))
+}
+
+func (a *Assets) serveStatic(w http.ResponseWriter, r *http.Request) {
+	name := path.Clean(strings.TrimPrefix(r.URL.Path, "/"))
+	cacheControl := revalidate
+
+	// Resolve name.<hash>.ext to name.ext when the hash is current
+	if !a.dev {
+		if original, hash, ok := splitHash(name); ok {
+			a.mutex.RLock()
+			current := a.hashes[original]
+			a.mutex.RUnlock()
+			if current == hash {
+				name = original
+				cacheControl = immutableCache
+			}
+		}
+	}
+
+	f, err := a.fsys.Open("static/" + name)
+	if err != nil {
+		notFound(w)
+		return
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil || info.IsDir() {
+		notFound(w)
+		return
+	}
+
+	content, ok := f.(io.ReadSeeker)
+	if !ok {
+		writeError(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Cache-Control", cacheControl)
+	if !a.dev {
+		a.mutex.RLock()
+		hash := a.hashes[name]
+		a.mutex.RUnlock()
+		if hash != "" {
+			w.Header().Set("ETag", `"`+hash+`"`)
+		}
+	}
+
+	// Embedded files have a zero modification time; ETags cover revalidation
+	http.ServeContent(w, r, name, info.ModTime(), content)
+}
+
+func notFound(w http.ResponseWriter) {
+	writeError(w, "Not found", http.StatusNotFound)
+}
+
+// writeError writes a models.Error JSON body like the rest of the site
+func writeError(w http.ResponseWriter, message string, code int) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(models.Error{Code: code, Message: message})
+}
+
+// parse reads and parses a template, exposing the asset helper to it
+func (a *Assets) parse(name string) (*template.Template, error) {
+	src, err := fs.ReadFile(a.fsys, "templates/"+name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read template %s: %w", name, err)
+	}
+
+	tpl, err := template.New(name).Funcs(template.FuncMap{
+		"asset": a.URL,
+	}).Parse(string(src))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse template %s: %w", name, err)
+	}
+	return tpl, nil
+}
+
+// hashStatic fingerprints every static file
+func (a *Assets) hashStatic() error {
+	return fs.WalkDir(a.fsys, "static", func(p string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+
+		content, err := fs.ReadFile(a.fsys, p)
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", p, err)
+		}
+		sum := sha256.Sum256(content)
+		a.hashes[strings.TrimPrefix(p, "static/")] = hex.EncodeToString(sum[:])[:hashLength]
+		return nil
+	})
+}
+
+// splitHash turns "css/styles.0123456789.css" into "css/styles.css" and its hash
+func splitHash(name string) (string, string, bool) {
+	ext := path.Ext(name)
+	base := strings.TrimSuffix(name, ext)
+	dot := strings.LastIndex(base, ".")
+	if dot < 0 || len(base)-dot-1 != hashLength {
+		return "", "", false
+	}
+	return base[:dot] + ext, base[dot+1:], true
+}
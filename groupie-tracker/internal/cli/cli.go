@@ -0,0 +1,205 @@
+// internal/cli/cli.go
+package cli
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"groupie-tracker/internal/logging"
+	"groupie-tracker/internal/models"
+	"groupie-tracker/internal/service"
+	"groupie-tracker/internal/snapshot"
+)
+
+// cacheDuration is how long fetched upstream data stays fresh
+const cacheDuration = 1 * time.Hour
+
+// errUsage signals invalid command-line usage; the flag package has
+// already printed the details
+var errUsage = errors.New("invalid usage")
+
+// command is a subcommand of the CLI
+type command struct {
+	name    string
+	summary string
+	run     func(ctx context.Context, args []string) error
+}
+
+func commands() []command {
+	return []command{
+		{"serve", "start the web server (default)", runServe},
+		{"fetch", "download the upstream dataset into a file", runFetch},
+		{"export", "dump artists, events or a snapshot of the dataset", runExport},
+		{"import", "install the geocodes of a snapshot into the database", runImport},
+		{"geocode", "pre-warm the stored geocodes of every concert location", runGeocode},
+		{"validate", "check the dataset for inconsistencies", runValidate},
+	}
+}
+
+// Run executes the subcommand named by args[0] and returns the exit code.
+// Without a subcommand, or when the first argument is a flag, it serves.
+func Run(args []string) int {
+	name := "serve"
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		name, args = args[0], args[1:]
+	}
+
+	if name == "help" || name == "-h" || name == "--help" {
+		usage(os.Stdout)
+		return 0
+	}
+
+	for _, cmd := range commands() {
+		if cmd.name != name {
+			continue
+		}
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+
+		err := cmd.run(ctx, args)
+		switch {
+		case err == nil:
+			return 0
+		case errors.Is(err, errUsage), errors.Is(err, flag.ErrHelp):
+			return 2
+		default:
+			slog.Error(cmd.name+" failed", "error", err)
+			return 1
+		}
+	}
+
+	fmt.Fprintf(os.Stderr, "unknown command %q\n\n", name)
+	usage(os.Stderr)
+	return 2
+}
+
+func usage(w io.Writer) {
+	fmt.Fprintln(w, "Usage: groupie-tracker <command> [flags]")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Commands:")
+	for _, cmd := range commands() {
+		fmt.Fprintf(w, "  %-10s %s\n", cmd.name, cmd.summary)
+	}
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Run 'groupie-tracker <command> -h' for the flags of a command.")
+}
+
+// logOptions are the logging flags shared by every command
+type logOptions struct {
+	level  *string
+	format *string
+}
+
+func newFlagSet(name string) (*flag.FlagSet, *logOptions) {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	opts := &logOptions{
+		level:  fs.String("log-level", envOr("LOG_LEVEL", "info"), "log level: debug, info, warn or error"),
+		format: fs.String("log-format", envOr("LOG_FORMAT", logging.FormatText), "log format: text or json"),
+	}
+	return fs, opts
+}
+
+// parseFlags parses args and installs the configured logger as default
+func parseFlags(fs *flag.FlagSet, opts *logOptions, args []string, out io.Writer) (*slog.Logger, error) {
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+	if fs.NArg() > 0 {
+		fmt.Fprintf(fs.Output(), "unexpected arguments: %s\n", strings.Join(fs.Args(), " "))
+		return nil, errUsage
+	}
+
+	logger, err := logging.New(out, logging.Config{Level: *opts.level, Format: *opts.format})
+	if err != nil {
+		fmt.Fprintln(fs.Output(), err)
+		return nil, errUsage
+	}
+	slog.SetDefault(logger)
+
+	// Initialize models package with required constants
+	models.InitConstants(service.GetMapboxAccessToken(), service.GetMapboxGeocodingAPI())
+	return logger, nil
+}
+
+// loadSnapshot reads a snapshot or a dataset saved by fetch, or downloads
+// the dataset when from is empty
+func loadSnapshot(ctx context.Context, from string, logger *slog.Logger) (snapshot.Snapshot, error) {
+	if from != "" {
+		return snapshot.LoadFile(from)
+	}
+
+	fetchedAt := time.Now()
+	data, err := service.NewCacheService(cacheDuration, logger).Fetch(ctx)
+	if err != nil {
+		return snapshot.Snapshot{}, err
+	}
+	return snapshot.New(data, nil, service.UpstreamSource, fetchedAt), nil
+}
+
+// loadData is loadSnapshot for commands that only need the dataset
+func loadData(ctx context.Context, from string, logger *slog.Logger) (models.Datas, error) {
+	s, err := loadSnapshot(ctx, from, logger)
+	return s.Data, err
+}
+
+// createOutput opens path for writing, or stdout for "" and "-"
+func createOutput(path string) (io.WriteCloser, error) {
+	if path == "" || path == "-" {
+		return nopCloser{os.Stdout}, nil
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create %s: %w", path, err)
+	}
+	return f, nil
+}
+
+type nopCloser struct{ io.Writer }
+
+func (nopCloser) Close() error { return nil }
+
+// splitList splits a comma-separated list, dropping empty entries
+func splitList(s string) []string {
+	var items []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// envFloat returns the numeric environment variable value or a fallback
+func envFloat(key string, fallback float64) float64 {
+	if v, err := strconv.ParseFloat(os.Getenv(key), 64); err == nil {
+		return v
+	}
+	return fallback
+}
+
+// envInt returns the environment variable as an integer or a fallback
+func envInt(key string, fallback int) int {
+	if v, err := strconv.Atoi(os.Getenv(key)); err == nil {
+		return v
+	}
+	return fallback
+}
+
+// envOr returns the environment variable value or a fallback
+func envOr(key, fallback string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return fallback
+}
@@ -0,0 +1,154 @@
+// internal/cli/export.go
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+
+	"groupie-tracker/internal/export"
+	"groupie-tracker/internal/models"
+	"groupie-tracker/internal/service"
+	"groupie-tracker/internal/snapshot"
+	"groupie-tracker/internal/store"
+)
+
+func runExport(ctx context.Context, args []string) error {
+	fs, logOpts := newFlagSet("export")
+	what := fs.String("what", "artists", "what to export: artists, events or snapshot")
+	formatName := fs.String("format", "json", "output format of artists and events: json, ndjson, csv or ics (events only)")
+	geocodeCachePath := fs.String("geocode-cache", envOr("GEOCODE_CACHE", ""), "geocode cache file to include in snapshots")
+	dbPath := fs.String("db", envOr("DATABASE", "groupie-tracker.db"), "SQLite database whose geocodes snapshots include, when it exists")
+	out := fs.String("o", "-", "output file (- for stdout)")
+	from := fs.String("from", "", "snapshot or dataset file to read (default: download it)")
+
+	logger, err := parseFlags(fs, logOpts, args, os.Stderr)
+	if err != nil {
+		return err
+	}
+
+	format, err := export.ParseFormat(*formatName)
+	if err != nil {
+		fmt.Fprintln(fs.Output(), err)
+		return errUsage
+	}
+	switch *what {
+	case "artists":
+		if format == export.FormatICS {
+			fmt.Fprintln(fs.Output(), "artists cannot be exported as ics")
+			return errUsage
+		}
+	case "events":
+	case "snapshot":
+		return exportSnapshot(ctx, *from, *geocodeCachePath, *dbPath, *out, logger)
+	default:
+		fmt.Fprintf(fs.Output(), "unknown export %q\n", *what)
+		return errUsage
+	}
+
+	snap, err := loadSnapshot(ctx, *from, logger)
+	if err != nil {
+		return err
+	}
+	data := snap.Data
+
+	f, err := createOutput(*out)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	switch {
+	case *what == "events" && format == export.FormatICS:
+		cal := export.Calendar{Name: "Concerts", Stamp: snap.Meta.FetchedAt}
+		err = export.WriteCalendar(f, cal, export.Events(data, data.ArtistsData))
+	case *what == "events":
+		err = export.WriteEvents(f, format, export.Events(data, data.ArtistsData))
+	default:
+		err = export.WriteArtists(f, format, data.ArtistsData)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write export: %w", err)
+	}
+	return f.Close()
+}
+
+// exportSnapshot bundles the dataset with the geocodes known for its
+// locations into a compressed snapshot
+func exportSnapshot(ctx context.Context, from, geocodeCachePath, dbPath, out string, logger *slog.Logger) error {
+	snap, err := loadSnapshot(ctx, from, logger)
+	if err != nil {
+		return err
+	}
+
+	geocodes := service.NewGeocodeCache()
+	for address, loc := range snap.Geocodes {
+		geocodes.Put(address, loc)
+	}
+	if geocodeCachePath != "" {
+		if err := geocodes.LoadFile(geocodeCachePath); err != nil {
+			return err
+		}
+	}
+	if err := loadStoredGeocodes(ctx, geocodes, dbPath); err != nil {
+		return err
+	}
+
+	// Only keep geocodes of locations in the dataset
+	entries := make(map[string]models.GeoLocation)
+	for _, entry := range snap.Data.LocationsData.Index {
+		for _, loc := range entry.Locations {
+			if geo, ok := geocodes.Get(loc); ok {
+				entries[loc] = geo
+			}
+		}
+	}
+	snap = snapshot.New(snap.Data, entries, snap.Meta.Source, snap.Meta.FetchedAt)
+
+	if out == "" || out == "-" {
+		err = snapshot.Write(os.Stdout, snap)
+	} else {
+		err = snapshot.SaveFile(out, snap)
+	}
+	if err != nil {
+		return err
+	}
+
+	logger.Info("Snapshot exported",
+		"version", snap.Version,
+		"artists", snap.Meta.Artists,
+		"geocodes", snap.Meta.Locations,
+		"fetched_at", snap.Meta.FetchedAt,
+		"output", out,
+	)
+	return nil
+}
+
+// loadStoredGeocodes adds the geocodes of the database at path to geocodes,
+// without creating the database when it does not exist
+func loadStoredGeocodes(ctx context.Context, geocodes *service.GeocodeCache, path string) error {
+	if path == "" || path == ":memory:" {
+		return nil
+	}
+	if _, err := os.Stat(path); errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+
+	st, err := store.Open(ctx, path)
+	if err != nil {
+		return err
+	}
+	defer st.Close()
+
+	stored, err := st.Geocodes(ctx)
+	if err != nil {
+		return err
+	}
+	for address, loc := range stored {
+		geocodes.Put(address, loc)
+	}
+	return nil
+}
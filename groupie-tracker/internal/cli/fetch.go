@@ -0,0 +1,57 @@
+// internal/cli/fetch.go
+package cli
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+func runFetch(ctx context.Context, args []string) error {
+	fs, logOpts := newFlagSet("fetch")
+	out := fs.String("o", "-", "output file, gzip-compressed when it ends in .gz (- for stdout)")
+
+	logger, err := parseFlags(fs, logOpts, args, os.Stderr)
+	if err != nil {
+		return err
+	}
+
+	data, err := loadData(ctx, "", logger)
+	if err != nil {
+		return err
+	}
+
+	f, err := createOutput(*out)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	var w io.Writer = f
+	var zw *gzip.Writer
+	if strings.HasSuffix(*out, ".gz") {
+		zw = gzip.NewWriter(f)
+		w = zw
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(data); err != nil {
+		return fmt.Errorf("failed to write dataset: %w", err)
+	}
+	if zw != nil {
+		if err := zw.Close(); err != nil {
+			return fmt.Errorf("failed to write dataset: %w", err)
+		}
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("failed to write dataset: %w", err)
+	}
+
+	logger.Info("Dataset fetched", "artists", len(data.ArtistsData), "output", *out)
+	return nil
+}
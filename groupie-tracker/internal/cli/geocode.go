@@ -0,0 +1,92 @@
+// internal/cli/geocode.go
+package cli
+
+import (
+	"context"
+	"os"
+	"sort"
+	"time"
+
+	"groupie-tracker/internal/service"
+	"groupie-tracker/internal/store"
+)
+
+func runGeocode(ctx context.Context, args []string) error {
+	fs, logOpts := newFlagSet("geocode")
+	cachePath := fs.String("geocode-cache", envOr("GEOCODE_CACHE", ""), "geocode cache file to also fill")
+	dbPath := fs.String("db", envOr("DATABASE", "groupie-tracker.db"), "SQLite database to store geocodes in, as read by serve")
+	from := fs.String("from", "", "snapshot or dataset file to read (default: download it)")
+	delay := fs.Duration("delay", 100*time.Millisecond, "pause between Mapbox requests")
+
+	logger, err := parseFlags(fs, logOpts, args, os.Stderr)
+	if err != nil {
+		return err
+	}
+
+	geocodes := service.NewGeocodeCache()
+	if *cachePath != "" {
+		if err := geocodes.LoadFile(*cachePath); err != nil {
+			return err
+		}
+	}
+
+	// Results are written through to the database as they come
+	st, err := store.Open(ctx, *dbPath)
+	if err != nil {
+		return err
+	}
+	defer st.Close()
+	if err := geocodes.Attach(ctx, st); err != nil {
+		return err
+	}
+
+	data, err := loadData(ctx, *from, logger)
+	if err != nil {
+		return err
+	}
+
+	seen := make(map[string]bool)
+	var missing []string
+	for _, entry := range data.LocationsData.Index {
+		for _, loc := range entry.Locations {
+			if seen[loc] {
+				continue
+			}
+			seen[loc] = true
+			if _, ok := geocodes.Get(loc); !ok {
+				missing = append(missing, loc)
+			}
+		}
+	}
+	sort.Strings(missing)
+	logger.Info("Geocoding locations", "total", len(seen), "missing", len(missing))
+
+	// The search service only needs the cache to geocode
+	searchService := service.NewSearchService(nil, geocodes, logger)
+
+	failed := 0
+	for i, loc := range missing {
+		if ctx.Err() != nil {
+			break
+		}
+		if i > 0 && *delay > 0 {
+			select {
+			case <-ctx.Done():
+			case <-time.After(*delay):
+			}
+		}
+		if _, err := searchService.Geocode(ctx, loc); err != nil {
+			logger.Warn("geocoding failed", "location", loc, "error", err)
+			failed++
+		}
+	}
+
+	// Save whatever was resolved, even when interrupted
+	if *cachePath != "" {
+		if err := geocodes.SaveFile(*cachePath); err != nil {
+			return err
+		}
+	}
+	logger.Info("Geocodes saved", "db", *dbPath, "path", *cachePath, "entries", geocodes.Len(), "failed", failed)
+	return ctx.Err()
+}
@@ -0,0 +1,77 @@
+// internal/cli/import.go
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"groupie-tracker/internal/service"
+	"groupie-tracker/internal/snapshot"
+	"groupie-tracker/internal/store"
+)
+
+func runImport(ctx context.Context, args []string) error {
+	fs, logOpts := newFlagSet("import")
+	in := fs.String("i", "", "snapshot file to import")
+	geocodeCachePath := fs.String("geocode-cache", envOr("GEOCODE_CACHE", ""), "geocode cache file to also merge the snapshot's geocodes into")
+	dbPath := fs.String("db", envOr("DATABASE", "groupie-tracker.db"), "SQLite database to merge the snapshot's geocodes into, as read by serve")
+
+	logger, err := parseFlags(fs, logOpts, args, os.Stderr)
+	if err != nil {
+		return err
+	}
+	if *in == "" {
+		fmt.Fprintln(fs.Output(), "missing snapshot file (-i)")
+		return errUsage
+	}
+
+	snap, err := snapshot.LoadFile(*in)
+	if err != nil {
+		return err
+	}
+	if issues := service.ValidateData(snap.Data); len(issues) > 0 {
+		for _, issue := range issues {
+			logger.Warn("snapshot data issue", "issue", issue.String())
+		}
+	}
+
+	geocodes := service.NewGeocodeCache()
+	if *geocodeCachePath != "" {
+		if err := geocodes.LoadFile(*geocodeCachePath); err != nil {
+			return err
+		}
+	}
+
+	st, err := store.Open(ctx, *dbPath)
+	if err != nil {
+		return err
+	}
+	defer st.Close()
+	if err := geocodes.Attach(ctx, st); err != nil {
+		return err
+	}
+
+	before := geocodes.Len()
+	for address, loc := range snap.Geocodes {
+		if err := geocodes.Record(ctx, address, loc); err != nil {
+			return err
+		}
+	}
+	if *geocodeCachePath != "" {
+		if err := geocodes.SaveFile(*geocodeCachePath); err != nil {
+			return err
+		}
+	}
+
+	logger.Info("Snapshot imported",
+		"version", snap.Version,
+		"source", snap.Meta.Source,
+		"fetched_at", snap.Meta.FetchedAt,
+		"artists", snap.Meta.Artists,
+		"geocodes_added", geocodes.Len()-before,
+		"db", *dbPath,
+		"geocode_cache", *geocodeCachePath,
+	)
+	return ctx.Err()
+}
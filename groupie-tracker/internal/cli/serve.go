@@ -0,0 +1,272 @@
+// internal/cli/serve.go
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"groupie-tracker/internal/assets"
+	"groupie-tracker/internal/gql"
+	"groupie-tracker/internal/handlers"
+	"groupie-tracker/internal/middleware"
+	"groupie-tracker/internal/router"
+	"groupie-tracker/internal/service"
+	"groupie-tracker/internal/snapshot"
+	"groupie-tracker/internal/store"
+	"groupie-tracker/web"
+)
+
+// shutdownTimeout bounds how long in-flight requests may take to finish
+const shutdownTimeout = 10 * time.Second
+
+// maxAnnouncements bounds how many new concerts the feeds remember
+const maxAnnouncements = 500
+
+// maxSavedSearches bounds how many searches can be saved
+const maxSavedSearches = 10000
+
+func runServe(ctx context.Context, args []string) error {
+	fs, logOpts := newFlagSet("serve")
+	addr := fs.String("addr", envOr("ADDR", ":8000"), "address to listen on")
+	snapshotPath := fs.String("snapshot", envOr("SNAPSHOT", ""), "serve data from this snapshot instead of the upstream API")
+	geocodeCachePath := fs.String("geocode-cache", envOr("GEOCODE_CACHE", ""), "file to load geocoding results from and save them to on shutdown")
+	savedSearchesPath := fs.String("saved-searches", envOr("SAVED_SEARCHES", ""), "JSON file of searches saved by earlier releases, imported into the database")
+	dbPath := fs.String("db", envOr("DATABASE", "groupie-tracker.db"), "SQLite database of user accounts, geocodes and snapshots (:memory: for a throwaway one)")
+	corsOrigins := fs.String("cors-origins", envOr("CORS_ORIGINS", ""), "comma-separated origins allowed to call the API, or *")
+	hsts := fs.Bool("hsts", envOr("HSTS", "") == "true", "send Strict-Transport-Security (only behind HTTPS)")
+	apiRate := fs.Float64("api-rate", envFloat("API_RATE", 10), "API requests per second allowed per client and route (0 disables)")
+	apiBurst := fs.Int("api-burst", envInt("API_BURST", 20), "API request burst per client and route")
+	geocodeRate := fs.Float64("geocode-rate", envFloat("GEOCODE_RATE", 0.2), "requests per second per client on geocoding endpoints (0 disables)")
+	geocodeBurst := fs.Int("geocode-burst", envInt("GEOCODE_BURST", 5), "request burst per client on geocoding endpoints")
+	authRate := fs.Float64("auth-rate", envFloat("AUTH_RATE", 0.1), "sign-in and registration attempts per second per client (0 disables)")
+	authBurst := fs.Int("auth-burst", envInt("AUTH_BURST", 10), "sign-in and registration attempt burst per client")
+	dev := fs.Bool("dev", envOr("DEV", "") == "true", "reload templates and static files from disk on every request")
+	webDir := fs.String("web-dir", envOr("WEB_DIR", "web"), "directory of templates and static files used in dev mode")
+	trustProxy := fs.Bool("trust-proxy", envOr("TRUST_PROXY", "") == "true", "trust X-Forwarded-For and X-Forwarded-Proto from a reverse proxy")
+	baseURLFlag := fs.String("base-url", envOr("BASE_URL", ""), "public URL of the site for links in feeds, calendars and saved searches (default http://localhost and the -addr port)")
+
+	logger, err := parseFlags(fs, logOpts, args, os.Stdout)
+	if err != nil {
+		return err
+	}
+
+	baseURL, err := parseBaseURL(*baseURLFlag, *addr)
+	if err != nil {
+		fmt.Fprintf(fs.Output(), "-base-url: %v\n", err)
+		return errUsage
+	}
+
+	// A bucket that never holds a whole token would reject every request
+	for _, limit := range []struct {
+		name  string
+		rate  float64
+		burst int
+	}{
+		{"api", *apiRate, *apiBurst},
+		{"geocode", *geocodeRate, *geocodeBurst},
+		{"auth", *authRate, *authBurst},
+	} {
+		if limit.rate > 0 && limit.burst < 1 {
+			fmt.Fprintf(fs.Output(), "-%s-burst must be at least 1 while -%s-rate is set\n", limit.name, limit.name)
+			return errUsage
+		}
+	}
+
+	// Initialize services
+	geocodes := service.NewGeocodeCache()
+	if *geocodeCachePath != "" {
+		if err := geocodes.LoadFile(*geocodeCachePath); err != nil {
+			return err
+		}
+		logger.Info("Geocode cache loaded", "path", *geocodeCachePath, "entries", geocodes.Len())
+	}
+
+	st, err := store.Open(ctx, *dbPath)
+	if err != nil {
+		return err
+	}
+	defer st.Close()
+
+	savedSearches := service.NewSavedSearchService(st, maxSavedSearches)
+	if *savedSearchesPath != "" {
+		imported, err := savedSearches.ImportFile(ctx, *savedSearchesPath)
+		if err != nil {
+			return err
+		}
+		logger.Info("Saved searches imported", "path", *savedSearchesPath, "imported", imported)
+	}
+
+	var cacheOpts []service.CacheOption
+	if *snapshotPath != "" {
+		snap, err := snapshot.LoadFile(*snapshotPath)
+		if err != nil {
+			return err
+		}
+		for address, loc := range snap.Geocodes {
+			if _, ok := geocodes.Get(address); !ok {
+				geocodes.Put(address, loc)
+			}
+		}
+		cacheOpts = append(cacheOpts, service.WithSnapshot(snap.Data, service.CacheInfo{
+			FetchedAt: snap.Meta.FetchedAt,
+			Source:    snap.Meta.Source,
+		}))
+	} else {
+		cacheOpts = append(cacheOpts, service.WithStore(st))
+	}
+
+	if err := geocodes.Attach(ctx, st); err != nil {
+		return err
+	}
+	logger.Info("Geocodes loaded from database", "entries", geocodes.Len())
+
+	cacheService := service.NewCacheService(cacheDuration, logger, cacheOpts...)
+	filterService := service.NewFilterService(cacheService)
+	searchService := service.NewSearchService(cacheService, geocodes, logger)
+	catalogService := service.NewCatalogService(cacheService)
+	announcements := service.NewAnnouncementService(cacheService, maxAnnouncements, logger)
+	if err := announcements.Attach(ctx, st); err != nil {
+		return err
+	}
+	userService := service.NewUserService(st, logger)
+
+	graphQLService, err := gql.New(cacheService, searchService, filterService, searchService, gql.DefaultLimits())
+	if err != nil {
+		return fmt.Errorf("failed to build GraphQL schema: %w", err)
+	}
+
+	// Initialize cache with initial data
+	if err := cacheService.RefreshCache(ctx); err != nil {
+		return fmt.Errorf("failed to fetch initial data: %w", err)
+	}
+	logger.Info("Initial data fetched successfully")
+
+	// Load templates and static assets, embedded unless in dev mode
+	webFiles := web.Files()
+	if *dev {
+		webFiles = os.DirFS(*webDir)
+		logger.Info("Dev mode: serving templates and static files from disk", "dir", *webDir)
+	}
+	siteAssets, err := assets.New(webFiles, *dev)
+	if err != nil {
+		return fmt.Errorf("failed to load web assets: %w", err)
+	}
+
+	// Initialize handlers
+	h := handlers.NewHandler(handlers.Config{
+		Templates:      siteAssets,
+		CacheService:   cacheService,
+		FilterService:  filterService,
+		SearchService:  searchService,
+		CatalogService: catalogService,
+		Announcements:  announcements,
+		SavedSearches:  savedSearches,
+		UserService:    userService,
+		GraphQL:        graphQLService,
+		Logger:         logger,
+		TrustProxy:     *trustProxy,
+		BaseURL:        baseURL,
+	})
+
+	// Set up routes
+	apiLimit := middleware.Limit{Rate: *apiRate, Burst: *apiBurst}
+	geocodeLimit := middleware.Limit{Rate: *geocodeRate, Burst: *geocodeBurst}
+	authLimit := middleware.Limit{Rate: *authRate, Burst: *authBurst}
+	logger.Debug("Rate limits configured", "api", apiLimit.String(), "geocode", geocodeLimit.String(), "auth", authLimit.String())
+
+	mux := router.New(router.Config{
+		Handler:      h,
+		Static:       siteAssets.Handler(),
+		APILimit:     apiLimit,
+		GeocodeLimit: geocodeLimit,
+		AuthLimit:    authLimit,
+		TrustProxy:   *trustProxy,
+	})
+
+	// Apply middleware uniformly to every route
+	corsConfig := middleware.DefaultCORSConfig()
+	corsConfig.AllowedOrigins = splitList(*corsOrigins)
+
+	securityConfig := middleware.DefaultSecurityConfig()
+	securityConfig.HSTS = *hsts
+
+	handler := middleware.Chain(mux,
+		middleware.RequestID,
+		middleware.Logger(logger),
+		middleware.Recover(logger),
+		middleware.SecurityHeaders(securityConfig),
+		middleware.CORS(corsConfig),
+		middleware.Compress,
+	)
+
+	server := &http.Server{
+		Addr:         *addr,
+		Handler:      handler,
+		ErrorLog:     slog.NewLogLogger(logger.Handler(), slog.LevelError),
+		ReadTimeout:  15 * time.Second,
+		WriteTimeout: 15 * time.Second,
+		IdleTimeout:  60 * time.Second,
+	}
+
+	serveErr := make(chan error, 1)
+	go func() {
+		logger.Info("Server starting", "addr", *addr)
+		serveErr <- server.ListenAndServe()
+	}()
+
+	select {
+	case err := <-serveErr:
+		return fmt.Errorf("server failed: %w", err)
+	case <-ctx.Done():
+	}
+
+	logger.Info("Shutting down")
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := server.Shutdown(shutdownCtx); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		logger.Warn("Graceful shutdown failed", "error", err)
+	}
+
+	if *geocodeCachePath != "" {
+		if err := geocodes.SaveFile(*geocodeCachePath); err != nil {
+			return err
+		}
+		logger.Info("Geocode cache saved", "path", *geocodeCachePath, "entries", geocodes.Len())
+	}
+	return nil
+}
+
+// parseBaseURL checks the public URL of the site and drops its trailing
+// slash. Without one, links point at the local port of addr.
+func parseBaseURL(raw, addr string) (string, error) {
+	if raw == "" {
+		host, port, err := net.SplitHostPort(addr)
+		if err != nil {
+			return "", fmt.Errorf("cannot derive a base URL from -addr %q", addr)
+		}
+		if ip := net.ParseIP(host); host == "" || ip != nil && ip.IsUnspecified() {
+			host = "localhost"
+		}
+		return "http://" + net.JoinHostPort(host, port), nil
+	}
+
+	u, err := url.Parse(raw)
+	if err != nil {
+		return "", err
+	}
+	if u.Scheme != "http" && u.Scheme != "https" || u.Host == "" {
+		return "", fmt.Errorf("%q is not an absolute http or https URL", raw)
+	}
+	if u.RawQuery != "" || u.Fragment != "" {
+		return "", fmt.Errorf("%q must not have a query or fragment", raw)
+	}
+	return strings.TrimSuffix(u.String(), "/"), nil
+}
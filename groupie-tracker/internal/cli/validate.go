@@ -0,0 +1,36 @@
+// internal/cli/validate.go
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"groupie-tracker/internal/service"
+)
+
+func runValidate(ctx context.Context, args []string) error {
+	fs, logOpts := newFlagSet("validate")
+	from := fs.String("from", "", "snapshot or dataset file to read (default: download it)")
+
+	logger, err := parseFlags(fs, logOpts, args, os.Stderr)
+	if err != nil {
+		return err
+	}
+
+	data, err := loadData(ctx, *from, logger)
+	if err != nil {
+		return err
+	}
+
+	issues := service.ValidateData(data)
+	for _, issue := range issues {
+		fmt.Println(issue)
+	}
+	if len(issues) > 0 {
+		return fmt.Errorf("dataset has %d issues", len(issues))
+	}
+
+	logger.Info("Dataset is valid", "artists", len(data.ArtistsData))
+	return nil
+}
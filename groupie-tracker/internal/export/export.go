@@ -0,0 +1,179 @@
+// internal/export/export.go
+package export
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"groupie-tracker/internal/models"
+)
+
+// Format is an export file format
+type Format string
+
+// Supported formats
+const (
+	FormatJSON   Format = "json"
+	FormatNDJSON Format = "ndjson"
+	FormatCSV    Format = "csv"
+	FormatICS    Format = "ics"
+)
+
+// ParseFormat validates a format name
+func ParseFormat(name string) (Format, error) {
+	switch f := Format(strings.ToLower(name)); f {
+	case FormatJSON, FormatNDJSON, FormatCSV, FormatICS:
+		return f, nil
+	case "jsonl":
+		return FormatNDJSON, nil
+	}
+	return "", fmt.Errorf("unknown export format %q", name)
+}
+
+// ContentType returns the MIME type of the format
+func (f Format) ContentType() string {
+	switch f {
+	case FormatNDJSON:
+		return "application/x-ndjson"
+	case FormatCSV:
+		return "text/csv; charset=utf-8"
+	case FormatICS:
+		return "text/calendar; charset=utf-8"
+	}
+	return "application/json"
+}
+
+// Event is one concert of an artist, flattened for export
+type Event struct {
+	ArtistID int    `json:"artistId"`
+	Artist   string `json:"artist"`
+	Location string `json:"location"`
+	Place    string `json:"place"`
+	Date     string `json:"date"`
+}
+
+// Events flattens the relations of the given artists into concerts sorted
+// by artist and date
+func Events(data models.Datas, artists []models.Artist) []Event {
+	relations := make(map[int]map[string][]string, len(data.RelationsData.Index))
+	for _, entry := range data.RelationsData.Index {
+		relations[entry.ID] = entry.DatesLocations
+	}
+
+	var events []Event
+	for _, artist := range artists {
+		start := len(events)
+		for slug, dates := range relations[artist.ID] {
+			for _, raw := range dates {
+				date := strings.TrimPrefix(raw, "*")
+				if t, err := models.ParseConcertDate(raw); err == nil {
+					date = t.Format("2006-01-02")
+				}
+				events = append(events, Event{
+					ArtistID: artist.ID,
+					Artist:   artist.Name,
+					Location: slug,
+					Place:    models.FormatLocation(slug),
+					Date:     date,
+				})
+			}
+		}
+		group := events[start:]
+		sort.Slice(group, func(i, j int) bool {
+			if group[i].Date != group[j].Date {
+				return group[i].Date < group[j].Date
+			}
+			return group[i].Location < group[j].Location
+		})
+	}
+	return events
+}
+
+// artistHeader is the CSV header of artist exports
+var artistHeader = []string{"id", "name", "members", "memberCount", "creationDate", "firstAlbum", "image"}
+
+// eventHeader is the CSV header of event exports
+var eventHeader = []string{"artistId", "artist", "location", "place", "date"}
+
+// WriteArtists writes artists in the given format. Artists have no dates,
+// so the iCalendar format is not supported.
+func WriteArtists(w io.Writer, format Format, artists []models.Artist) error {
+	if format == FormatICS {
+		return fmt.Errorf("artists cannot be exported as %s", format)
+	}
+	return write(w, format, artistHeader, artists, func(a models.Artist) []string {
+		return []string{
+			strconv.Itoa(a.ID),
+			a.Name,
+			strings.Join(a.Members, "; "),
+			strconv.Itoa(len(a.Members)),
+			strconv.Itoa(a.CreationDate),
+			a.FirstAlbum,
+			a.Image,
+		}
+	})
+}
+
+// WriteEvents writes events in the given format
+func WriteEvents(w io.Writer, format Format, events []Event) error {
+	if format == FormatICS {
+		return WriteCalendar(w, Calendar{}, events)
+	}
+	return write(w, format, eventHeader, events, func(e Event) []string {
+		return []string{strconv.Itoa(e.ArtistID), e.Artist, e.Location, e.Place, e.Date}
+	})
+}
+
+// write encodes items one at a time so large exports are not buffered
+func write[T any](w io.Writer, format Format, header []string, items []T, row func(T) []string) error {
+	switch format {
+	case FormatCSV:
+		cw := csv.NewWriter(w)
+		if err := cw.Write(header); err != nil {
+			return err
+		}
+		for _, item := range items {
+			if err := cw.Write(row(item)); err != nil {
+				return err
+			}
+		}
+		cw.Flush()
+		return cw.Error()
+
+	case FormatNDJSON:
+		enc := json.NewEncoder(w)
+		for _, item := range items {
+			if err := enc.Encode(item); err != nil {
+				return err
+			}
+		}
+		return nil
+
+	case FormatJSON:
+		if _, err := io.WriteString(w, "[\n"); err != nil {
+			return err
+		}
+		for i, item := range items {
+			b, err := json.Marshal(item)
+			if err != nil {
+				return err
+			}
+			if i > 0 {
+				if _, err := io.WriteString(w, ",\n"); err != nil {
+					return err
+				}
+			}
+			if _, err := w.Write(b); err != nil {
+				return err
+			}
+		}
+		_, err := io.WriteString(w, "\n]\n")
+		return err
+	}
+	return fmt.Errorf("unknown export format %q", format)
+}
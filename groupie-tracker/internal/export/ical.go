@@ -0,0 +1,149 @@
+// internal/export/ical.go
+package export
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"groupie-tracker/internal/models"
+)
+
+// Calendar holds the calendar-wide properties of an iCalendar export
+type Calendar struct {
+	// Name is shown by calendar applications as the calendar title
+	Name string
+	// Stamp is the DTSTAMP of every event; use the time the data was
+	// fetched so repeated exports are identical. Defaults to now.
+	Stamp time.Time
+	// Refresh, when set, tells subscribed clients how often to poll
+	Refresh time.Duration
+	// Geocode, when set, returns the coordinates of a location slug; only
+	// events with known coordinates get a GEO property
+	Geocode func(location string) (models.GeoLocation, bool)
+}
+
+// maxLineOctets is the longest content line allowed by RFC 5545 before
+// folding, excluding the line break
+const maxLineOctets = 75
+
+// WriteCalendar writes events as an RFC 5545 calendar with one all-day
+// VEVENT per concert
+func WriteCalendar(w io.Writer, cal Calendar, events []Event) error {
+	cw := &calendarWriter{w: w}
+	if cal.Stamp.IsZero() {
+		cal.Stamp = time.Now()
+	}
+	stamp := cal.Stamp.UTC().Format("20060102T150405Z")
+
+	cw.line("BEGIN:VCALENDAR")
+	cw.line("VERSION:2.0")
+	cw.line("PRODID:-//groupie-tracker//concerts//EN")
+	cw.line("CALSCALE:GREGORIAN")
+	cw.line("METHOD:PUBLISH")
+	if cal.Name != "" {
+		cw.line("X-WR-CALNAME:" + escapeText(cal.Name))
+	}
+	if cal.Refresh > 0 {
+		cw.line("REFRESH-INTERVAL;VALUE=DURATION:" + formatDuration(cal.Refresh))
+		cw.line("X-PUBLISHED-TTL:" + formatDuration(cal.Refresh))
+	}
+
+	for _, e := range events {
+		day, err := time.Parse("2006-01-02", e.Date)
+		if err != nil {
+			// Undated concerts cannot be placed on a calendar
+			continue
+		}
+		cw.line("BEGIN:VEVENT")
+		cw.line("UID:" + EventUID(e))
+		cw.line("DTSTAMP:" + stamp)
+		cw.line("DTSTART;VALUE=DATE:" + day.Format("20060102"))
+		cw.line("DTEND;VALUE=DATE:" + day.AddDate(0, 0, 1).Format("20060102"))
+		cw.line("SUMMARY:" + escapeText(e.Artist+" in "+e.Place))
+		cw.line("LOCATION:" + escapeText(e.Place))
+		if cal.Geocode != nil {
+			if geo, ok := cal.Geocode(e.Location); ok {
+				cw.line(fmt.Sprintf("GEO:%.6f;%.6f", geo.Lat, geo.Lon))
+			}
+		}
+		cw.line("TRANSP:TRANSPARENT")
+		cw.line("END:VEVENT")
+		if cw.err != nil {
+			return cw.err
+		}
+	}
+
+	cw.line("END:VCALENDAR")
+	return cw.err
+}
+
+// EventUID identifies a concert independently of export time, so calendar
+// clients update rather than duplicate events on re-import
+func EventUID(e Event) string {
+	return fmt.Sprintf("%d-%s-%s@groupie-tracker", e.ArtistID, e.Location, strings.ReplaceAll(e.Date, "-", ""))
+}
+
+// formatDuration formats a duration as an RFC 5545 DURATION value with
+// minute precision
+func formatDuration(d time.Duration) string {
+	minutes := int(d.Minutes())
+	if minutes < 1 {
+		minutes = 1
+	}
+	if minutes%60 == 0 {
+		return fmt.Sprintf("PT%dH", minutes/60)
+	}
+	return fmt.Sprintf("PT%dM", minutes)
+}
+
+// calendarWriter writes folded CRLF-terminated content lines, keeping the
+// first error
+type calendarWriter struct {
+	w   io.Writer
+	err error
+}
+
+func (cw *calendarWriter) line(s string) {
+	if cw.err != nil {
+		return
+	}
+	_, cw.err = io.WriteString(cw.w, fold(s)+"\r\n")
+}
+
+// fold splits a content line into chunks of at most 75 octets, never inside
+// a UTF-8 sequence; continuation lines start with a space
+func fold(s string) string {
+	if len(s) <= maxLineOctets {
+		return s
+	}
+
+	var b strings.Builder
+	limit := maxLineOctets
+	for len(s) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(s[cut]) {
+			cut--
+		}
+		b.WriteString(s[:cut])
+		b.WriteString("\r\n ")
+		s = s[cut:]
+		// The leading space counts towards the continuation line's length
+		limit = maxLineOctets - 1
+	}
+	b.WriteString(s)
+	return b.String()
+}
+
+// escapeText escapes a TEXT property value
+func escapeText(s string) string {
+	return strings.NewReplacer(
+		`\`, `\\`,
+		";", `\;`,
+		",", `\,`,
+		"\r\n", `\n`,
+		"\n", `\n`,
+	).Replace(s)
+}
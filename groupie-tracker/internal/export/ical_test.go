@@ -0,0 +1,116 @@
+// internal/export/ical_test.go
+package export
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"groupie-tracker/internal/models"
+)
+
+func TestFold(t *testing.T) {
+	tests := []struct {
+		name string
+		line string
+	}{
+		{"short", "SUMMARY:Queen in Paris"},
+		{"exactly the limit", "SUMMARY:" + strings.Repeat("a", maxLineOctets-len("SUMMARY:"))},
+		{"long ascii", "SUMMARY:" + strings.Repeat("abcdefghij", 20)},
+		{"two-byte runes", "SUMMARY:" + strings.Repeat("é", 100)},
+		{"four-byte runes", "SUMMARY:" + strings.Repeat("🎸", 50)},
+		{"mixed", "LOCATION:" + strings.Repeat("Zürich, ", 20)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			folded := fold(tt.line)
+			lines := strings.Split(folded, "\r\n")
+			for i, line := range lines {
+				if len(line) > maxLineOctets {
+					t.Errorf("line %d is %d octets long, want at most %d", i, len(line), maxLineOctets)
+				}
+				if i > 0 && !strings.HasPrefix(line, " ") {
+					t.Errorf("continuation line %d does not start with a space: %q", i, line)
+				}
+				if !utf8.ValidString(line) {
+					t.Errorf("line %d splits a UTF-8 sequence: %q", i, line)
+				}
+			}
+			if len(tt.line) <= maxLineOctets && len(lines) != 1 {
+				t.Errorf("a line of %d octets was folded", len(tt.line))
+			}
+			if unfolded := strings.ReplaceAll(folded, "\r\n ", ""); unfolded != tt.line {
+				t.Errorf("unfolding gives %q, want %q", unfolded, tt.line)
+			}
+		})
+	}
+}
+
+func TestEscapeText(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"Queen", "Queen"},
+		{"Paris, France", `Paris\, France`},
+		{"a;b", `a\;b`},
+		{`back\slash`, `back\\slash`},
+		{"line\nbreak", `line\nbreak`},
+		{"windows\r\nbreak", `windows\nbreak`},
+		{`all\;,` + "\n", `all\\\;\,\n`},
+	}
+	for _, tt := range tests {
+		if got := escapeText(tt.in); got != tt.want {
+			t.Errorf("escapeText(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestWriteCalendar(t *testing.T) {
+	cal := Calendar{
+		Name:    "Concerts; live",
+		Stamp:   time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC),
+		Refresh: 12 * time.Hour,
+		Geocode: func(location string) (models.GeoLocation, bool) {
+			if location == "paris-france" {
+				return models.GeoLocation{Lat: 48.8566, Lon: 2.3522}, true
+			}
+			return models.GeoLocation{}, false
+		},
+	}
+	events := []Event{
+		{ArtistID: 1, Artist: "Queen", Location: "paris-france", Place: "Paris, France", Date: "2027-06-01"},
+		{ArtistID: 1, Artist: "Queen", Location: "lyon-france", Place: "Lyon, France", Date: "2027-06-03"},
+		{ArtistID: 2, Artist: "Undated", Location: "lyon-france", Place: "Lyon, France", Date: "soon"},
+	}
+
+	var buf bytes.Buffer
+	if err := WriteCalendar(&buf, cal, events); err != nil {
+		t.Fatalf("WriteCalendar: %v", err)
+	}
+	out := buf.String()
+
+	if !strings.HasSuffix(out, "END:VCALENDAR\r\n") || strings.Contains(strings.ReplaceAll(out, "\r\n", ""), "\n") {
+		t.Error("content lines are not all CRLF-terminated")
+	}
+	for _, want := range []string{
+		"X-WR-CALNAME:Concerts\\; live\r\n",
+		"REFRESH-INTERVAL;VALUE=DURATION:PT12H\r\n",
+		"UID:1-paris-france-20270601@groupie-tracker\r\n",
+		"DTSTAMP:20260102T030405Z\r\n",
+		"DTSTART;VALUE=DATE:20270601\r\nDTEND;VALUE=DATE:20270602\r\n",
+		"SUMMARY:Queen in Paris\\, France\r\n",
+		"GEO:48.856600;2.352200\r\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("calendar lacks %q", want)
+		}
+	}
+	if n := strings.Count(out, "BEGIN:VEVENT"); n != 2 {
+		t.Errorf("calendar has %d events, want 2 without the undated one", n)
+	}
+	if n := strings.Count(out, "GEO:"); n != 1 {
+		t.Errorf("calendar has %d GEO properties, want 1 for the geocoded location", n)
+	}
+}
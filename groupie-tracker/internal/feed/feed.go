@@ -0,0 +1,192 @@
+// internal/feed/feed.go
+package feed
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"time"
+)
+
+// Feed is a syndication feed that can be written as Atom or RSS
+type Feed struct {
+	// ID is a permanent, host-independent identifier such as a tag URI
+	ID      string
+	Title   string
+	Link    string // absolute URL of the HTML page the feed describes
+	Self    string // absolute URL of the feed itself
+	Updated time.Time
+	Entries []Entry
+}
+
+// Entry is one item of a feed
+type Entry struct {
+	ID      string
+	Title   string
+	Link    string
+	Summary string
+	Updated time.Time
+}
+
+// TagURI builds an RFC 4151 tag URI, used for stable feed and entry IDs
+func TagURI(specific string) string {
+	return "tag:groupie-tracker,2024:" + specific
+}
+
+const (
+	atomNamespace = "http://www.w3.org/2005/Atom"
+	// ContentTypeAtom is the MIME type of Atom feeds
+	ContentTypeAtom = "application/atom+xml; charset=utf-8"
+	// ContentTypeRSS is the MIME type of RSS feeds
+	ContentTypeRSS = "application/rss+xml; charset=utf-8"
+)
+
+type atomFeed struct {
+	XMLName xml.Name    `xml:"feed"`
+	XMLNS   string      `xml:"xmlns,attr"`
+	ID      string      `xml:"id"`
+	Title   string      `xml:"title"`
+	Updated string      `xml:"updated"`
+	Links   []atomLink  `xml:"link"`
+	Author  atomAuthor  `xml:"author"`
+	Entries []atomEntry `xml:"entry"`
+}
+
+type atomLink struct {
+	Rel  string `xml:"rel,attr,omitempty"`
+	Type string `xml:"type,attr,omitempty"`
+	Href string `xml:"href,attr"`
+}
+
+type atomAuthor struct {
+	Name string `xml:"name"`
+}
+
+type atomEntry struct {
+	ID      string   `xml:"id"`
+	Title   string   `xml:"title"`
+	Updated string   `xml:"updated"`
+	Link    atomLink `xml:"link"`
+	Summary string   `xml:"summary"`
+}
+
+// WriteAtom writes the feed as Atom 1.0 (RFC 4287)
+func WriteAtom(w io.Writer, f Feed) error {
+	doc := atomFeed{
+		XMLNS:   atomNamespace,
+		ID:      f.ID,
+		Title:   f.Title,
+		Updated: atomTime(f.Updated),
+		Links: []atomLink{
+			{Rel: "alternate", Type: "text/html", Href: f.Link},
+			{Rel: "self", Type: "application/atom+xml", Href: f.Self},
+		},
+		Author: atomAuthor{Name: "Groupie Tracker"},
+	}
+	for _, e := range f.Entries {
+		doc.Entries = append(doc.Entries, atomEntry{
+			ID:      e.ID,
+			Title:   e.Title,
+			Updated: atomTime(e.Updated),
+			Link:    atomLink{Rel: "alternate", Type: "text/html", Href: e.Link},
+			Summary: e.Summary,
+		})
+	}
+	return encode(w, doc)
+}
+
+type rssDocument struct {
+	XMLName xml.Name   `xml:"rss"`
+	Version string     `xml:"version,attr"`
+	Atom    string     `xml:"xmlns:atom,attr"`
+	Channel rssChannel `xml:"channel"`
+}
+
+type rssChannel struct {
+	Title         string    `xml:"title"`
+	Link          string    `xml:"link"`
+	Description   string    `xml:"description"`
+	Self          atomLink  `xml:"atom:link"`
+	LastBuildDate string    `xml:"lastBuildDate"`
+	Items         []rssItem `xml:"item"`
+}
+
+type rssItem struct {
+	Title       string  `xml:"title"`
+	Link        string  `xml:"link"`
+	Description string  `xml:"description"`
+	GUID        rssGUID `xml:"guid"`
+	PubDate     string  `xml:"pubDate"`
+}
+
+type rssGUID struct {
+	IsPermaLink bool   `xml:"isPermaLink,attr"`
+	Value       string `xml:",chardata"`
+}
+
+// WriteRSS writes the feed as RSS 2.0
+func WriteRSS(w io.Writer, f Feed) error {
+	doc := rssDocument{
+		Version: "2.0",
+		Atom:    atomNamespace,
+		Channel: rssChannel{
+			Title:         f.Title,
+			Link:          f.Link,
+			Description:   f.Title,
+			Self:          atomLink{Rel: "self", Type: "application/rss+xml", Href: f.Self},
+			LastBuildDate: f.Updated.UTC().Format(time.RFC1123Z),
+		},
+	}
+	for _, e := range f.Entries {
+		doc.Channel.Items = append(doc.Channel.Items, rssItem{
+			Title:       e.Title,
+			Link:        e.Link,
+			Description: e.Summary,
+			GUID:        rssGUID{Value: e.ID},
+			PubDate:     e.Updated.UTC().Format(time.RFC1123Z),
+		})
+	}
+	return encode(w, doc)
+}
+
+func encode(w io.Writer, doc interface{}) error {
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return fmt.Errorf("failed to encode feed: %w", err)
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+func atomTime(t time.Time) string {
+	return t.UTC().Format(time.RFC3339)
+}
+
+// Format is a feed format
+type Format string
+
+// Supported formats
+const (
+	Atom Format = "atom"
+	RSS  Format = "rss"
+)
+
+// ContentType returns the MIME type of the format
+func (f Format) ContentType() string {
+	if f == RSS {
+		return ContentTypeRSS
+	}
+	return ContentTypeAtom
+}
+
+// Write writes the feed in the given format
+func Write(w io.Writer, format Format, f Feed) error {
+	if format == RSS {
+		return WriteRSS(w, f)
+	}
+	return WriteAtom(w, f)
+}
@@ -0,0 +1,417 @@
+// internal/geo/countries.go
+package geo
+
+import "strings"
+
+// Continent codes
+const (
+	Africa       = "AF"
+	Antarctica   = "AN"
+	Asia         = "AS"
+	Europe       = "EU"
+	NorthAmerica = "NA"
+	Oceania      = "OC"
+	SouthAmerica = "SA"
+)
+
+// continentNames maps continent codes to display names
+var continentNames = map[string]string{
+	Africa:       "Africa",
+	Antarctica:   "Antarctica",
+	Asia:         "Asia",
+	Europe:       "Europe",
+	NorthAmerica: "North America",
+	Oceania:      "Oceania",
+	SouthAmerica: "South America",
+}
+
+// Country is a country with its ISO 3166-1 alpha-2 code
+type Country struct {
+	Code      string `json:"code"`
+	Name      string `json:"name"`
+	Continent string `json:"continent"`
+}
+
+// countries is an offline gazetteer keyed by ISO code
+var countries = map[string]Country{
+	"AF": {"AF", "Afghanistan", Asia},
+	"AL": {"AL", "Albania", Europe},
+	"DZ": {"DZ", "Algeria", Africa},
+	"AD": {"AD", "Andorra", Europe},
+	"AO": {"AO", "Angola", Africa},
+	"AR": {"AR", "Argentina", SouthAmerica},
+	"AM": {"AM", "Armenia", Asia},
+	"AU": {"AU", "Australia", Oceania},
+	"AT": {"AT", "Austria", Europe},
+	"AZ": {"AZ", "Azerbaijan", Asia},
+	"BS": {"BS", "Bahamas", NorthAmerica},
+	"BH": {"BH", "Bahrain", Asia},
+	"BD": {"BD", "Bangladesh", Asia},
+	"BB": {"BB", "Barbados", NorthAmerica},
+	"BY": {"BY", "Belarus", Europe},
+	"BE": {"BE", "Belgium", Europe},
+	"BZ": {"BZ", "Belize", NorthAmerica},
+	"BJ": {"BJ", "Benin", Africa},
+	"BT": {"BT", "Bhutan", Asia},
+	"BO": {"BO", "Bolivia", SouthAmerica},
+	"BA": {"BA", "Bosnia and Herzegovina", Europe},
+	"BW": {"BW", "Botswana", Africa},
+	"BR": {"BR", "Brazil", SouthAmerica},
+	"BN": {"BN", "Brunei", Asia},
+	"BG": {"BG", "Bulgaria", Europe},
+	"BF": {"BF", "Burkina Faso", Africa},
+	"KH": {"KH", "Cambodia", Asia},
+	"CM": {"CM", "Cameroon", Africa},
+	"CA": {"CA", "Canada", NorthAmerica},
+	"CL": {"CL", "Chile", SouthAmerica},
+	"CN": {"CN", "China", Asia},
+	"CO": {"CO", "Colombia", SouthAmerica},
+	"CR": {"CR", "Costa Rica", NorthAmerica},
+	"HR": {"HR", "Croatia", Europe},
+	"CU": {"CU", "Cuba", NorthAmerica},
+	"CY": {"CY", "Cyprus", Europe},
+	"CZ": {"CZ", "Czechia", Europe},
+	"DK": {"DK", "Denmark", Europe},
+	"DO": {"DO", "Dominican Republic", NorthAmerica},
+	"EC": {"EC", "Ecuador", SouthAmerica},
+	"EG": {"EG", "Egypt", Africa},
+	"SV": {"SV", "El Salvador", NorthAmerica},
+	"EE": {"EE", "Estonia", Europe},
+	"ET": {"ET", "Ethiopia", Africa},
+	"FJ": {"FJ", "Fiji", Oceania},
+	"FI": {"FI", "Finland", Europe},
+	"FR": {"FR", "France", Europe},
+	"PF": {"PF", "French Polynesia", Oceania},
+	"GE": {"GE", "Georgia", Asia},
+	"DE": {"DE", "Germany", Europe},
+	"GH": {"GH", "Ghana", Africa},
+	"GR": {"GR", "Greece", Europe},
+	"GT": {"GT", "Guatemala", NorthAmerica},
+	"HN": {"HN", "Honduras", NorthAmerica},
+	"HK": {"HK", "Hong Kong", Asia},
+	"HU": {"HU", "Hungary", Europe},
+	"IS": {"IS", "Iceland", Europe},
+	"IN": {"IN", "India", Asia},
+	"ID": {"ID", "Indonesia", Asia},
+	"IR": {"IR", "Iran", Asia},
+	"IQ": {"IQ", "Iraq", Asia},
+	"IE": {"IE", "Ireland", Europe},
+	"IL": {"IL", "Israel", Asia},
+	"IT": {"IT", "Italy", Europe},
+	"CI": {"CI", "Côte d'Ivoire", Africa},
+	"JM": {"JM", "Jamaica", NorthAmerica},
+	"JP": {"JP", "Japan", Asia},
+	"JO": {"JO", "Jordan", Asia},
+	"KZ": {"KZ", "Kazakhstan", Asia},
+	"KE": {"KE", "Kenya", Africa},
+	"KW": {"KW", "Kuwait", Asia},
+	"KG": {"KG", "Kyrgyzstan", Asia},
+	"LA": {"LA", "Laos", Asia},
+	"LV": {"LV", "Latvia", Europe},
+	"LB": {"LB", "Lebanon", Asia},
+	"LI": {"LI", "Liechtenstein", Europe},
+	"LT": {"LT", "Lithuania", Europe},
+	"LU": {"LU", "Luxembourg", Europe},
+	"MO": {"MO", "Macau", Asia},
+	"MG": {"MG", "Madagascar", Africa},
+	"MY": {"MY", "Malaysia", Asia},
+	"MV": {"MV", "Maldives", Asia},
+	"MT": {"MT", "Malta", Europe},
+	"MU": {"MU", "Mauritius", Africa},
+	"MX": {"MX", "Mexico", NorthAmerica},
+	"MD": {"MD", "Moldova", Europe},
+	"MC": {"MC", "Monaco", Europe},
+	"MN": {"MN", "Mongolia", Asia},
+	"ME": {"ME", "Montenegro", Europe},
+	"MA": {"MA", "Morocco", Africa},
+	"MZ": {"MZ", "Mozambique", Africa},
+	"MM": {"MM", "Myanmar", Asia},
+	"NA": {"NA", "Namibia", Africa},
+	"NP": {"NP", "Nepal", Asia},
+	"NL": {"NL", "Netherlands", Europe},
+	"CW": {"CW", "Curaçao", NorthAmerica},
+	"NC": {"NC", "New Caledonia", Oceania},
+	"NZ": {"NZ", "New Zealand", Oceania},
+	"NI": {"NI", "Nicaragua", NorthAmerica},
+	"NG": {"NG", "Nigeria", Africa},
+	"MK": {"MK", "North Macedonia", Europe},
+	"NO": {"NO", "Norway", Europe},
+	"OM": {"OM", "Oman", Asia},
+	"PK": {"PK", "Pakistan", Asia},
+	"PA": {"PA", "Panama", NorthAmerica},
+	"PG": {"PG", "Papua New Guinea", Oceania},
+	"PY": {"PY", "Paraguay", SouthAmerica},
+	"PE": {"PE", "Peru", SouthAmerica},
+	"PH": {"PH", "Philippines", Asia},
+	"PL": {"PL", "Poland", Europe},
+	"PT": {"PT", "Portugal", Europe},
+	"PR": {"PR", "Puerto Rico", NorthAmerica},
+	"QA": {"QA", "Qatar", Asia},
+	"RE": {"RE", "Réunion", Africa},
+	"RO": {"RO", "Romania", Europe},
+	"RU": {"RU", "Russia", Europe},
+	"RW": {"RW", "Rwanda", Africa},
+	"SM": {"SM", "San Marino", Europe},
+	"SA": {"SA", "Saudi Arabia", Asia},
+	"SN": {"SN", "Senegal", Africa},
+	"RS": {"RS", "Serbia", Europe},
+	"SG": {"SG", "Singapore", Asia},
+	"SK": {"SK", "Slovakia", Europe},
+	"SI": {"SI", "Slovenia", Europe},
+	"ZA": {"ZA", "South Africa", Africa},
+	"KR": {"KR", "South Korea", Asia},
+	"ES": {"ES", "Spain", Europe},
+	"LK": {"LK", "Sri Lanka", Asia},
+	"SE": {"SE", "Sweden", Europe},
+	"CH": {"CH", "Switzerland", Europe},
+	"TW": {"TW", "Taiwan", Asia},
+	"TJ": {"TJ", "Tajikistan", Asia},
+	"TZ": {"TZ", "Tanzania", Africa},
+	"TH": {"TH", "Thailand", Asia},
+	"TT": {"TT", "Trinidad and Tobago", NorthAmerica},
+	"TN": {"TN", "Tunisia", Africa},
+	"TR": {"TR", "Turkey", Asia},
+	"UG": {"UG", "Uganda", Africa},
+	"UA": {"UA", "Ukraine", Europe},
+	"AE": {"AE", "United Arab Emirates", Asia},
+	"GB": {"GB", "United Kingdom", Europe},
+	"US": {"US", "United States", NorthAmerica},
+	"UY": {"UY", "Uruguay", SouthAmerica},
+	"UZ": {"UZ", "Uzbekistan", Asia},
+	"VE": {"VE", "Venezuela", SouthAmerica},
+	"VN": {"VN", "Vietnam", Asia},
+	"ZM": {"ZM", "Zambia", Africa},
+	"ZW": {"ZW", "Zimbabwe", Africa},
+}
+
+// countrySlugs maps the country part of upstream location slugs, and
+// common alternative spellings, to ISO codes
+var countrySlugs = map[string]string{
+	"afghanistan":            "AF",
+	"albania":                "AL",
+	"algeria":                "DZ",
+	"andorra":                "AD",
+	"angola":                 "AO",
+	"argentina":              "AR",
+	"armenia":                "AM",
+	"australia":              "AU",
+	"austria":                "AT",
+	"azerbaijan":             "AZ",
+	"bahamas":                "BS",
+	"bahrain":                "BH",
+	"bangladesh":             "BD",
+	"barbados":               "BB",
+	"belarus":                "BY",
+	"belgium":                "BE",
+	"belize":                 "BZ",
+	"benin":                  "BJ",
+	"bhutan":                 "BT",
+	"bolivia":                "BO",
+	"bosnia_and_herzegovina": "BA",
+	"botswana":               "BW",
+	"brazil":                 "BR",
+	"brunei":                 "BN",
+	"bulgaria":               "BG",
+	"burkina_faso":           "BF",
+	"cambodia":               "KH",
+	"cameroon":               "CM",
+	"canada":                 "CA",
+	"chile":                  "CL",
+	"china":                  "CN",
+	"colombia":               "CO",
+	"costa_rica":             "CR",
+	"cote_d_ivoire":          "CI",
+	"croatia":                "HR",
+	"cuba":                   "CU",
+	"curacao":                "CW",
+	"cyprus":                 "CY",
+	"czech_republic":         "CZ",
+	"czechia":                "CZ",
+	"denmark":                "DK",
+	"dominican_republic":     "DO",
+	"ecuador":                "EC",
+	"egypt":                  "EG",
+	"el_salvador":            "SV",
+	"england":                "GB",
+	"estonia":                "EE",
+	"ethiopia":               "ET",
+	"fiji":                   "FJ",
+	"finland":                "FI",
+	"france":                 "FR",
+	"french_polynesia":       "PF",
+	"georgia":                "GE",
+	"germany":                "DE",
+	"ghana":                  "GH",
+	"great_britain":          "GB",
+	"greece":                 "GR",
+	"guatemala":              "GT",
+	"holland":                "NL",
+	"honduras":               "HN",
+	"hong_kong":              "HK",
+	"hungary":                "HU",
+	"iceland":                "IS",
+	"india":                  "IN",
+	"indonesia":              "ID",
+	"iran":                   "IR",
+	"iraq":                   "IQ",
+	"ireland":                "IE",
+	"israel":                 "IL",
+	"italy":                  "IT",
+	"ivory_coast":            "CI",
+	"jamaica":                "JM",
+	"japan":                  "JP",
+	"jordan":                 "JO",
+	"kazakhstan":             "KZ",
+	"kenya":                  "KE",
+	"korea":                  "KR",
+	"kuwait":                 "KW",
+	"kyrgyzstan":             "KG",
+	"laos":                   "LA",
+	"latvia":                 "LV",
+	"lebanon":                "LB",
+	"liechtenstein":          "LI",
+	"lithuania":              "LT",
+	"luxembourg":             "LU",
+	"macau":                  "MO",
+	"macedonia":              "MK",
+	"madagascar":             "MG",
+	"malaysia":               "MY",
+	"maldives":               "MV",
+	"malta":                  "MT",
+	"mauritius":              "MU",
+	"mexico":                 "MX",
+	"moldova":                "MD",
+	"monaco":                 "MC",
+	"mongolia":               "MN",
+	"montenegro":             "ME",
+	"morocco":                "MA",
+	"mozambique":             "MZ",
+	"myanmar":                "MM",
+	"namibia":                "NA",
+	"nepal":                  "NP",
+	"netherlands":            "NL",
+	"netherlands_antilles":   "CW",
+	"new_caledonia":          "NC",
+	"new_zealand":            "NZ",
+	"nicaragua":              "NI",
+	"nigeria":                "NG",
+	"north_macedonia":        "MK",
+	"northern_ireland":       "GB",
+	"norway":                 "NO",
+	"oman":                   "OM",
+	"pakistan":               "PK",
+	"panama":                 "PA",
+	"papua_new_guinea":       "PG",
+	"paraguay":               "PY",
+	"peru":                   "PE",
+	"philippines":            "PH",
+	"poland":                 "PL",
+	"portugal":               "PT",
+	"puerto_rico":            "PR",
+	"qatar":                  "QA",
+	"reunion":                "RE",
+	"romania":                "RO",
+	"russia":                 "RU",
+	"russian_federation":     "RU",
+	"rwanda":                 "RW",
+	"san_marino":             "SM",
+	"saudi_arabia":           "SA",
+	"scotland":               "GB",
+	"senegal":                "SN",
+	"serbia":                 "RS",
+	"singapore":              "SG",
+	"slovakia":               "SK",
+	"slovenia":               "SI",
+	"south_africa":           "ZA",
+	"south_korea":            "KR",
+	"spain":                  "ES",
+	"sri_lanka":              "LK",
+	"sweden":                 "SE",
+	"switzerland":            "CH",
+	"taiwan":                 "TW",
+	"tajikistan":             "TJ",
+	"tanzania":               "TZ",
+	"thailand":               "TH",
+	"trinidad_and_tobago":    "TT",
+	"tunisia":                "TN",
+	"turkey":                 "TR",
+	"uae":                    "AE",
+	"uganda":                 "UG",
+	"uk":                     "GB",
+	"ukraine":                "UA",
+	"united_arab_emirates":   "AE",
+	"united_kingdom":         "GB",
+	"united_states":          "US",
+	"uruguay":                "UY",
+	"us":                     "US",
+	"usa":                    "US",
+	"uzbekistan":             "UZ",
+	"venezuela":              "VE",
+	"viet_nam":               "VN",
+	"vietnam":                "VN",
+	"wales":                  "GB",
+	"zambia":                 "ZM",
+	"zimbabwe":               "ZW",
+}
+
+// LookupCountry returns the country of a location slug such as
+// "north_carolina-usa", using the part after the last hyphen
+func LookupCountry(location string) (Country, bool) {
+	part := strings.ToLower(strings.TrimSpace(location))
+	if i := strings.LastIndex(part, "-"); i >= 0 {
+		part = part[i+1:]
+	}
+	part = strings.ReplaceAll(part, " ", "_")
+
+	code, ok := countrySlugs[part]
+	if !ok {
+		return Country{}, false
+	}
+	return countries[code], true
+}
+
+// CountryByCode returns the country with the given ISO code
+func CountryByCode(code string) (Country, bool) {
+	c, ok := countries[strings.ToUpper(code)]
+	return c, ok
+}
+
+// ContinentName returns the display name of a continent code
+func ContinentName(code string) string {
+	return continentNames[strings.ToUpper(code)]
+}
+
+// ParseContinent accepts a continent code or name such as "EU", "europe"
+// or "north_america" and returns its code
+func ParseContinent(s string) (string, bool) {
+	s = strings.TrimSpace(s)
+	if _, ok := continentNames[strings.ToUpper(s)]; ok {
+		return strings.ToUpper(s), true
+	}
+	name := strings.ReplaceAll(strings.ToLower(s), "_", " ")
+	for code, n := range continentNames {
+		if strings.ToLower(n) == name {
+			return code, true
+		}
+	}
+	return "", false
+}
+
+// ParseCountry accepts an ISO code, a slug such as "new_zealand" or a
+// display name such as "New Zealand" and returns the country
+func ParseCountry(s string) (Country, bool) {
+	s = strings.TrimSpace(s)
+	if c, ok := CountryByCode(s); ok && len(s) == 2 {
+		return c, true
+	}
+	slug := strings.ReplaceAll(strings.ToLower(s), " ", "_")
+	if code, ok := countrySlugs[slug]; ok {
+		return countries[code], true
+	}
+	for _, c := range countries {
+		if strings.EqualFold(c.Name, s) {
+			return c, true
+		}
+	}
+	return Country{}, false
+}
@@ -0,0 +1,24 @@
+// internal/geo/geo.go
+package geo
+
+import "math"
+
+// EarthRadiusKm is the mean Earth radius used for distances
+const EarthRadiusKm = 6371.0
+
+// Distance returns the great-circle distance in kilometres between two
+// points given in degrees, using the haversine formula
+func Distance(lat1, lon1, lat2, lon2 float64) float64 {
+	phi1 := radians(lat1)
+	phi2 := radians(lat2)
+	dPhi := radians(lat2 - lat1)
+	dLambda := radians(lon2 - lon1)
+
+	a := math.Sin(dPhi/2)*math.Sin(dPhi/2) +
+		math.Cos(phi1)*math.Cos(phi2)*math.Sin(dLambda/2)*math.Sin(dLambda/2)
+	return 2 * EarthRadiusKm * math.Asin(math.Min(1, math.Sqrt(a)))
+}
+
+func radians(deg float64) float64 {
+	return deg * math.Pi / 180
+}
@@ -0,0 +1,34 @@
+// internal/geo/geo_test.go
+package geo
+
+import (
+	"math"
+	"testing"
+)
+
+func TestDistance(t *testing.T) {
+	tests := []struct {
+		name                   string
+		lat1, lon1, lat2, lon2 float64
+		want                   float64
+	}{
+		{"same point", 48.8566, 2.3522, 48.8566, 2.3522, 0},
+		{"paris to london", 48.8566, 2.3522, 51.5074, -0.1278, 343.6},
+		{"new york to los angeles", 40.7128, -74.0060, 34.0522, -118.2437, 3935.7},
+		{"one degree of latitude", 0, 0, 1, 0, 111.19},
+		{"across the antimeridian", 0, 179.5, 0, -179.5, 111.19},
+		{"pole to pole", 90, 0, -90, 0, math.Pi * EarthRadiusKm},
+		{"antipodes", 0, 0, 0, 180, math.Pi * EarthRadiusKm},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Distance(tt.lat1, tt.lon1, tt.lat2, tt.lon2)
+			if math.Abs(got-tt.want) > 0.5 {
+				t.Errorf("Distance = %.2f km, want %.2f km", got, tt.want)
+			}
+			if back := Distance(tt.lat2, tt.lon2, tt.lat1, tt.lon1); math.Abs(back-got) > 1e-9 {
+				t.Errorf("Distance is not symmetric: %.6f and %.6f", got, back)
+			}
+		})
+	}
+}
@@ -0,0 +1,131 @@
+// internal/geo/index.go
+package geo
+
+import "math"
+
+// kmPerDegree is the length of one degree of latitude
+const kmPerDegree = math.Pi * EarthRadiusKm / 180
+
+// Index is a grid-based spatial index of named points. Queries only visit
+// the cells overlapping the searched region.
+type Index struct {
+	cellDeg float64
+	latN    int
+	lonN    int
+	cells   map[[2]int][]entry
+	size    int
+}
+
+type entry struct {
+	id       string
+	lat, lon float64
+}
+
+// NewIndex returns an empty index with square cells of cellDeg degrees
+func NewIndex(cellDeg float64) *Index {
+	return &Index{
+		cellDeg: cellDeg,
+		latN:    int(math.Ceil(180 / cellDeg)),
+		lonN:    int(math.Ceil(360 / cellDeg)),
+		cells:   make(map[[2]int][]entry),
+	}
+}
+
+// Insert adds a point
+func (ix *Index) Insert(id string, lat, lon float64) {
+	key := [2]int{ix.latCell(lat), ix.lonCell(lon)}
+	ix.cells[key] = append(ix.cells[key], entry{id: id, lat: lat, lon: lon})
+	ix.size++
+}
+
+// Len returns the number of points
+func (ix *Index) Len() int {
+	return ix.size
+}
+
+// WithinRadius returns the IDs of points within km of lat, lon
+func (ix *Index) WithinRadius(lat, lon, km float64) []string {
+	dLat := km / kmPerDegree
+	minLat, maxLat := math.Max(-90, lat-dLat), math.Min(90, lat+dLat)
+
+	// Longitude degrees shrink towards the poles; near them, or for huge
+	// radii, scan every longitude
+	minLon, maxLon := -180.0, 180.0
+	if cos := math.Cos(radians(math.Max(math.Abs(minLat), math.Abs(maxLat)))); cos > 1e-6 {
+		if dLon := dLat / cos; dLon < 180 {
+			minLon, maxLon = lon-dLon, lon+dLon
+		}
+	}
+
+	return ix.scan(minLat, maxLat, minLon, maxLon, func(e entry) bool {
+		return Distance(lat, lon, e.lat, e.lon) <= km
+	})
+}
+
+// WithinBBox returns the IDs of points inside the box. When minLon exceeds
+// maxLon the box crosses the antimeridian.
+func (ix *Index) WithinBBox(minLon, minLat, maxLon, maxLat float64) []string {
+	crosses := minLon > maxLon
+	scanMaxLon := maxLon
+	if crosses {
+		scanMaxLon += 360
+	}
+
+	return ix.scan(minLat, maxLat, minLon, scanMaxLon, func(e entry) bool {
+		if e.lat < minLat || e.lat > maxLat {
+			return false
+		}
+		if crosses {
+			return e.lon >= minLon || e.lon <= maxLon
+		}
+		return e.lon >= minLon && e.lon <= maxLon
+	})
+}
+
+// scan visits the cells covering the latitude and longitude ranges, where
+// the longitude range may extend past ±180 and wraps around. Ranges are
+// clamped before they become cell numbers, so out-of-range or non-finite
+// bounds cost at most one pass over the grid.
+func (ix *Index) scan(minLat, maxLat, minLon, maxLon float64, match func(entry) bool) []string {
+	var ids []string
+
+	minLat, maxLat = math.Max(minLat, -90), math.Min(maxLat, 90)
+	if !(minLat <= maxLat) {
+		return nil
+	}
+
+	// A range of 360 degrees or more, or one that is not a number, covers
+	// every longitude cell
+	firstLon, lastLon := 0, ix.lonN-1
+	if maxLon-minLon < 360 {
+		first := math.Floor((minLon + 180) / ix.cellDeg)
+		span := math.Floor((maxLon+180)/ix.cellDeg) - first
+		if span < float64(ix.lonN-1) {
+			firstLon = int(math.Mod(first, float64(ix.lonN)))
+			lastLon = firstLon + int(math.Max(span, 0))
+		}
+	}
+
+	for latCell := ix.latCell(minLat); latCell <= ix.latCell(maxLat); latCell++ {
+		for c := firstLon; c <= lastLon; c++ {
+			lonCell := ((c % ix.lonN) + ix.lonN) % ix.lonN
+			for _, e := range ix.cells[[2]int{latCell, lonCell}] {
+				if match(e) {
+					ids = append(ids, e.id)
+				}
+			}
+		}
+	}
+	return ids
+}
+
+func (ix *Index) latCell(lat float64) int {
+	lat = math.Max(-90, math.Min(90, lat))
+	c := int(math.Floor((lat + 90) / ix.cellDeg))
+	return min(max(c, 0), ix.latN-1)
+}
+
+func (ix *Index) lonCell(lon float64) int {
+	c := int(math.Floor(math.Mod(lon+180, 360) / ix.cellDeg))
+	return ((c % ix.lonN) + ix.lonN) % ix.lonN
+}
@@ -0,0 +1,122 @@
+// internal/geo/index_test.go
+package geo
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"testing"
+)
+
+type point struct {
+	id       string
+	lat, lon float64
+}
+
+// testPoints spreads points over the globe, including the poles and both
+// sides of the antimeridian
+func testPoints() []point {
+	var points []point
+	for lat := -90.0; lat <= 90; lat += 7.5 {
+		for lon := -180.0; lon < 180; lon += 7.5 {
+			points = append(points, point{fmt.Sprintf("%g,%g", lat, lon), lat, lon})
+		}
+	}
+	for _, lon := range []float64{179.9, -179.9, 180} {
+		points = append(points, point{fmt.Sprintf("0,%g", lon), 0, lon})
+	}
+	return points
+}
+
+func newTestIndex(points []point) *Index {
+	ix := NewIndex(5)
+	for _, p := range points {
+		ix.Insert(p.id, p.lat, p.lon)
+	}
+	return ix
+}
+
+func sorted(ids []string) []string {
+	ids = slices.Clone(ids)
+	slices.Sort(ids)
+	return ids
+}
+
+func TestIndexWithinRadius(t *testing.T) {
+	points := testPoints()
+	ix := newTestIndex(points)
+	if ix.Len() != len(points) {
+		t.Fatalf("Len = %d, want %d", ix.Len(), len(points))
+	}
+
+	tests := []struct {
+		name           string
+		lat, lon, km   float64
+		wantAtLeastOne bool
+	}{
+		{"paris", 48.8566, 2.3522, 500, true},
+		{"small radius", 48.8566, 2.3522, 10, false},
+		{"antimeridian", 0, 179, 300, true},
+		{"north pole", 89, 45, 1000, true},
+		{"south pole", -89.5, -120, 200, true},
+		{"half the globe", 10, 10, 10000, true},
+		{"whole globe", 0, 0, 25000, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var want []string
+			for _, p := range points {
+				if Distance(tt.lat, tt.lon, p.lat, p.lon) <= tt.km {
+					want = append(want, p.id)
+				}
+			}
+			got := sorted(ix.WithinRadius(tt.lat, tt.lon, tt.km))
+			if !slices.Equal(got, sorted(want)) {
+				t.Errorf("WithinRadius = %v, want %v", got, sorted(want))
+			}
+			if tt.wantAtLeastOne && len(got) == 0 {
+				t.Error("WithinRadius found nothing")
+			}
+		})
+	}
+}
+
+func TestIndexWithinBBox(t *testing.T) {
+	points := testPoints()
+	ix := newTestIndex(points)
+
+	tests := []struct {
+		name                           string
+		minLon, minLat, maxLon, maxLat float64
+	}{
+		{"europe", -10, 35, 30, 60},
+		{"empty", 1, 1, 2, 2},
+		{"crossing the antimeridian", 170, -10, -170, 10},
+		{"whole world", -180, -90, 180, 90},
+		{"single point", 0, 0, 0, 0},
+		{"past ±180", -200, -10, 200, 10},
+		{"huge longitudes", -2.5e19, -10, 2.5e19, 10},
+		{"huge latitudes", -10, -1e300, 10, 1e300},
+		{"infinite", math.Inf(-1), -10, math.Inf(1), 10},
+		{"not a number", math.NaN(), -10, 10, 10},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			crosses := tt.minLon > tt.maxLon
+			var want []string
+			for _, p := range points {
+				inLon := p.lon >= tt.minLon && p.lon <= tt.maxLon
+				if crosses {
+					inLon = p.lon >= tt.minLon || p.lon <= tt.maxLon
+				}
+				if inLon && p.lat >= tt.minLat && p.lat <= tt.maxLat {
+					want = append(want, p.id)
+				}
+			}
+			got := sorted(ix.WithinBBox(tt.minLon, tt.minLat, tt.maxLon, tt.maxLat))
+			if !slices.Equal(got, sorted(want)) {
+				t.Errorf("WithinBBox = %v, want %v", got, sorted(want))
+			}
+		})
+	}
+}
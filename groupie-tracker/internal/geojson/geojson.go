@@ -0,0 +1,53 @@
+// internal/geojson/geojson.go
+package geojson
+
+// ContentType is the MIME type of GeoJSON documents (RFC 7946)
+const ContentType = "application/geo+json"
+
+// FeatureCollection is a GeoJSON feature collection
+type FeatureCollection struct {
+	Type     string    `json:"type"`
+	Features []Feature `json:"features"`
+}
+
+// Feature is a geometry with arbitrary properties
+type Feature struct {
+	Type       string                 `json:"type"`
+	ID         interface{}            `json:"id,omitempty"`
+	Geometry   Geometry               `json:"geometry"`
+	Properties map[string]interface{} `json:"properties"`
+}
+
+// Geometry is a Point or LineString. Positions are [longitude, latitude].
+type Geometry struct {
+	Type        string      `json:"type"`
+	Coordinates interface{} `json:"coordinates"`
+}
+
+// NewFeatureCollection wraps features, never encoding them as null
+func NewFeatureCollection(features []Feature) FeatureCollection {
+	if features == nil {
+		features = []Feature{}
+	}
+	return FeatureCollection{Type: "FeatureCollection", Features: features}
+}
+
+// NewFeature builds a feature with the given ID, geometry and properties
+func NewFeature(id interface{}, geometry Geometry, properties map[string]interface{}) Feature {
+	return Feature{Type: "Feature", ID: id, Geometry: geometry, Properties: properties}
+}
+
+// Point returns a Point geometry
+func Point(lat, lon float64) Geometry {
+	return Geometry{Type: "Point", Coordinates: [2]float64{lon, lat}}
+}
+
+// LineString returns a LineString geometry through the given positions,
+// each given as {lat, lon}
+func LineString(points [][2]float64) Geometry {
+	coords := make([][2]float64, len(points))
+	for i, p := range points {
+		coords[i] = [2]float64{p[1], p[0]}
+	}
+	return Geometry{Type: "LineString", Coordinates: coords}
+}
@@ -0,0 +1,67 @@
+// internal/gql/gql.go
+package gql
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/gqlerrors"
+	"github.com/graphql-go/graphql/language/parser"
+	"github.com/graphql-go/graphql/language/source"
+
+	"groupie-tracker/internal/service"
+)
+
+// Request is a GraphQL request as posted over HTTP
+type Request struct {
+	Query         string                 `json:"query"`
+	OperationName string                 `json:"operationName,omitempty"`
+	Variables     map[string]interface{} `json:"variables,omitempty"`
+}
+
+// Service executes GraphQL queries against the cached dataset
+type Service struct {
+	schema graphql.Schema
+	cache  *service.CacheService
+	limits Limits
+}
+
+// New builds the schema. Artist filters default to the ranges of filter's
+// metadata and place coordinates are resolved with geocoder.
+func New(cache *service.CacheService, search *service.SearchService, filter *service.FilterService, geocoder Geocoder, limits Limits) (*Service, error) {
+	schema, err := newSchema(search, filter, geocoder)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build graphql schema: %w", err)
+	}
+	return &Service{schema: schema, cache: cache, limits: limits}, nil
+}
+
+// Execute checks the query against the limits and runs it against a
+// snapshot of the cached data taken at the start of the request
+func (s *Service) Execute(ctx context.Context, req Request) *graphql.Result {
+	doc, err := parser.Parse(parser.ParseParams{
+		Source: source.NewSource(&source.Source{Body: []byte(req.Query), Name: "GraphQL request"}),
+	})
+	if err != nil {
+		return &graphql.Result{Errors: []gqlerrors.FormattedError{gqlerrors.FormatError(err)}}
+	}
+
+	if err := s.limits.check(doc, req.OperationName, req.Variables); err != nil {
+		return &graphql.Result{Errors: []gqlerrors.FormattedError{gqlerrors.NewFormattedError(err.Error())}}
+	}
+
+	data, err := s.cache.GetCachedData(ctx)
+	if err != nil {
+		return &graphql.Result{Errors: []gqlerrors.FormattedError{gqlerrors.NewFormattedError("failed to fetch data")}}
+	}
+
+	return graphql.Do(graphql.Params{
+		Schema:         s.schema,
+		RequestString:  req.Query,
+		RootObject:     map[string]interface{}{snapshotKey: data},
+		VariableValues: req.Variables,
+		OperationName:  req.OperationName,
+		Context:        ctx,
+	})
+}
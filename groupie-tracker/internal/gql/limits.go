@@ -0,0 +1,165 @@
+// internal/gql/limits.go
+package gql
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+
+	"github.com/graphql-go/graphql/language/ast"
+)
+
+// Limits bounds the cost of a query before it is executed
+type Limits struct {
+	// MaxDepth is the deepest allowed selection nesting
+	MaxDepth int
+	// MaxComplexity bounds the estimated number of resolved fields, where
+	// list fields multiply the cost of their children by their page size
+	MaxComplexity int
+}
+
+// DefaultLimits allow an artist with members, events and places in one
+// request while rejecting pathological queries
+func DefaultLimits() Limits {
+	return Limits{MaxDepth: 6, MaxComplexity: 5000}
+}
+
+// listFields are the fields returning lists, with their assumed size when
+// the query does not pass a "first" argument
+var listFields = map[string]int{
+	"artists": defaultFirst,
+	"events":  20,
+	"places":  10,
+	"members": 5,
+}
+
+const (
+	// maxPageSize is the largest page size assumed for a list field, far
+	// above the size of the dataset
+	maxPageSize = 1000
+	// maxEstimate caps complexity estimates so multiplying them by a page
+	// size cannot overflow
+	maxEstimate = math.MaxInt32
+)
+
+// analyzer measures the depth and complexity of an operation
+type analyzer struct {
+	fragments map[string]*ast.FragmentDefinition
+	variables map[string]interface{}
+	visiting  map[string]bool
+}
+
+// check returns an error when the operation exceeds the limits
+func (l Limits) check(doc *ast.Document, operationName string, variables map[string]interface{}) error {
+	a := &analyzer{
+		fragments: map[string]*ast.FragmentDefinition{},
+		variables: variables,
+		visiting:  map[string]bool{},
+	}
+
+	var operations []*ast.OperationDefinition
+	for _, def := range doc.Definitions {
+		switch d := def.(type) {
+		case *ast.FragmentDefinition:
+			a.fragments[d.Name.Value] = d
+		case *ast.OperationDefinition:
+			operations = append(operations, d)
+		}
+	}
+
+	for _, op := range operations {
+		if operationName != "" && (op.Name == nil || op.Name.Value != operationName) {
+			continue
+		}
+
+		depth, complexity, err := a.selectionSet(op.SelectionSet, 1)
+		if err != nil {
+			return err
+		}
+		if l.MaxDepth > 0 && depth > l.MaxDepth {
+			return fmt.Errorf("query depth %d exceeds the maximum of %d", depth, l.MaxDepth)
+		}
+		if l.MaxComplexity > 0 && complexity > l.MaxComplexity {
+			return fmt.Errorf("query complexity %d exceeds the maximum of %d", complexity, l.MaxComplexity)
+		}
+	}
+	return nil
+}
+
+// selectionSet returns the depth and complexity of a selection set found
+// at the given depth
+func (a *analyzer) selectionSet(set *ast.SelectionSet, depth int) (int, int, error) {
+	if set == nil {
+		return depth - 1, 0, nil
+	}
+
+	maxDepth, complexity := depth, 0
+	for _, sel := range set.Selections {
+		var (
+			d, c int
+			err  error
+		)
+
+		switch s := sel.(type) {
+		case *ast.Field:
+			d, c, err = a.selectionSet(s.SelectionSet, depth+1)
+			if err != nil {
+				return 0, 0, err
+			}
+			if size, ok := listFields[s.Name.Value]; ok {
+				c *= a.pageSize(s, size)
+			}
+			c++
+			if s.SelectionSet == nil {
+				d = depth
+			}
+		case *ast.InlineFragment:
+			d, c, err = a.selectionSet(s.SelectionSet, depth)
+		case *ast.FragmentSpread:
+			name := s.Name.Value
+			frag, ok := a.fragments[name]
+			if !ok {
+				return 0, 0, fmt.Errorf("unknown fragment %q", name)
+			}
+			if a.visiting[name] {
+				return 0, 0, fmt.Errorf("fragment %q is recursive", name)
+			}
+			a.visiting[name] = true
+			d, c, err = a.selectionSet(frag.SelectionSet, depth)
+			delete(a.visiting, name)
+		}
+		if err != nil {
+			return 0, 0, err
+		}
+
+		maxDepth = max(maxDepth, d)
+		complexity = min(complexity+c, maxEstimate)
+	}
+	return maxDepth, complexity, nil
+}
+
+// pageSize returns the "first" argument of a list field, clamped to
+// [1, maxPageSize], or its default size
+func (a *analyzer) pageSize(field *ast.Field, fallback int) int {
+	for _, arg := range field.Arguments {
+		if arg.Name.Value != "first" {
+			continue
+		}
+		switch v := arg.Value.(type) {
+		case *ast.IntValue:
+			n, err := strconv.Atoi(v.Value)
+			if err != nil {
+				return maxPageSize
+			}
+			return min(max(n, 1), maxPageSize)
+		case *ast.Variable:
+			switch n := a.variables[v.Name.Value].(type) {
+			case float64:
+				return int(min(max(n, 1), maxPageSize))
+			case int:
+				return min(max(n, 1), maxPageSize)
+			}
+		}
+	}
+	return fallback
+}
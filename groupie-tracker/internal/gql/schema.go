@@ -0,0 +1,455 @@
+// internal/gql/schema.go
+package gql
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/graphql-go/graphql"
+
+	"groupie-tracker/internal/geo"
+	"groupie-tracker/internal/models"
+	"groupie-tracker/internal/service"
+)
+
+// Default page size for list fields without a "first" argument
+const defaultFirst = 50
+
+// Geocoder looks up the coordinates of an upstream location slug among
+// those already geocoded; queries never trigger geocoding API calls
+type Geocoder interface {
+	CachedGeocode(address string) (models.GeoLocation, bool)
+}
+
+// snapshotKey is the root object key holding the request's data snapshot
+const snapshotKey = "snapshot"
+
+// artistNode is the value resolved for the Artist type
+type artistNode struct {
+	artist models.Artist
+	data   models.Datas
+}
+
+// placeNode is the value resolved for the Place type
+type placeNode struct {
+	slug string
+}
+
+// eventNode is the value resolved for the Event type
+type eventNode struct {
+	date  string
+	raw   string
+	place placeNode
+}
+
+// newSchema builds the GraphQL schema over Artist, Member, Event and Place
+func newSchema(search *service.SearchService, filter *service.FilterService, geocoder Geocoder) (graphql.Schema, error) {
+	placeType := graphql.NewObject(graphql.ObjectConfig{
+		Name:        "Place",
+		Description: "A concert location, with coordinates once geocoded",
+		Fields: graphql.Fields{
+			"slug": &graphql.Field{
+				Type:        graphql.NewNonNull(graphql.String),
+				Description: "Upstream location identifier, e.g. north_carolina-usa",
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return p.Source.(placeNode).slug, nil
+				},
+			},
+			"name": &graphql.Field{
+				Type:        graphql.NewNonNull(graphql.String),
+				Description: "Display name, e.g. North Carolina, USA",
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return models.FormatLocation(p.Source.(placeNode).slug), nil
+				},
+			},
+			"lat": &graphql.Field{
+				Type:        graphql.Float,
+				Description: "Latitude; null until the location is geocoded",
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					geo, ok := geocoder.CachedGeocode(p.Source.(placeNode).slug)
+					if !ok {
+						return nil, nil
+					}
+					return geo.Lat, nil
+				},
+			},
+			"lon": &graphql.Field{
+				Type:        graphql.Float,
+				Description: "Longitude; null until the location is geocoded",
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					geo, ok := geocoder.CachedGeocode(p.Source.(placeNode).slug)
+					if !ok {
+						return nil, nil
+					}
+					return geo.Lon, nil
+				},
+			},
+		},
+	})
+
+	eventType := graphql.NewObject(graphql.ObjectConfig{
+		Name:        "Event",
+		Description: "A concert of an artist at a place",
+		Fields: graphql.Fields{
+			"date": &graphql.Field{
+				Type:        graphql.NewNonNull(graphql.String),
+				Description: "ISO 8601 date of the concert",
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return p.Source.(eventNode).date, nil
+				},
+			},
+			"rawDate": &graphql.Field{
+				Type:        graphql.NewNonNull(graphql.String),
+				Description: "Date as provided upstream (DD-MM-YYYY)",
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return p.Source.(eventNode).raw, nil
+				},
+			},
+			"place": &graphql.Field{
+				Type: graphql.NewNonNull(placeType),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return p.Source.(eventNode).place, nil
+				},
+			},
+		},
+	})
+
+	memberType := graphql.NewObject(graphql.ObjectConfig{
+		Name:        "Member",
+		Description: "A member of a band",
+		Fields: graphql.Fields{
+			"name": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.String),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return p.Source.(string), nil
+				},
+			},
+		},
+	})
+
+	artistType := graphql.NewObject(graphql.ObjectConfig{
+		Name:        "Artist",
+		Description: "An artist or band",
+		Fields: graphql.Fields{
+			"id":           artistField(graphql.NewNonNull(graphql.Int), func(a models.Artist) interface{} { return a.ID }),
+			"name":         artistField(graphql.NewNonNull(graphql.String), func(a models.Artist) interface{} { return a.Name }),
+			"image":        artistField(graphql.NewNonNull(graphql.String), func(a models.Artist) interface{} { return a.Image }),
+			"creationDate": artistField(graphql.NewNonNull(graphql.Int), func(a models.Artist) interface{} { return a.CreationDate }),
+			"firstAlbum":   artistField(graphql.NewNonNull(graphql.String), func(a models.Artist) interface{} { return a.FirstAlbum }),
+			"memberCount":  artistField(graphql.NewNonNull(graphql.Int), func(a models.Artist) interface{} { return len(a.Members) }),
+			"members": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(memberType))),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return p.Source.(artistNode).artist.Members, nil
+				},
+			},
+			"places": &graphql.Field{
+				Type:        graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(placeType))),
+				Description: "Every location the artist played at",
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					node := p.Source.(artistNode)
+					var places []placeNode
+					for _, entry := range node.data.LocationsData.Index {
+						if entry.ID == node.artist.ID {
+							for _, slug := range entry.Locations {
+								places = append(places, placeNode{slug: slug})
+							}
+							break
+						}
+					}
+					return places, nil
+				},
+			},
+			"events": &graphql.Field{
+				Type:        graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(eventType))),
+				Description: "Concerts ordered by date",
+				Args: graphql.FieldConfigArgument{
+					"location": &graphql.ArgumentConfig{Type: graphql.String, Description: "Substring of the location slug or name"},
+					"from":     &graphql.ArgumentConfig{Type: graphql.String, Description: "Earliest ISO date, inclusive"},
+					"to":       &graphql.ArgumentConfig{Type: graphql.String, Description: "Latest ISO date, inclusive"},
+					"first":    &graphql.ArgumentConfig{Type: graphql.Int, Description: "Maximum number of events"},
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					node := p.Source.(artistNode)
+					location, _ := p.Args["location"].(string)
+					from, _ := p.Args["from"].(string)
+					to, _ := p.Args["to"].(string)
+					events := artistEvents(node, strings.ToLower(location), from, to)
+					return limit(events, p.Args), nil
+				},
+			},
+		},
+	})
+
+	pointInput := graphql.NewInputObject(graphql.InputObjectConfig{
+		Name: "GeoPointInput",
+		Fields: graphql.InputObjectConfigFieldMap{
+			"lat": &graphql.InputObjectFieldConfig{Type: graphql.NewNonNull(graphql.Float)},
+			"lon": &graphql.InputObjectFieldConfig{Type: graphql.NewNonNull(graphql.Float)},
+		},
+	})
+
+	locationMatch := graphql.NewEnum(graphql.EnumConfig{
+		Name: "LocationMatch",
+		Values: graphql.EnumValueConfigMap{
+			"ANY": &graphql.EnumValueConfig{Value: models.MatchAny, Description: "Played in at least one of the locations"},
+			"ALL": &graphql.EnumValueConfig{Value: models.MatchAll, Description: "Played in every one of the locations"},
+		},
+	})
+
+	filterInput := graphql.NewInputObject(graphql.InputObjectConfig{
+		Name:        "ArtistFilter",
+		Description: "Same semantics as the FilterParams of /api/search",
+		Fields: graphql.InputObjectConfigFieldMap{
+			"creationYearMin":   &graphql.InputObjectFieldConfig{Type: graphql.Int},
+			"creationYearMax":   &graphql.InputObjectFieldConfig{Type: graphql.Int},
+			"firstAlbumYearMin": &graphql.InputObjectFieldConfig{Type: graphql.Int},
+			"firstAlbumYearMax": &graphql.InputObjectFieldConfig{Type: graphql.Int},
+			"membersMin":        &graphql.InputObjectFieldConfig{Type: graphql.Int},
+			"membersMax":        &graphql.InputObjectFieldConfig{Type: graphql.Int},
+			"members":           &graphql.InputObjectFieldConfig{Type: graphql.NewList(graphql.NewNonNull(graphql.Int))},
+			"excludeMembers":    &graphql.InputObjectFieldConfig{Type: graphql.NewList(graphql.NewNonNull(graphql.Int))},
+			"locations":         &graphql.InputObjectFieldConfig{Type: graphql.NewList(graphql.NewNonNull(graphql.String))},
+			"locationMatch":     &graphql.InputObjectFieldConfig{Type: locationMatch},
+			"excludeLocations":  &graphql.InputObjectFieldConfig{Type: graphql.NewList(graphql.NewNonNull(graphql.String))},
+			"countries":         &graphql.InputObjectFieldConfig{Type: graphql.NewList(graphql.NewNonNull(graphql.String))},
+			"excludeCountries":  &graphql.InputObjectFieldConfig{Type: graphql.NewList(graphql.NewNonNull(graphql.String))},
+			"continents":        &graphql.InputObjectFieldConfig{Type: graphql.NewList(graphql.NewNonNull(graphql.String))},
+			"near":              &graphql.InputObjectFieldConfig{Type: pointInput},
+			"radiusKm":          &graphql.InputObjectFieldConfig{Type: graphql.Float},
+			"bbox": &graphql.InputObjectFieldConfig{
+				Type:        graphql.NewList(graphql.NewNonNull(graphql.Float)),
+				Description: "minLon, minLat, maxLon, maxLat",
+			},
+		},
+	})
+
+	queryType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Query",
+		Fields: graphql.Fields{
+			"artist": &graphql.Field{
+				Type: artistType,
+				Args: graphql.FieldConfigArgument{
+					"id": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.Int)},
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					data := snapshot(p)
+					id, _ := p.Args["id"].(int)
+					for _, artist := range data.ArtistsData {
+						if artist.ID == id {
+							return artistNode{artist: artist, data: data}, nil
+						}
+					}
+					return nil, nil
+				},
+			},
+			"artists": &graphql.Field{
+				Type:        graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(artistType))),
+				Description: "Artists matching the search text and filters, like /api/search",
+				Args: graphql.FieldConfigArgument{
+					"search": &graphql.ArgumentConfig{Type: graphql.String},
+					"filter": &graphql.ArgumentConfig{Type: filterInput},
+					"first":  &graphql.ArgumentConfig{Type: graphql.Int, DefaultValue: defaultFirst},
+					"offset": &graphql.ArgumentConfig{Type: graphql.Int, DefaultValue: 0},
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					data := snapshot(p)
+					query, _ := p.Args["search"].(string)
+					meta, err := filter.Meta(p.Context)
+					if err != nil {
+						return nil, errors.New("failed to fetch data")
+					}
+					filters := service.DefaultFilters(meta)
+					if raw, ok := p.Args["filter"].(map[string]interface{}); ok {
+						applyFilter(&filters, raw)
+					}
+					if err := service.ValidateFilters(&filters, meta); err != nil {
+						return nil, fmt.Errorf("invalid filter: %w", err)
+					}
+
+					artists, err := search.SearchArtists(p.Context, query, filters)
+					if err != nil {
+						return nil, errors.New("failed to search artists")
+					}
+
+					nodes := make([]artistNode, 0, len(artists))
+					for _, artist := range artists {
+						nodes = append(nodes, artistNode{artist: artist, data: data})
+					}
+					return limit(nodes, p.Args), nil
+				},
+			},
+		},
+	})
+
+	return graphql.NewSchema(graphql.SchemaConfig{Query: queryType})
+}
+
+// artistField resolves a scalar field of the underlying models.Artist
+func artistField(typ graphql.Output, get func(models.Artist) interface{}) *graphql.Field {
+	return &graphql.Field{
+		Type: typ,
+		Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			return get(p.Source.(artistNode).artist), nil
+		},
+	}
+}
+
+// snapshot returns the data snapshot taken for the current request
+func snapshot(p graphql.ResolveParams) models.Datas {
+	data, _ := p.Info.RootValue.(map[string]interface{})[snapshotKey].(models.Datas)
+	return data
+}
+
+// artistEvents lists the concerts of an artist, filtered and sorted by date
+func artistEvents(node artistNode, location, from, to string) []eventNode {
+	var events []eventNode
+	for _, entry := range node.data.RelationsData.Index {
+		if entry.ID != node.artist.ID {
+			continue
+		}
+		for slug, dates := range entry.DatesLocations {
+			if location != "" && !strings.Contains(slug, location) &&
+				!strings.Contains(strings.ToLower(models.FormatLocation(slug)), location) {
+				continue
+			}
+			for _, raw := range dates {
+				t, err := models.ParseConcertDate(raw)
+				if err != nil {
+					continue
+				}
+				date := t.Format("2006-01-02")
+				if (from != "" && date < from) || (to != "" && date > to) {
+					continue
+				}
+				events = append(events, eventNode{date: date, raw: raw, place: placeNode{slug: slug}})
+			}
+		}
+		break
+	}
+
+	sort.Slice(events, func(i, j int) bool {
+		if events[i].date != events[j].date {
+			return events[i].date < events[j].date
+		}
+		return events[i].place.slug < events[j].place.slug
+	})
+	return events
+}
+
+// applyFilter copies the ArtistFilter input onto the default filters
+func applyFilter(filters *models.FilterParams, raw map[string]interface{}) {
+	if v, ok := raw["creationYearMin"].(int); ok {
+		filters.CreationYearMin = v
+	}
+	if v, ok := raw["creationYearMax"].(int); ok {
+		filters.CreationYearMax = v
+	}
+	if v, ok := raw["firstAlbumYearMin"].(int); ok {
+		filters.FirstAlbumYearMin = v
+	}
+	if v, ok := raw["firstAlbumYearMax"].(int); ok {
+		filters.FirstAlbumYearMax = v
+	}
+	// Zero or negative member bounds leave the range open
+	if v, ok := raw["membersMin"].(int); ok && v > 0 {
+		filters.MembersMin = v
+	}
+	if v, ok := raw["membersMax"].(int); ok && v > 0 {
+		filters.MembersMax = v
+	}
+	filters.Members = append(filters.Members, intList(raw["members"])...)
+	filters.ExcludeMembers = append(filters.ExcludeMembers, intList(raw["excludeMembers"])...)
+	filters.Locations = append(filters.Locations, stringList(raw["locations"])...)
+	filters.ExcludeLocations = append(filters.ExcludeLocations, stringList(raw["excludeLocations"])...)
+	if v, ok := raw["locationMatch"].(string); ok {
+		filters.LocationMatch = v
+	}
+	// Unknown excluded countries exclude nothing
+	for _, name := range stringList(raw["excludeCountries"]) {
+		if country, ok := geo.ParseCountry(name); ok {
+			filters.ExcludeCountries = append(filters.ExcludeCountries, country.Code)
+		}
+	}
+	// Unknown countries and continents match nothing rather than everything
+	if list, ok := raw["countries"].([]interface{}); ok {
+		for _, v := range list {
+			name, _ := v.(string)
+			if country, ok := geo.ParseCountry(name); ok {
+				filters.Countries = append(filters.Countries, country.Code)
+			} else {
+				filters.Countries = append(filters.Countries, "?")
+			}
+		}
+	}
+	if list, ok := raw["continents"].([]interface{}); ok {
+		for _, v := range list {
+			name, _ := v.(string)
+			if code, ok := geo.ParseContinent(name); ok {
+				filters.Continents = append(filters.Continents, code)
+			} else {
+				filters.Continents = append(filters.Continents, "?")
+			}
+		}
+	}
+	if near, ok := raw["near"].(map[string]interface{}); ok {
+		lat, _ := near["lat"].(float64)
+		lon, _ := near["lon"].(float64)
+		filters.Near = &models.GeoPoint{Lat: lat, Lon: lon}
+		filters.RadiusKm = service.DefaultRadiusKm
+	}
+	if v, ok := raw["radiusKm"].(float64); ok && v > 0 {
+		filters.RadiusKm = math.Min(v, service.MaxRadiusKm)
+	}
+	if list, ok := raw["bbox"].([]interface{}); ok && len(list) == 4 {
+		var bbox models.BBox
+		for i, v := range list {
+			bbox[i], _ = v.(float64)
+		}
+		filters.BBox = &bbox
+	}
+}
+
+// limit applies the optional first/offset arguments to a list
+func limit[T any](items []T, args map[string]interface{}) []T {
+	offset, _ := args["offset"].(int)
+	if offset < 0 {
+		offset = 0
+	}
+	if offset > len(items) {
+		offset = len(items)
+	}
+	items = items[offset:]
+
+	if first, ok := args["first"].(int); ok && first >= 0 && first < len(items) {
+		items = items[:first]
+	}
+	return items
+}
+
+// intList returns the integers of a list input
+func intList(raw interface{}) []int {
+	list, _ := raw.([]interface{})
+	var ints []int
+	for _, v := range list {
+		if n, ok := v.(int); ok {
+			ints = append(ints, n)
+		}
+	}
+	return ints
+}
+
+// stringList returns the non-blank, trimmed strings of a list input
+func stringList(raw interface{}) []string {
+	list, _ := raw.([]interface{})
+	var strs []string
+	for _, v := range list {
+		if s, ok := v.(string); ok && strings.TrimSpace(s) != "" {
+			strs = append(strs, strings.TrimSpace(s))
+		}
+	}
+	return strs
+}
@@ -0,0 +1,374 @@
+// internal/handlers/account.go
+package handlers
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"mime"
+	"net/http"
+	"net/url"
+
+	"groupie-tracker/internal/models"
+	"groupie-tracker/internal/service"
+)
+
+// sessionCookie holds the session token of a signed-in browser
+const sessionCookie = "session"
+
+// csrfHeader carries the session's CSRF token on requests that change state
+const csrfHeader = "X-CSRF-Token"
+
+// maxCredentialsSize bounds register and login request bodies
+const maxCredentialsSize = 64 << 10
+
+// HandleLoginPage serves the sign-in and registration page at GET /login
+func (h *Handler) HandleLoginPage(w http.ResponseWriter, r *http.Request) {
+	h.render(w, r, "login.html", nil)
+}
+
+// HandleRegister serves POST /api/auth/register: it creates an account from
+// models.Credentials and signs it in
+func (h *Handler) HandleRegister(w http.ResponseWriter, r *http.Request) {
+	creds, ok := h.credentials(w, r)
+	if !ok {
+		return
+	}
+
+	username, err := service.ValidateCredentials(creds.Username, creds.Password)
+	if err != nil {
+		h.sendError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// A guest registering keeps the artists it follows
+	guest, guestSession, ok := h.guest(w, r)
+	if !ok {
+		return
+	}
+
+	var user models.User
+	if guest.Guest {
+		user, err = h.users.ClaimGuest(r.Context(), guest.ID, username, creds.Password)
+	} else {
+		user, err = h.users.Register(r.Context(), username, creds.Password)
+	}
+	if errors.Is(err, service.ErrUsernameTaken) {
+		h.sendError(w, "Username already taken", http.StatusConflict)
+		return
+	}
+	if err != nil {
+		h.logger.ErrorContext(r.Context(), "error registering user", "error", err)
+		h.sendError(w, "Failed to register", http.StatusInternalServerError)
+		return
+	}
+	if guest.Guest {
+		// The guest's session now belongs to the account; sign it in afresh
+		if err := h.users.DeleteSession(r.Context(), guestSession.Token); err != nil {
+			h.logger.WarnContext(r.Context(), "error ending guest session", "user_id", user.ID, "error", err)
+		}
+	}
+
+	h.signIn(w, r, user, creds.Favorites, http.StatusCreated)
+}
+
+// HandleLogin serves POST /api/auth/login. Favorites in the body, kept by
+// the browser while signed out, and what a guest session saved are merged
+// into the account.
+func (h *Handler) HandleLogin(w http.ResponseWriter, r *http.Request) {
+	creds, ok := h.credentials(w, r)
+	if !ok {
+		return
+	}
+	guest, _, ok := h.guest(w, r)
+	if !ok {
+		return
+	}
+
+	user, err := h.users.Authenticate(r.Context(), creds.Username, creds.Password)
+	if errors.Is(err, service.ErrInvalidCredentials) {
+		h.logger.InfoContext(r.Context(), "failed login", "username", creds.Username)
+		h.sendError(w, "Invalid username or password", http.StatusUnauthorized)
+		return
+	}
+	if err != nil {
+		h.logger.ErrorContext(r.Context(), "error authenticating user", "error", err)
+		h.sendError(w, "Failed to sign in", http.StatusInternalServerError)
+		return
+	}
+	if guest.Guest {
+		if err := h.users.MergeGuest(r.Context(), guest.ID, user.ID); err != nil {
+			h.logger.WarnContext(r.Context(), "error merging guest", "user_id", user.ID, "error", err)
+		}
+	}
+
+	h.signIn(w, r, user, creds.Favorites, http.StatusOK)
+}
+
+// HandleGuest serves POST /api/auth/guest: it starts a guest session for a
+// visitor who wants to follow artists without registering, and replies
+// with the account. Visitors already signed in get their own account back.
+func (h *Handler) HandleGuest(w http.ResponseWriter, r *http.Request) {
+	user, session, err := h.session(r)
+	if err == nil {
+		w.Header().Set("Cache-Control", "no-store")
+		h.sendJSON(w, r, models.Account{User: user, CSRFToken: session.CSRFToken})
+		return
+	}
+	if !errors.Is(err, service.ErrSessionNotFound) {
+		h.logger.ErrorContext(r.Context(), "error looking up session", "error", err)
+		h.sendError(w, "Failed to look up session", http.StatusInternalServerError)
+		return
+	}
+
+	user, session, err = h.users.StartGuest(r.Context())
+	if err != nil {
+		h.logger.ErrorContext(r.Context(), "error starting guest session", "error", err)
+		h.sendError(w, "Failed to start session", http.StatusInternalServerError)
+		return
+	}
+	h.startSession(w, r, user, session, http.StatusCreated)
+}
+
+// HandleLogout serves POST /api/auth/logout
+func (h *Handler) HandleLogout(w http.ResponseWriter, r *http.Request) {
+	_, session, ok := h.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.users.DeleteSession(r.Context(), session.Token); err != nil {
+		h.logger.ErrorContext(r.Context(), "error signing out", "error", err)
+		h.sendError(w, "Failed to sign out", http.StatusInternalServerError)
+		return
+	}
+
+	http.SetCookie(w, &http.Cookie{
+		Name:     sessionCookie,
+		Path:     "/",
+		MaxAge:   -1,
+		HttpOnly: true,
+		Secure:   h.isHTTPS(r),
+		SameSite: http.SameSiteLaxMode,
+	})
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// HandleMe serves GET /api/me with the signed-in user and their CSRF token
+func (h *Handler) HandleMe(w http.ResponseWriter, r *http.Request) {
+	user, session, ok := h.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	h.sendJSON(w, r, models.Account{User: user, CSRFToken: session.CSRFToken})
+}
+
+// HandleFavorites serves GET /api/me/favorites
+func (h *Handler) HandleFavorites(w http.ResponseWriter, r *http.Request) {
+	user, _, ok := h.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	favorites, err := h.users.Favorites(r.Context(), user.ID)
+	if err != nil {
+		h.logger.ErrorContext(r.Context(), "error listing favorites", "error", err)
+		h.sendError(w, "Failed to list favorites", http.StatusInternalServerError)
+		return
+	}
+
+	// Name the artists still in the dataset
+	if cachedData, err := h.cache.GetCachedData(r.Context()); err == nil {
+		names := service.ArtistNames(cachedData)
+		for i := range favorites {
+			favorites[i].Name = names[favorites[i].ArtistID]
+		}
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	h.sendJSON(w, r, favorites)
+}
+
+// HandleAddFavorite serves PUT /api/me/favorites/{id}
+func (h *Handler) HandleAddFavorite(w http.ResponseWriter, r *http.Request) {
+	user, _, ok := h.currentUser(w, r)
+	if !ok {
+		return
+	}
+	id, ok := h.artistID(w, r)
+	if !ok {
+		return
+	}
+	if _, _, err := h.findArtist(r.Context(), id); err != nil {
+		h.sendLookupError(w, r, err)
+		return
+	}
+
+	if err := h.users.AddFavorites(r.Context(), user.ID, []int{id}); err != nil {
+		h.logger.ErrorContext(r.Context(), "error adding favorite", "error", err)
+		h.sendError(w, "Failed to add favorite", http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// HandleRemoveFavorite serves DELETE /api/me/favorites/{id}
+func (h *Handler) HandleRemoveFavorite(w http.ResponseWriter, r *http.Request) {
+	user, _, ok := h.currentUser(w, r)
+	if !ok {
+		return
+	}
+	id, ok := h.artistID(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.users.RemoveFavorite(r.Context(), user.ID, id); err != nil {
+		h.logger.ErrorContext(r.Context(), "error removing favorite", "error", err)
+		h.sendError(w, "Failed to remove favorite", http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// currentUser returns the user signed in with the request's session cookie.
+// Requests that change state must also carry the session's CSRF token. On
+// failure it has already replied 401 or 403.
+func (h *Handler) currentUser(w http.ResponseWriter, r *http.Request) (models.User, service.Session, bool) {
+	user, session, err := h.session(r)
+	if errors.Is(err, service.ErrSessionNotFound) {
+		h.sendError(w, "Not signed in", http.StatusUnauthorized)
+		return models.User{}, service.Session{}, false
+	}
+	if err != nil {
+		h.logger.ErrorContext(r.Context(), "error looking up session", "error", err)
+		h.sendError(w, "Failed to look up session", http.StatusInternalServerError)
+		return models.User{}, service.Session{}, false
+	}
+
+	switch r.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+	default:
+		if subtle.ConstantTimeCompare([]byte(r.Header.Get(csrfHeader)), []byte(session.CSRFToken)) != 1 {
+			h.logger.WarnContext(r.Context(), "CSRF token mismatch", "user_id", user.ID)
+			h.sendError(w, "Invalid CSRF token", http.StatusForbidden)
+			return models.User{}, service.Session{}, false
+		}
+	}
+	return user, session, true
+}
+
+// pageUser returns the signed-in user of a page request. Signed-out
+// visitors are redirected to sign in and come back afterwards.
+func (h *Handler) pageUser(w http.ResponseWriter, r *http.Request) (models.User, service.Session, bool) {
+	user, session, err := h.session(r)
+	if errors.Is(err, service.ErrSessionNotFound) {
+		http.Redirect(w, r, "/login?next="+url.QueryEscape(r.URL.RequestURI()), http.StatusSeeOther)
+		return models.User{}, service.Session{}, false
+	}
+	if err != nil {
+		h.logger.ErrorContext(r.Context(), "error looking up session", "error", err)
+		h.sendError(w, "Failed to look up session", http.StatusInternalServerError)
+		return models.User{}, service.Session{}, false
+	}
+	return user, session, true
+}
+
+// guest returns the guest signed in with the request's session cookie, or a
+// zero user when the request carries no guest session. On failure it has
+// already replied 500.
+func (h *Handler) guest(w http.ResponseWriter, r *http.Request) (models.User, service.Session, bool) {
+	user, session, err := h.session(r)
+	if errors.Is(err, service.ErrSessionNotFound) || (err == nil && !user.Guest) {
+		return models.User{}, service.Session{}, true
+	}
+	if err != nil {
+		h.logger.ErrorContext(r.Context(), "error looking up session", "error", err)
+		h.sendError(w, "Failed to look up session", http.StatusInternalServerError)
+		return models.User{}, service.Session{}, false
+	}
+	return user, session, true
+}
+
+// session looks up the session cookie of a request
+func (h *Handler) session(r *http.Request) (models.User, service.Session, error) {
+	cookie, err := r.Cookie(sessionCookie)
+	if err != nil {
+		return models.User{}, service.Session{}, service.ErrSessionNotFound
+	}
+	return h.users.Session(r.Context(), cookie.Value)
+}
+
+// credentials decodes the models.Credentials body of register and login
+// requests. Only JSON is accepted, which cross-site forms cannot send.
+func (h *Handler) credentials(w http.ResponseWriter, r *http.Request) (models.Credentials, bool) {
+	var creds models.Credentials
+	if mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); mediaType != "application/json" {
+		h.sendError(w, "Content-Type must be application/json", http.StatusUnsupportedMediaType)
+		return creds, false
+	}
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxCredentialsSize)).Decode(&creds); err != nil {
+		h.sendError(w, "Invalid request body", http.StatusBadRequest)
+		return creds, false
+	}
+	return creds, true
+}
+
+// signIn starts a session for user, merges the favorites the browser kept
+// while signed out and replies with the account
+func (h *Handler) signIn(w http.ResponseWriter, r *http.Request, user models.User, favorites []int, status int) {
+	if len(favorites) > 0 {
+		if err := h.users.AddFavorites(r.Context(), user.ID, h.knownArtists(r, favorites)); err != nil {
+			h.logger.WarnContext(r.Context(), "error merging local favorites", "user_id", user.ID, "error", err)
+		}
+	}
+
+	session, err := h.users.CreateSession(r.Context(), user.ID)
+	if err != nil {
+		h.logger.ErrorContext(r.Context(), "error creating session", "error", err)
+		h.sendError(w, "Failed to sign in", http.StatusInternalServerError)
+		return
+	}
+	h.startSession(w, r, user, session, status)
+}
+
+// startSession sets the session cookie and replies with the account
+func (h *Handler) startSession(w http.ResponseWriter, r *http.Request, user models.User, session service.Session, status int) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     sessionCookie,
+		Value:    session.Token,
+		Path:     "/",
+		Expires:  session.ExpiresAt,
+		HttpOnly: true,
+		Secure:   h.isHTTPS(r),
+		SameSite: http.SameSiteLaxMode,
+	})
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	h.sendJSON(w, r, models.Account{User: user, CSRFToken: session.CSRFToken})
+}
+
+// knownArtists keeps the IDs of artists in the dataset
+func (h *Handler) knownArtists(r *http.Request, ids []int) []int {
+	cachedData, err := h.cache.GetCachedData(r.Context())
+	if err != nil {
+		return nil
+	}
+
+	known := make(map[int]bool, len(cachedData.ArtistsData))
+	for _, artist := range cachedData.ArtistsData {
+		known[artist.ID] = true
+	}
+	var kept []int
+	for _, id := range ids {
+		if known[id] {
+			kept = append(kept, id)
+		} else {
+			h.logger.DebugContext(r.Context(), "ignoring unknown favorite", "id", id)
+		}
+	}
+	return kept
+}
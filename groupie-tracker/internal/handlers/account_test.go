@@ -0,0 +1,280 @@
+// internal/handlers/account_test.go
+package handlers
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"groupie-tracker/internal/models"
+	"groupie-tracker/internal/service"
+	"groupie-tracker/internal/store"
+)
+
+func newAccountHandler(trustProxy bool) (*Handler, store.Store) {
+	st := store.NewMemory()
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	return NewHandler(Config{
+		UserService: service.NewUserService(st, logger),
+		Logger:      logger,
+		TrustProxy:  trustProxy,
+	}), st
+}
+
+// call serves one request with the given JSON body, cookies and headers
+func call(handler http.HandlerFunc, method, body string, cookies []*http.Cookie, header http.Header) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(method, "/", strings.NewReader(body))
+	if body != "" {
+		r.Header.Set("Content-Type", "application/json")
+	}
+	for name, values := range header {
+		for _, value := range values {
+			r.Header.Add(name, value)
+		}
+	}
+	for _, cookie := range cookies {
+		r.AddCookie(cookie)
+	}
+	w := httptest.NewRecorder()
+	handler(w, r)
+	return w
+}
+
+// account decodes an account response and returns it with its cookies
+func account(t *testing.T, w *httptest.ResponseRecorder) (models.Account, []*http.Cookie) {
+	t.Helper()
+	var acc models.Account
+	if err := json.NewDecoder(w.Body).Decode(&acc); err != nil {
+		t.Fatalf("decoding account: %v", err)
+	}
+	return acc, w.Result().Cookies()
+}
+
+func csrf(token string) http.Header {
+	return http.Header{csrfHeader: {token}}
+}
+
+func TestRegisterAndLogin(t *testing.T) {
+	h, _ := newAccountHandler(false)
+	creds := `{"username":"Alice","password":"correct horse"}`
+
+	w := call(h.HandleRegister, http.MethodPost, creds, nil, nil)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("register: status %d, want %d: %s", w.Code, http.StatusCreated, w.Body)
+	}
+	registered, cookies := account(t, w)
+	if registered.User.Username != "alice" || registered.CSRFToken == "" {
+		t.Errorf("registered account = %+v, want alice with a CSRF token", registered)
+	}
+	if len(cookies) != 1 || cookies[0].Name != sessionCookie || !cookies[0].HttpOnly ||
+		cookies[0].SameSite != http.SameSiteLaxMode || cookies[0].Secure {
+		t.Errorf("session cookie = %+v, want an HttpOnly, SameSite=Lax cookie, not Secure over HTTP", cookies)
+	}
+
+	w = call(h.HandleMe, http.MethodGet, "", cookies, nil)
+	if me, _ := account(t, w); w.Code != http.StatusOK || me.User.ID != registered.User.ID || me.CSRFToken != registered.CSRFToken {
+		t.Errorf("me: status %d, account %+v; want %+v", w.Code, me, registered)
+	}
+
+	tests := []struct {
+		name   string
+		login  bool
+		body   string
+		status int
+	}{
+		{"taken username", false, `{"username":"alice","password":"other password"}`, http.StatusConflict},
+		{"short password", false, `{"username":"bob","password":"short"}`, http.StatusBadRequest},
+		{"invalid username", false, `{"username":"b","password":"long enough"}`, http.StatusBadRequest},
+		{"reserved username", false, `{"username":"guest-bob","password":"long enough"}`, http.StatusBadRequest},
+		{"malformed body", false, `{"username":`, http.StatusBadRequest},
+		{"wrong password", true, `{"username":"alice","password":"wrong horse"}`, http.StatusUnauthorized},
+		{"unknown user", true, `{"username":"bob","password":"correct horse"}`, http.StatusUnauthorized},
+		{"correct password", true, creds, http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := h.HandleRegister
+			if tt.login {
+				handler = h.HandleLogin
+			}
+			if w := call(handler, http.MethodPost, tt.body, nil, nil); w.Code != tt.status {
+				t.Errorf("status %d, want %d: %s", w.Code, tt.status, w.Body)
+			}
+		})
+	}
+}
+
+func TestCredentialsRequireJSON(t *testing.T) {
+	h, _ := newAccountHandler(false)
+	r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader("username=alice&password=correct+horse"))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	w := httptest.NewRecorder()
+	h.HandleRegister(w, r)
+	if w.Code != http.StatusUnsupportedMediaType {
+		t.Errorf("form body: status %d, want %d", w.Code, http.StatusUnsupportedMediaType)
+	}
+}
+
+func TestSessionRequired(t *testing.T) {
+	h, _ := newAccountHandler(false)
+	tests := []struct {
+		name    string
+		cookies []*http.Cookie
+	}{
+		{"no cookie", nil},
+		{"unknown token", []*http.Cookie{{Name: sessionCookie, Value: "forged"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if w := call(h.HandleMe, http.MethodGet, "", tt.cookies, nil); w.Code != http.StatusUnauthorized {
+				t.Errorf("status %d, want %d", w.Code, http.StatusUnauthorized)
+			}
+		})
+	}
+}
+
+func TestExpiredSession(t *testing.T) {
+	h, st := newAccountHandler(false)
+	ctx := context.Background()
+	user, err := st.CreateUser(ctx, "alice", "hash", time.Now())
+	if err != nil {
+		t.Fatalf("CreateUser: %v", err)
+	}
+	session, err := h.users.CreateSession(ctx, user.ID)
+	if err != nil {
+		t.Fatalf("CreateSession: %v", err)
+	}
+	cookies := []*http.Cookie{{Name: sessionCookie, Value: session.Token}}
+	if w := call(h.HandleMe, http.MethodGet, "", cookies, nil); w.Code != http.StatusOK {
+		t.Fatalf("live session: status %d, want %d", w.Code, http.StatusOK)
+	}
+
+	// Replace the session with one that expired; the store keeps the
+	// SHA-256 of tokens
+	if err := h.users.DeleteSession(ctx, session.Token); err != nil {
+		t.Fatalf("DeleteSession: %v", err)
+	}
+	sum := sha256.Sum256([]byte(session.Token))
+	if err := st.CreateSession(ctx, store.Session{
+		TokenHash: hex.EncodeToString(sum[:]),
+		UserID:    user.ID,
+		CSRFToken: session.CSRFToken,
+		CreatedAt: time.Now().Add(-2 * time.Hour),
+		ExpiresAt: time.Now().Add(-time.Hour),
+	}); err != nil {
+		t.Fatalf("CreateSession: %v", err)
+	}
+	if w := call(h.HandleMe, http.MethodGet, "", cookies, nil); w.Code != http.StatusUnauthorized {
+		t.Errorf("expired session: status %d, want %d", w.Code, http.StatusUnauthorized)
+	}
+}
+
+func TestCSRF(t *testing.T) {
+	h, _ := newAccountHandler(false)
+	w := call(h.HandleRegister, http.MethodPost, `{"username":"alice","password":"correct horse"}`, nil, nil)
+	acc, cookies := account(t, w)
+
+	tests := []struct {
+		name   string
+		header http.Header
+		status int
+	}{
+		{"missing token", nil, http.StatusForbidden},
+		{"wrong token", csrf("wrong"), http.StatusForbidden},
+		{"token of the session", csrf(acc.CSRFToken), http.StatusNoContent},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if w := call(h.HandleLogout, http.MethodPost, "", cookies, tt.header); w.Code != tt.status {
+				t.Errorf("logout: status %d, want %d", w.Code, tt.status)
+			}
+		})
+	}
+
+	if w := call(h.HandleMe, http.MethodGet, "", cookies, nil); w.Code != http.StatusUnauthorized {
+		t.Errorf("me after logout: status %d, want %d", w.Code, http.StatusUnauthorized)
+	}
+}
+
+func TestSecureCookie(t *testing.T) {
+	tests := []struct {
+		trustProxy bool
+		proto      string
+		secure     bool
+	}{
+		{false, "", false},
+		{false, "https", false},
+		{true, "", false},
+		{true, "https", true},
+	}
+	for _, tt := range tests {
+		h, _ := newAccountHandler(tt.trustProxy)
+		var header http.Header
+		if tt.proto != "" {
+			header = http.Header{"X-Forwarded-Proto": {tt.proto}}
+		}
+		w := call(h.HandleGuest, http.MethodPost, "", nil, header)
+		if _, cookies := account(t, w); len(cookies) != 1 || cookies[0].Secure != tt.secure {
+			t.Errorf("trustProxy %v, X-Forwarded-Proto %q: cookies %+v, want Secure %v",
+				tt.trustProxy, tt.proto, cookies, tt.secure)
+		}
+	}
+}
+
+func TestGuestSessions(t *testing.T) {
+	h, st := newAccountHandler(false)
+	ctx := context.Background()
+
+	w := call(h.HandleGuest, http.MethodPost, "", nil, nil)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("guest: status %d, want %d", w.Code, http.StatusCreated)
+	}
+	guest, guestCookies := account(t, w)
+	if !guest.User.Guest {
+		t.Fatalf("guest account = %+v, want a guest", guest)
+	}
+	if err := st.AddFollow(ctx, guest.User.ID, 1, time.Now()); err != nil {
+		t.Fatalf("AddFollow: %v", err)
+	}
+
+	// Asking again keeps the session
+	w = call(h.HandleGuest, http.MethodPost, "", guestCookies, nil)
+	if again, _ := account(t, w); w.Code != http.StatusOK || again.User.ID != guest.User.ID {
+		t.Errorf("guest again: status %d, account %+v; want the same guest", w.Code, again)
+	}
+
+	// Registering claims the guest and ends its session
+	w = call(h.HandleRegister, http.MethodPost, `{"username":"alice","password":"correct horse"}`, guestCookies, nil)
+	alice, _ := account(t, w)
+	if w.Code != http.StatusCreated || alice.User.ID != guest.User.ID || alice.User.Guest {
+		t.Fatalf("register as guest: status %d, account %+v; want guest %d claimed", w.Code, alice, guest.User.ID)
+	}
+	if w := call(h.HandleMe, http.MethodGet, "", guestCookies, nil); w.Code != http.StatusUnauthorized {
+		t.Errorf("guest session after registering: status %d, want %d", w.Code, http.StatusUnauthorized)
+	}
+
+	// Signing in merges another guest into the account
+	other, otherCookies := account(t, call(h.HandleGuest, http.MethodPost, "", nil, nil))
+	if err := st.AddFollow(ctx, other.User.ID, 2, time.Now()); err != nil {
+		t.Fatalf("AddFollow: %v", err)
+	}
+	w = call(h.HandleLogin, http.MethodPost, `{"username":"alice","password":"correct horse"}`, otherCookies, nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("login as guest: status %d, want %d", w.Code, http.StatusOK)
+	}
+	follows, err := st.Follows(ctx, alice.User.ID)
+	if err != nil || len(follows) != 2 {
+		t.Errorf("follows after merging = %+v, %v; want artists 1 and 2", follows, err)
+	}
+	if _, _, err := st.UserByName(ctx, other.User.Username); err == nil {
+		t.Error("merged guest was kept")
+	}
+}
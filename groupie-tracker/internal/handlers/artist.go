@@ -2,92 +2,95 @@
 package handlers
 
 import (
-	"encoding/json"
+	"context"
+	"errors"
 	"net/http"
 	"strconv"
-	"strings"
 
 	"groupie-tracker/internal/models"
 )
 
+// errArtistNotFound is returned when no artist matches the requested ID
+var errArtistNotFound = errors.New("artist not found")
+
+// HandleArtist serves GET /api/artists/{id} with the artist's details
 func (h *Handler) HandleArtist(w http.ResponseWriter, r *http.Request) {
-	// Extract artist ID from URL
-	idStr := strings.TrimPrefix(r.URL.Path, "/api/artist/")
-	id, err := strconv.Atoi(idStr)
-	if err != nil {
-		h.logger.Printf("Invalid artist ID: %v", err)
-		http.Error(w, "Invalid artist ID", http.StatusBadRequest)
+	id, ok := h.artistID(w, r)
+	if !ok {
 		return
 	}
 
-	// Get cached data
-	cachedData, err := h.cache.GetCachedData()
+	// Get cached data and find artist
+	artist, cachedData, err := h.findArtist(r.Context(), id)
 	if err != nil {
-		h.logger.Printf("Error getting cached data: %v", err)
-		http.Error(w, "Failed to fetch data", http.StatusInternalServerError)
-		return
-	}
-
-	// Find artist
-	var artist models.Artist
-	for _, a := range cachedData.ArtistsData {
-		if a.ID == id {
-			artist = a
-			break
-		}
-	}
-
-	if artist.ID == 0 {
-		http.Error(w, "Artist not found", http.StatusNotFound)
+		h.sendLookupError(w, r, err)
 		return
 	}
 
 	// Get artist details
 	details := models.ArtistDetail{
 		Artist:    artist,
-		Locations: h.search.GetLocations(id, cachedData.LocationsData),
+		Locations: h.search.GetLocations(r.Context(), id, cachedData.LocationsData),
 		Dates:     h.search.GetDates(id, cachedData.DatesData),
 		Relations: h.search.GetRelations(id, cachedData.RelationsData),
 	}
 
-	// Set response headers
-	w.Header().Set("Content-Type", "application/json")
-
-	// Encode and send response
-	if err := json.NewEncoder(w).Encode(details); err != nil {
-		h.logger.Printf("Error encoding artist details: %v", err)
-		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
-		return
-	}
+	h.sendJSON(w, r, details)
 }
 
+// HandleArtistDetails serves the artist page at GET /artist/{id}, rendered
+// server-side; the map is added by JavaScript
 func (h *Handler) HandleArtistDetails() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		// Extract artist ID from URL
-		parts := strings.Split(r.URL.Path, "/")
-		if len(parts) < 3 {
-			http.Error(w, "Invalid artist ID", http.StatusBadRequest)
+		id, ok := h.artistID(w, r)
+		if !ok {
 			return
 		}
 
-		// Get artist ID
-		artistID := parts[len(parts)-1]
-
-		// Create template data
-		data := struct {
-			ArtistID string
-		}{
-			ArtistID: artistID,
+		artist, cachedData, err := h.findArtist(r.Context(), id)
+		if err != nil {
+			h.sendLookupError(w, r, err)
+			return
 		}
 
-		// Set headers
-		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		h.render(w, r, "artist-details.html", newArtistPage(artist, cachedData))
+	}
+}
 
-		// Execute template
-		if err := h.artistTpl.Execute(w, data); err != nil {
-			h.logger.Printf("Error executing artist template: %v", err)
-			http.Error(w, "Failed to render template", http.StatusInternalServerError)
-			return
+// artistID parses the {id} path parameter, replying 400 when it is invalid
+func (h *Handler) artistID(w http.ResponseWriter, r *http.Request) (int, bool) {
+	idStr := r.PathValue("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil || id < 1 {
+		h.logger.DebugContext(r.Context(), "invalid artist ID", "id", idStr)
+		h.sendError(w, "Invalid artist ID", http.StatusBadRequest)
+		return 0, false
+	}
+	return id, true
+}
+
+// findArtist looks up an artist in the cached data
+func (h *Handler) findArtist(ctx context.Context, id int) (models.Artist, models.Datas, error) {
+	cachedData, err := h.cache.GetCachedData(ctx)
+	if err != nil {
+		return models.Artist{}, models.Datas{}, err
+	}
+
+	for _, a := range cachedData.ArtistsData {
+		if a.ID == id {
+			return a, cachedData, nil
 		}
 	}
+
+	return models.Artist{}, cachedData, errArtistNotFound
+}
+
+// sendLookupError replies to a failed findArtist call
+func (h *Handler) sendLookupError(w http.ResponseWriter, r *http.Request, err error) {
+	if errors.Is(err, errArtistNotFound) {
+		h.sendError(w, "Artist not found", http.StatusNotFound)
+		return
+	}
+	h.logger.ErrorContext(r.Context(), "error getting cached data", "error", err)
+	h.sendError(w, "Failed to fetch data", http.StatusInternalServerError)
 }
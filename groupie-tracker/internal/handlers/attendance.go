@@ -0,0 +1,263 @@
+// internal/handlers/attendance.go
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"time"
+
+	"groupie-tracker/internal/models"
+	"groupie-tracker/internal/service"
+)
+
+// maxAttendanceImportSize bounds attendance import bodies
+const maxAttendanceImportSize = 1 << 20
+
+// HandleAttended serves GET /api/me/attendance, the concerts the user
+// attended sorted by date
+func (h *Handler) HandleAttended(w http.ResponseWriter, r *http.Request) {
+	user, _, ok := h.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	attended, err := h.attended(r.Context(), user.ID)
+	if err != nil {
+		h.logger.ErrorContext(r.Context(), "error listing attended concerts", "error", err)
+		h.sendError(w, "Failed to list attended concerts", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	h.sendJSON(w, r, attended)
+}
+
+// HandleAttend serves PUT /api/me/attendance/{id}/{location}/{date}. It
+// marks a concert from the relations as attended, with an optional
+// models.AttendanceNote body; marking it again replaces the note.
+func (h *Handler) HandleAttend(w http.ResponseWriter, r *http.Request) {
+	user, _, ok := h.currentUser(w, r)
+	if !ok {
+		return
+	}
+	concert, ok := h.concert(w, r)
+	if !ok {
+		return
+	}
+
+	var note models.AttendanceNote
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxJSONBodySize)).Decode(&note); err != nil && !errors.Is(err, io.EOF) {
+		h.sendError(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if err := service.ValidateAttendanceNote(note); err != nil {
+		h.sendError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	attended, err := h.users.Attend(r.Context(), user.ID, models.Attendance{
+		ArtistID: concert.ArtistID,
+		Location: concert.Location,
+		Date:     concert.Date,
+		Rating:   note.Rating,
+		Notes:    note.Notes,
+	})
+	if err != nil {
+		h.logger.ErrorContext(r.Context(), "error marking concert attended", "error", err)
+		h.sendError(w, "Failed to mark concert attended", http.StatusInternalServerError)
+		return
+	}
+	described := []models.Attendance{attended}
+	h.describeAttended(r.Context(), described)
+	h.sendJSON(w, r, described[0])
+}
+
+// HandleUnattend serves DELETE /api/me/attendance/{id}/{location}/{date}
+func (h *Handler) HandleUnattend(w http.ResponseWriter, r *http.Request) {
+	user, _, ok := h.currentUser(w, r)
+	if !ok {
+		return
+	}
+	id, ok := h.artistID(w, r)
+	if !ok {
+		return
+	}
+
+	// The concert may have left the dataset since it was marked
+	date := models.NormalizeConcertDate(r.PathValue("date"))
+	if err := h.users.Unattend(r.Context(), user.ID, id, r.PathValue("location"), date); err != nil {
+		h.logger.ErrorContext(r.Context(), "error removing attended concert", "error", err)
+		h.sendError(w, "Failed to remove attended concert", http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// HandleAttendanceStats serves GET /api/me/attendance/stats: artists seen,
+// countries, first and last shows
+func (h *Handler) HandleAttendanceStats(w http.ResponseWriter, r *http.Request) {
+	user, _, ok := h.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	attended, err := h.attended(r.Context(), user.ID)
+	if err != nil {
+		h.logger.ErrorContext(r.Context(), "error listing attended concerts", "error", err)
+		h.sendError(w, "Failed to compute attendance stats", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	h.sendJSON(w, r, service.NewAttendanceStats(attended))
+}
+
+// HandleExportAttendance serves GET /api/me/attendance/export, the attended
+// concerts as a models.AttendanceLog download
+func (h *Handler) HandleExportAttendance(w http.ResponseWriter, r *http.Request) {
+	user, _, ok := h.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	attended, err := h.attended(r.Context(), user.ID)
+	if err != nil {
+		h.logger.ErrorContext(r.Context(), "error listing attended concerts", "error", err)
+		h.sendError(w, "Failed to export attended concerts", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("Content-Disposition", `attachment; filename="attended-concerts.json"`)
+	h.sendJSON(w, r, models.AttendanceLog{
+		Version:    models.AttendanceLogVersion,
+		ExportedAt: time.Now().UTC(),
+		Attended:   attended,
+	})
+}
+
+// HandleImportAttendance serves POST /api/me/attendance/import with a
+// models.AttendanceLog body, as exported. Entries matching no concert of
+// the dataset are skipped and reported; the rest are imported together.
+func (h *Handler) HandleImportAttendance(w http.ResponseWriter, r *http.Request) {
+	user, _, ok := h.currentUser(w, r)
+	if !ok {
+		return
+	}
+	if mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); mediaType != "application/json" {
+		h.sendError(w, "Content-Type must be application/json", http.StatusUnsupportedMediaType)
+		return
+	}
+
+	var upload models.AttendanceLog
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxAttendanceImportSize)).Decode(&upload); err != nil {
+		h.sendError(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if upload.Version > models.AttendanceLogVersion {
+		h.sendError(w, fmt.Sprintf("Unsupported version %d", upload.Version), http.StatusBadRequest)
+		return
+	}
+
+	cachedData, err := h.cache.GetCachedData(r.Context())
+	if err != nil {
+		h.sendLookupError(w, r, err)
+		return
+	}
+
+	result := models.AttendanceImport{}
+	imported := make([]models.Attendance, 0, len(upload.Attended))
+	for i, a := range upload.Attended {
+		if err := service.ValidateAttendanceNote(models.AttendanceNote{Rating: a.Rating, Notes: a.Notes}); err != nil {
+			h.sendError(w, fmt.Sprintf("attended[%d]: %v", i, err), http.StatusBadRequest)
+			return
+		}
+		concert, err := service.FindConcert(cachedData, a.ArtistID, a.Location, a.Date)
+		if err != nil {
+			result.Skipped = append(result.Skipped, a)
+			continue
+		}
+		a.Date = concert.Date
+		imported = append(imported, a)
+	}
+
+	if err := h.users.ImportAttended(r.Context(), user.ID, imported); err != nil {
+		h.logger.ErrorContext(r.Context(), "error importing attended concerts", "error", err)
+		h.sendError(w, "Failed to import attended concerts", http.StatusInternalServerError)
+		return
+	}
+	result.Imported = len(imported)
+
+	h.logger.InfoContext(r.Context(), "attended concerts imported",
+		"user_id", user.ID, "imported", result.Imported, "skipped", len(result.Skipped))
+	h.sendJSON(w, r, result)
+}
+
+// HandleMyShowsPage serves the attendance log with its stats at GET
+// /me/shows. Signed-out visitors are sent to sign in.
+func (h *Handler) HandleMyShowsPage(w http.ResponseWriter, r *http.Request) {
+	user, session, ok := h.pageUser(w, r)
+	if !ok {
+		return
+	}
+
+	attended, err := h.attended(r.Context(), user.ID)
+	if err != nil {
+		h.logger.ErrorContext(r.Context(), "error listing attended concerts", "error", err)
+		h.sendError(w, "Failed to list attended concerts", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	h.render(w, r, "my-shows.html", myShowsPage{
+		Username:  user.Username,
+		CSRFToken: session.CSRFToken,
+		Attended:  attended,
+		Stats:     service.NewAttendanceStats(attended),
+		Ratings:   []int{1, 2, 3, 4, 5},
+	})
+}
+
+// concert looks up the concert named by the {id}, {location} and {date}
+// path parameters, replying 404 when the artist did not play there then
+func (h *Handler) concert(w http.ResponseWriter, r *http.Request) (models.Concert, bool) {
+	id, ok := h.artistID(w, r)
+	if !ok {
+		return models.Concert{}, false
+	}
+	cachedData, err := h.cache.GetCachedData(r.Context())
+	if err != nil {
+		h.sendLookupError(w, r, err)
+		return models.Concert{}, false
+	}
+
+	concert, err := service.FindConcert(cachedData, id, r.PathValue("location"), r.PathValue("date"))
+	if err != nil {
+		h.sendError(w, "Concert not found", http.StatusNotFound)
+		return models.Concert{}, false
+	}
+	return concert, true
+}
+
+// attended lists the described concerts a user attended
+func (h *Handler) attended(ctx context.Context, userID int64) ([]models.Attendance, error) {
+	attended, err := h.users.Attended(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	h.describeAttended(ctx, attended)
+	return attended, nil
+}
+
+// describeAttended fills in the names, places and countries of attended
+// concerts when the cached data is available
+func (h *Handler) describeAttended(ctx context.Context, attended []models.Attendance) {
+	if cachedData, err := h.cache.GetCachedData(ctx); err == nil {
+		service.DescribeAttended(cachedData, attended)
+	}
+}
@@ -0,0 +1,102 @@
+// internal/handlers/export.go
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"groupie-tracker/internal/export"
+	"groupie-tracker/internal/models"
+)
+
+// HandleExport serves /api/export?q=&format=&what= with the same query and
+// FilterParams as /api/search. Artists are exported as JSON, NDJSON or CSV;
+// their concerts (what=events) additionally as an iCalendar file. The
+// response is streamed as it is encoded.
+func (h *Handler) HandleExport(w http.ResponseWriter, r *http.Request) {
+	params := r.URL.Query()
+
+	format := export.FormatCSV
+	if name := params.Get("format"); name != "" {
+		f, err := export.ParseFormat(name)
+		if err != nil {
+			h.sendError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		format = f
+	}
+
+	what := strings.ToLower(params.Get("what"))
+	switch {
+	case what == "" && format == export.FormatICS:
+		what = "events"
+	case what == "":
+		what = "artists"
+	case what != "artists" && what != "events":
+		h.sendError(w, fmt.Sprintf("Unknown export %q (want artists or events)", what), http.StatusBadRequest)
+		return
+	}
+	if what == "artists" && format == export.FormatICS {
+		h.sendError(w, "Artists cannot be exported as ics; use what=events", http.StatusBadRequest)
+		return
+	}
+
+	artists, data, _, ok := h.runSearch(w, r)
+	if !ok {
+		return
+	}
+
+	w.Header().Set("Content-Type", format.ContentType())
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="groupie-tracker-%s.%s"`, what, format))
+	w.Header().Set("Cache-Control", "no-store")
+
+	var err error
+	switch {
+	case what == "events" && format == export.FormatICS:
+		cal := export.Calendar{Name: "Groupie Tracker concerts", Stamp: h.cache.Info().FetchedAt}
+		err = export.WriteCalendar(w, cal, export.Events(data, artists))
+	case what == "events":
+		err = export.WriteEvents(w, format, export.Events(data, artists))
+	default:
+		err = export.WriteArtists(w, format, artists)
+	}
+	if err != nil {
+		// Headers are already sent; the client sees a truncated file
+		h.logger.WarnContext(r.Context(), "export interrupted", "format", format, "error", err)
+	}
+}
+
+// calendarRefresh is how often subscribed calendar clients should poll
+const calendarRefresh = 6 * time.Hour
+
+// HandleArtistCalendar serves GET /artist/{id}/concerts.ics, an iCalendar
+// feed of the artist's concerts that calendar apps can subscribe to
+func (h *Handler) HandleArtistCalendar(w http.ResponseWriter, r *http.Request) {
+	id, ok := h.artistID(w, r)
+	if !ok {
+		return
+	}
+
+	artist, cachedData, err := h.findArtist(r.Context(), id)
+	if err != nil {
+		h.sendLookupError(w, r, err)
+		return
+	}
+
+	cal := export.Calendar{
+		Name:    artist.Name + " concerts",
+		Stamp:   h.cache.Info().FetchedAt,
+		Refresh: calendarRefresh,
+		Geocode: h.search.CachedGeocode,
+	}
+
+	w.Header().Set("Content-Type", export.FormatICS.ContentType())
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`inline; filename="artist-%d-concerts.ics"`, artist.ID))
+	w.Header().Set("Cache-Control", "public, max-age=3600")
+
+	if err := export.WriteCalendar(w, cal, export.Events(cachedData, []models.Artist{artist})); err != nil {
+		h.logger.WarnContext(r.Context(), "calendar interrupted", "artist", artist.ID, "error", err)
+	}
+}
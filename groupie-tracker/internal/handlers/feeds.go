@@ -0,0 +1,140 @@
+// internal/handlers/feeds.go
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"groupie-tracker/internal/feed"
+	"groupie-tracker/internal/models"
+)
+
+// HandleConcertFeed serves /feeds/concerts.atom and .rss, listing concert
+// dates that appeared in the dataset since earlier refreshes
+func (h *Handler) HandleConcertFeed(format feed.Format) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		f := feed.Feed{
+			ID:    feed.TagURI("feeds/concerts"),
+			Title: "Groupie Tracker: new concerts",
+			Link:  h.absoluteURL("/"),
+		}
+		h.sendFeed(w, r, format, f, nil)
+	}
+}
+
+// HandleArtistFeed serves /feeds/artists/{id}/concerts.atom and .rss
+func (h *Handler) HandleArtistFeed(format feed.Format) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, ok := h.artistID(w, r)
+		if !ok {
+			return
+		}
+
+		artist, _, err := h.findArtist(r.Context(), id)
+		if err != nil {
+			h.sendLookupError(w, r, err)
+			return
+		}
+
+		f := feed.Feed{
+			ID:    feed.TagURI("feeds/artists/" + strconv.Itoa(id)),
+			Title: "Groupie Tracker: new " + artist.Name + " concerts",
+			Link:  h.absoluteURL("/artist/" + strconv.Itoa(id)),
+		}
+		h.sendFeed(w, r, format, f, func(a models.Announcement) bool {
+			return a.ArtistID == id
+		})
+	}
+}
+
+// HandleLocationFeed serves /feeds/locations/{location}/concerts.atom and
+// .rss, where location is a slug such as north_carolina-usa
+func (h *Handler) HandleLocationFeed(format feed.Format) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		location := r.PathValue("location")
+
+		cachedData, err := h.cache.GetCachedData(r.Context())
+		if err != nil {
+			h.logger.ErrorContext(r.Context(), "error getting cached data", "error", err)
+			h.sendError(w, "Failed to fetch data", http.StatusInternalServerError)
+			return
+		}
+		if !hasLocation(cachedData, location) {
+			h.sendError(w, "Location not found", http.StatusNotFound)
+			return
+		}
+
+		f := feed.Feed{
+			ID:    feed.TagURI("feeds/locations/" + location),
+			Title: "Groupie Tracker: new concerts in " + models.FormatLocation(location),
+			Link:  h.absoluteURL("/"),
+		}
+		h.sendFeed(w, r, format, f, func(a models.Announcement) bool {
+			return a.Location == location
+		})
+	}
+}
+
+// sendFeed fills f with the announcements matching keep and writes it
+func (h *Handler) sendFeed(w http.ResponseWriter, r *http.Request, format feed.Format, f feed.Feed, keep func(models.Announcement) bool) {
+	f.Self = h.absoluteURL(r.URL.Path)
+	f.Updated = h.cache.Info().FetchedAt
+
+	for _, a := range h.announcements.Recent(keep) {
+		place := models.FormatLocation(a.Location)
+		when := a.Date
+		if t, err := time.Parse("2006-01-02", a.Date); err == nil {
+			when = t.Format("January 2, 2006")
+		}
+
+		f.Entries = append(f.Entries, feed.Entry{
+			ID:      feed.TagURI("concerts/" + a.Key()),
+			Title:   fmt.Sprintf("%s in %s on %s", a.Artist, place, when),
+			Link:    h.absoluteURL("/artist/" + strconv.Itoa(a.ArtistID)),
+			Summary: fmt.Sprintf("%s announced a concert in %s on %s.", a.Artist, place, when),
+			Updated: a.FoundAt,
+		})
+		if a.FoundAt.After(f.Updated) {
+			f.Updated = a.FoundAt
+		}
+	}
+	if f.Updated.IsZero() {
+		f.Updated = time.Now()
+	}
+
+	w.Header().Set("Content-Type", format.ContentType())
+	w.Header().Set("Cache-Control", "public, max-age=300")
+	if err := feed.Write(w, format, f); err != nil {
+		h.logger.WarnContext(r.Context(), "feed interrupted", "format", format, "error", err)
+	}
+}
+
+// hasLocation reports whether any artist played at the location slug
+func hasLocation(data models.Datas, location string) bool {
+	for _, entry := range data.LocationsData.Index {
+		for _, loc := range entry.Locations {
+			if loc == location {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// absoluteURL resolves path against the configured base URL. The Host of
+// the request is never used: clients choose it, and feeds are cached by
+// shared caches.
+func (h *Handler) absoluteURL(path string) string {
+	return h.baseURL + path
+}
+
+// isHTTPS reports whether the client reached the site over HTTPS. Behind a
+// trusted proxy, X-Forwarded-Proto tells; otherwise any client could set it.
+func (h *Handler) isHTTPS(r *http.Request) bool {
+	if r.TLS != nil {
+		return true
+	}
+	return h.trustProxy && r.Header.Get("X-Forwarded-Proto") == "https"
+}
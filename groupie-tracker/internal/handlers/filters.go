@@ -0,0 +1,21 @@
+// internal/handlers/filters.go
+package handlers
+
+import (
+	"net/http"
+)
+
+// HandleFiltersMeta serves GET /api/filters/meta with the year ranges,
+// member counts and locations the search filters accept. The metadata is
+// recomputed once per cache refresh.
+func (h *Handler) HandleFiltersMeta(w http.ResponseWriter, r *http.Request) {
+	meta, err := h.filter.Meta(r.Context())
+	if err != nil {
+		h.logger.ErrorContext(r.Context(), "error getting filter metadata", "error", err)
+		h.sendError(w, "Failed to fetch data", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Cache-Control", "public, max-age=300")
+	h.sendJSON(w, r, meta)
+}
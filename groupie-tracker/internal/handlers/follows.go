@@ -0,0 +1,419 @@
+// internal/handlers/follows.go
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"groupie-tracker/internal/export"
+	"groupie-tracker/internal/geo"
+	"groupie-tracker/internal/models"
+	"groupie-tracker/internal/service"
+)
+
+// concertQuery selects the concerts of followed artists to list
+type concertQuery struct {
+	// From is the earliest date as YYYY-MM-DD, empty for every date
+	From string
+	// Near and RadiusKm keep concerts within RadiusKm of Near
+	Near     *models.GeoPoint
+	RadiusKm float64
+}
+
+// HandleFollows serves GET /api/me/follows
+func (h *Handler) HandleFollows(w http.ResponseWriter, r *http.Request) {
+	user, _, ok := h.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	follows, err := h.users.Follows(r.Context(), user.ID)
+	if err != nil {
+		h.logger.ErrorContext(r.Context(), "error listing follows", "error", err)
+		h.sendError(w, "Failed to list followed artists", http.StatusInternalServerError)
+		return
+	}
+
+	if cachedData, err := h.cache.GetCachedData(r.Context()); err == nil {
+		names := service.ArtistNames(cachedData)
+		for i := range follows {
+			follows[i].Name = names[follows[i].ArtistID]
+		}
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	h.sendJSON(w, r, follows)
+}
+
+// HandleFollow serves PUT /api/me/follows/{id}
+func (h *Handler) HandleFollow(w http.ResponseWriter, r *http.Request) {
+	user, _, ok := h.currentUser(w, r)
+	if !ok {
+		return
+	}
+	id, ok := h.artistID(w, r)
+	if !ok {
+		return
+	}
+	if _, _, err := h.findArtist(r.Context(), id); err != nil {
+		h.sendLookupError(w, r, err)
+		return
+	}
+
+	if err := h.users.Follow(r.Context(), user.ID, id); err != nil {
+		h.logger.ErrorContext(r.Context(), "error following artist", "error", err)
+		h.sendError(w, "Failed to follow artist", http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// HandleUnfollow serves DELETE /api/me/follows/{id}
+func (h *Handler) HandleUnfollow(w http.ResponseWriter, r *http.Request) {
+	user, _, ok := h.currentUser(w, r)
+	if !ok {
+		return
+	}
+	id, ok := h.artistID(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.users.Unfollow(r.Context(), user.ID, id); err != nil {
+		h.logger.ErrorContext(r.Context(), "error unfollowing artist", "error", err)
+		h.sendError(w, "Failed to unfollow artist", http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// HandleHome serves GET /api/me/home, 404 when no home is set
+func (h *Handler) HandleHome(w http.ResponseWriter, r *http.Request) {
+	user, _, ok := h.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	home, found, err := h.users.Home(r.Context(), user.ID)
+	if err != nil {
+		h.logger.ErrorContext(r.Context(), "error getting home", "error", err)
+		h.sendError(w, "Failed to get home location", http.StatusInternalServerError)
+		return
+	}
+	if !found {
+		h.sendError(w, "No home location set", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	h.sendJSON(w, r, home)
+}
+
+// HandleSetHome serves PUT /api/me/home with a models.Home body; the radius
+// defaults to service.DefaultRadiusKm
+func (h *Handler) HandleSetHome(w http.ResponseWriter, r *http.Request) {
+	user, _, ok := h.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	var home models.Home
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxJSONBodySize)).Decode(&home); err != nil {
+		h.sendError(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if err := home.Validate(); err != nil {
+		h.sendError(w, "Invalid home location: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	if home.RadiusKm == 0 {
+		home.RadiusKm = service.DefaultRadiusKm
+	}
+	if home.RadiusKm < 0 || home.RadiusKm > service.MaxRadiusKm {
+		h.sendError(w, fmt.Sprintf("radiusKm must be between 0 and %d", service.MaxRadiusKm), http.StatusBadRequest)
+		return
+	}
+
+	if err := h.users.SetHome(r.Context(), user.ID, home); err != nil {
+		h.logger.ErrorContext(r.Context(), "error setting home", "error", err)
+		h.sendError(w, "Failed to set home location", http.StatusInternalServerError)
+		return
+	}
+	h.sendJSON(w, r, home)
+}
+
+// HandleClearHome serves DELETE /api/me/home
+func (h *Handler) HandleClearHome(w http.ResponseWriter, r *http.Request) {
+	user, _, ok := h.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.users.ClearHome(r.Context(), user.ID); err != nil {
+		h.logger.ErrorContext(r.Context(), "error clearing home", "error", err)
+		h.sendError(w, "Failed to clear home location", http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// HandleMyConcerts serves GET /api/me/concerts, the upcoming concerts of
+// the artists the user follows sorted by date. Query parameters: from
+// (YYYY-MM-DD, default today, or "all"), near=lat,lon or home=true to keep
+// concerts around a point or the user's home, and radiusKm. Locations
+// without coordinates are geocoded on demand.
+func (h *Handler) HandleMyConcerts(w http.ResponseWriter, r *http.Request) {
+	user, _, ok := h.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	query, err := h.concertQuery(r.Context(), user.ID, r.URL.Query(), time.Now().Format("2006-01-02"))
+	if err != nil {
+		h.sendError(w, "Invalid parameters: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	concerts, err := h.userConcerts(r.Context(), user.ID, query, h.geocodeOnDemand)
+	if err != nil {
+		h.logger.ErrorContext(r.Context(), "error listing concerts", "error", err)
+		h.sendError(w, "Failed to list concerts", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	h.sendJSON(w, r, concerts)
+}
+
+// HandleMyConcertsPage serves the concerts of followed artists at
+// GET /me/concerts, taking the query parameters of /api/me/concerts. Only
+// cached geocodes are used. Signed-out visitors are sent to sign in.
+func (h *Handler) HandleMyConcertsPage(w http.ResponseWriter, r *http.Request) {
+	user, session, ok := h.pageUser(w, r)
+	if !ok {
+		return
+	}
+
+	page := myConcertsPage{
+		Username:  user.Username,
+		Guest:     user.Guest,
+		CSRFToken: session.CSRFToken,
+		From:      r.URL.Query().Get("from"),
+		NearHome:  r.URL.Query().Get("home") == "true",
+	}
+
+	home, found, err := h.users.Home(r.Context(), user.ID)
+	if err != nil {
+		h.logger.ErrorContext(r.Context(), "error getting home", "error", err)
+		h.sendError(w, "Failed to get home location", http.StatusInternalServerError)
+		return
+	}
+	if found {
+		page.Home = &home
+	}
+
+	query, err := h.concertQuery(r.Context(), user.ID, r.URL.Query(), time.Now().Format("2006-01-02"))
+	if err != nil {
+		h.sendError(w, "Invalid parameters: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	page.RadiusKm = query.RadiusKm
+
+	if page.Follows, err = h.users.Follows(r.Context(), user.ID); err == nil {
+		page.Concerts, err = h.userConcerts(r.Context(), user.ID, query, h.cachedGeocode)
+	}
+	if err != nil {
+		h.logger.ErrorContext(r.Context(), "error listing concerts", "error", err)
+		h.sendError(w, "Failed to list concerts", http.StatusInternalServerError)
+		return
+	}
+	if cachedData, err := h.cache.GetCachedData(r.Context()); err == nil {
+		names := service.ArtistNames(cachedData)
+		for i := range page.Follows {
+			page.Follows[i].Name = names[page.Follows[i].ArtistID]
+		}
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	h.render(w, r, "my-concerts.html", page)
+}
+
+// HandleCreateCalendar serves POST /api/me/calendar. It replies with the
+// URL of a private calendar of the followed artists' concerts; creating a
+// new one revokes the previous URL.
+func (h *Handler) HandleCreateCalendar(w http.ResponseWriter, r *http.Request) {
+	user, _, ok := h.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	token, err := h.users.CreateCalendarToken(r.Context(), user.ID)
+	if err != nil {
+		h.logger.ErrorContext(r.Context(), "error creating calendar token", "error", err)
+		h.sendError(w, "Failed to create calendar", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	h.sendJSON(w, r, map[string]string{
+		"url": h.absoluteURL("/calendar/" + token + "/concerts.ics"),
+	})
+}
+
+// HandleRevokeCalendar serves DELETE /api/me/calendar
+func (h *Handler) HandleRevokeCalendar(w http.ResponseWriter, r *http.Request) {
+	user, _, ok := h.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.users.RevokeCalendarToken(r.Context(), user.ID); err != nil {
+		h.logger.ErrorContext(r.Context(), "error revoking calendar token", "error", err)
+		h.sendError(w, "Failed to revoke calendar", http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// HandlePrivateCalendar serves GET /calendar/{token}/concerts.ics, the
+// concerts of the artists a user follows as an iCalendar feed. It accepts
+// the query parameters of /api/me/concerts, defaulting to every date, and
+// only uses cached geocodes.
+func (h *Handler) HandlePrivateCalendar(w http.ResponseWriter, r *http.Request) {
+	user, err := h.users.CalendarUser(r.Context(), r.PathValue("token"))
+	if errors.Is(err, service.ErrCalendarNotFound) {
+		h.sendError(w, "Calendar not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		h.logger.ErrorContext(r.Context(), "error looking up calendar", "error", err)
+		h.sendError(w, "Failed to fetch calendar", http.StatusInternalServerError)
+		return
+	}
+
+	query, err := h.concertQuery(r.Context(), user.ID, r.URL.Query(), "")
+	if err != nil {
+		h.sendError(w, "Invalid parameters: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	concerts, err := h.userConcerts(r.Context(), user.ID, query, h.cachedGeocode)
+	if err != nil {
+		h.logger.ErrorContext(r.Context(), "error listing concerts", "error", err)
+		h.sendError(w, "Failed to fetch calendar", http.StatusInternalServerError)
+		return
+	}
+
+	events := make([]export.Event, len(concerts))
+	for i, c := range concerts {
+		events[i] = export.Event{ArtistID: c.ArtistID, Artist: c.Artist, Location: c.Location, Place: c.Place, Date: c.Date}
+	}
+	cal := export.Calendar{
+		Name:    "My concerts",
+		Stamp:   h.cache.Info().FetchedAt,
+		Refresh: calendarRefresh,
+		Geocode: h.search.CachedGeocode,
+	}
+
+	w.Header().Set("Content-Type", export.FormatICS.ContentType())
+	w.Header().Set("Content-Disposition", `inline; filename="my-concerts.ics"`)
+	w.Header().Set("Cache-Control", "private, max-age=3600")
+	if err := export.WriteCalendar(w, cal, events); err != nil {
+		h.logger.WarnContext(r.Context(), "calendar interrupted", "user_id", user.ID, "error", err)
+	}
+}
+
+// concertQuery parses the concert selection of a request; from applies when
+// the request has none
+func (h *Handler) concertQuery(ctx context.Context, userID int64, values url.Values, from string) (concertQuery, error) {
+	query := concertQuery{From: from}
+	switch raw := values.Get("from"); raw {
+	case "":
+	case "all":
+		query.From = ""
+	default:
+		if _, err := time.Parse("2006-01-02", raw); err != nil {
+			return concertQuery{}, fmt.Errorf("from must be YYYY-MM-DD or all, got %q", raw)
+		}
+		query.From = raw
+	}
+
+	if raw := values.Get("near"); raw != "" {
+		near, err := models.ParseGeoPoint(raw)
+		if err != nil {
+			return concertQuery{}, fmt.Errorf("near: %w", err)
+		}
+		query.Near = &near
+		query.RadiusKm = service.DefaultRadiusKm
+	} else if values.Get("home") == "true" {
+		home, found, err := h.users.Home(ctx, userID)
+		if err != nil {
+			return concertQuery{}, err
+		}
+		if !found {
+			return concertQuery{}, errors.New("no home location set")
+		}
+		query.Near = &home.GeoPoint
+		query.RadiusKm = home.RadiusKm
+	}
+
+	if raw := values.Get("radiusKm"); raw != "" {
+		radius, err := strconv.ParseFloat(raw, 64)
+		if err != nil || radius <= 0 || radius > service.MaxRadiusKm {
+			return concertQuery{}, fmt.Errorf("radiusKm must be between 0 and %d", service.MaxRadiusKm)
+		}
+		query.RadiusKm = radius
+	}
+	return query, nil
+}
+
+// userConcerts lists the concerts of the artists a user follows that match
+// the query
+func (h *Handler) userConcerts(ctx context.Context, userID int64, query concertQuery, geocode geocodeFunc) ([]models.Concert, error) {
+	follows, err := h.users.Follows(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	cachedData, err := h.cache.GetCachedData(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]int, len(follows))
+	for i, f := range follows {
+		ids[i] = f.ArtistID
+	}
+
+	concerts := []models.Concert{}
+	for _, c := range service.ArtistConcerts(cachedData, ids) {
+		if query.From != "" && c.Date < query.From {
+			continue
+		}
+		if query.Near != nil {
+			loc, ok := geocode(ctx, c.Location)
+			if !ok {
+				continue
+			}
+			km := geo.Distance(query.Near.Lat, query.Near.Lon, loc.Lat, loc.Lon)
+			if km > query.RadiusKm {
+				continue
+			}
+			km = math.Round(km*10) / 10
+			c.DistanceKm = &km
+		}
+		concerts = append(concerts, c)
+	}
+	return concerts, nil
+}
+
+// cachedGeocode resolves locations from the geocode cache only
+func (h *Handler) cachedGeocode(_ context.Context, location string) (models.GeoLocation, bool) {
+	return h.search.CachedGeocode(location)
+}
@@ -0,0 +1,160 @@
+// internal/handlers/geojson.go
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"groupie-tracker/internal/geojson"
+	"groupie-tracker/internal/models"
+	"groupie-tracker/internal/service"
+)
+
+// HandleArtistGeoJSON serves GET /api/artists/{id}/locations.geojson, one
+// Point feature per concert location of the artist. Locations that were
+// never geocoded are looked up on demand.
+func (h *Handler) HandleArtistGeoJSON(w http.ResponseWriter, r *http.Request) {
+	id, ok := h.artistID(w, r)
+	if !ok {
+		return
+	}
+
+	_, cachedData, err := h.findArtist(r.Context(), id)
+	if err != nil {
+		h.sendLookupError(w, r, err)
+		return
+	}
+
+	visits := service.VisitsByLocation(cachedData, func(artistID int) bool { return artistID == id })
+	h.sendGeoJSON(w, r, h.locationFeatures(r.Context(), visits, h.geocodeOnDemand))
+}
+
+// HandleLocationsGeoJSON serves GET /api/locations.geojson, one Point
+// feature per concert location across all artists. Only locations already
+// in the geocode cache are included, so the request never calls Mapbox.
+func (h *Handler) HandleLocationsGeoJSON(w http.ResponseWriter, r *http.Request) {
+	cachedData, err := h.cache.GetCachedData(r.Context())
+	if err != nil {
+		h.logger.ErrorContext(r.Context(), "error getting cached data", "error", err)
+		h.sendError(w, "Failed to fetch data", http.StatusInternalServerError)
+		return
+	}
+
+	visits := service.VisitsByLocation(cachedData, nil)
+	features := h.locationFeatures(r.Context(), visits, func(_ context.Context, location string) (models.GeoLocation, bool) {
+		return h.search.CachedGeocode(location)
+	})
+	h.sendGeoJSON(w, r, features)
+}
+
+// geocodeFunc resolves a location slug to coordinates
+type geocodeFunc func(ctx context.Context, location string) (models.GeoLocation, bool)
+
+// geocodeOnDemand geocodes through the cache, calling Mapbox on a miss
+func (h *Handler) geocodeOnDemand(ctx context.Context, location string) (models.GeoLocation, bool) {
+	geo, err := h.search.Geocode(ctx, location)
+	if err != nil {
+		h.logger.WarnContext(ctx, "geocoding failed", "location", location, "error", err)
+		return models.GeoLocation{}, false
+	}
+	return geo, true
+}
+
+// locationFeatures converts visits into Point features, skipping locations
+// without coordinates
+func (h *Handler) locationFeatures(ctx context.Context, visits []service.LocationVisits, geocode geocodeFunc) []geojson.Feature {
+	features := make([]geojson.Feature, 0, len(visits))
+	for _, v := range visits {
+		geo, ok := geocode(ctx, v.Location)
+		if !ok {
+			continue
+		}
+		features = append(features, geojson.NewFeature(v.Location, geojson.Point(geo.Lat, geo.Lon), map[string]interface{}{
+			"location":  v.Location,
+			"name":      models.FormatLocation(v.Location),
+			"artistIds": v.ArtistIDs,
+			"dates":     v.Dates,
+			"visits":    v.Visits,
+		}))
+	}
+	return features
+}
+
+// sendGeoJSON writes features as a FeatureCollection
+func (h *Handler) sendGeoJSON(w http.ResponseWriter, r *http.Request, features []geojson.Feature) {
+	w.Header().Set("Content-Type", geojson.ContentType)
+	if err := json.NewEncoder(w).Encode(geojson.NewFeatureCollection(features)); err != nil {
+		h.logger.ErrorContext(r.Context(), "error encoding response", "error", err)
+	}
+}
+
+// HandleArtistRoute serves GET /api/artists/{id}/route, the artist's
+// concerts in chronological order with leg distances and statistics
+func (h *Handler) HandleArtistRoute(w http.ResponseWriter, r *http.Request) {
+	route, ok := h.artistRoute(w, r)
+	if !ok {
+		return
+	}
+	h.sendJSON(w, r, route)
+}
+
+// HandleArtistRouteGeoJSON serves GET /api/artists/{id}/route.geojson: a
+// LineString through the geocoded stops followed by one Point per stop
+func (h *Handler) HandleArtistRouteGeoJSON(w http.ResponseWriter, r *http.Request) {
+	route, ok := h.artistRoute(w, r)
+	if !ok {
+		return
+	}
+
+	var path [][2]float64
+	var stops []geojson.Feature
+	for i, stop := range route.Stops {
+		if !stop.Geocoded {
+			continue
+		}
+		path = append(path, [2]float64{stop.Lat, stop.Lon})
+		stops = append(stops, geojson.NewFeature(nil, geojson.Point(stop.Lat, stop.Lon), map[string]interface{}{
+			"order":    i,
+			"location": stop.Location,
+			"name":     stop.Name,
+			"date":     stop.Date,
+		}))
+	}
+
+	properties := map[string]interface{}{
+		"artistId":  route.ArtistID,
+		"totalKm":   route.TotalKm,
+		"countries": route.Countries,
+		"legs":      len(route.Legs),
+	}
+	if route.Longest != nil {
+		properties["longestHopKm"] = route.Longest.DistanceKm
+	}
+
+	var features []geojson.Feature
+	if len(path) >= 2 {
+		features = append(features, geojson.NewFeature("route", geojson.LineString(path), properties))
+	}
+	h.sendGeoJSON(w, r, append(features, stops...))
+}
+
+// artistRoute builds the route of the artist in the {id} path parameter,
+// geocoding its locations on demand
+func (h *Handler) artistRoute(w http.ResponseWriter, r *http.Request) (models.Route, bool) {
+	id, ok := h.artistID(w, r)
+	if !ok {
+		return models.Route{}, false
+	}
+
+	_, cachedData, err := h.findArtist(r.Context(), id)
+	if err != nil {
+		h.sendLookupError(w, r, err)
+		return models.Route{}, false
+	}
+
+	ctx := r.Context()
+	return service.BuildRoute(cachedData, id, func(location string) (models.GeoLocation, bool) {
+		return h.geocodeOnDemand(ctx, location)
+	}), true
+}
@@ -0,0 +1,47 @@
+// internal/handlers/graphql.go
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"groupie-tracker/internal/gql"
+)
+
+// maxGraphQLBody bounds the size of a posted GraphQL request
+const maxGraphQLBody = 64 << 10
+
+// HandleGraphQL serves GET and POST /graphql
+func (h *Handler) HandleGraphQL(w http.ResponseWriter, r *http.Request) {
+	var req gql.Request
+
+	if r.Method == http.MethodGet {
+		query := r.URL.Query()
+		req.Query = query.Get("query")
+		req.OperationName = query.Get("operationName")
+		if vars := query.Get("variables"); vars != "" {
+			if err := json.Unmarshal([]byte(vars), &req.Variables); err != nil {
+				h.sendError(w, "Invalid variables: "+err.Error(), http.StatusBadRequest)
+				return
+			}
+		}
+	} else {
+		r.Body = http.MaxBytesReader(w, r.Body, maxGraphQLBody)
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			h.sendError(w, "Invalid GraphQL request: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	if req.Query == "" {
+		h.sendError(w, "Missing GraphQL query", http.StatusBadRequest)
+		return
+	}
+
+	result := h.graphql.Execute(r.Context(), req)
+	if result.HasErrors() {
+		h.logger.DebugContext(r.Context(), "graphql query returned errors", "errors", len(result.Errors))
+	}
+
+	h.sendJSON(w, r, result)
+}
@@ -2,37 +2,118 @@
 package handlers
 
 import (
+	"bytes"
+	"encoding/json"
 	"html/template"
-	"log"
+	"log/slog"
+	"net/http"
 
+	"groupie-tracker/internal/gql"
+	"groupie-tracker/internal/models"
 	"groupie-tracker/internal/service"
 )
 
+// maxJSONBodySize bounds small JSON request bodies, such as settings and
+// notes
+const maxJSONBodySize = 64 << 10
+
+// TemplateSource provides parsed page templates by file name
+type TemplateSource interface {
+	Template(name string) (*template.Template, error)
+}
+
 type Config struct {
-	IndexTemplate  *template.Template
-	ArtistTemplate *template.Template
+	Templates      TemplateSource
 	CacheService   *service.CacheService
 	FilterService  *service.FilterService
 	SearchService  *service.SearchService
-	Logger         *log.Logger
+	CatalogService *service.CatalogService
+	Announcements  *service.AnnouncementService
+	SavedSearches  *service.SavedSearchService
+	UserService    *service.UserService
+	GraphQL        *gql.Service
+	Logger         *slog.Logger
+	// TrustProxy takes the scheme of requests from X-Forwarded-Proto
+	TrustProxy bool
+	// BaseURL is the public URL of the site without a trailing slash, such
+	// as https://example.com; absolute links in feeds, calendars and saved
+	// searches start with it
+	BaseURL string
 }
 
 type Handler struct {
-	indexTpl  *template.Template
-	artistTpl *template.Template
-	cache     *service.CacheService
-	filter    *service.FilterService
-	search    *service.SearchService
-	logger    *log.Logger
+	templates     TemplateSource
+	cache         *service.CacheService
+	filter        *service.FilterService
+	search        *service.SearchService
+	catalog       *service.CatalogService
+	announcements *service.AnnouncementService
+	saved         *service.SavedSearchService
+	users         *service.UserService
+	graphql       *gql.Service
+	logger        *slog.Logger
+	trustProxy    bool
+	baseURL       string
 }
 
 func NewHandler(config Config) *Handler {
 	return &Handler{
-		indexTpl:  config.IndexTemplate,
-		artistTpl: config.ArtistTemplate,
-		cache:     config.CacheService,
-		filter:    config.FilterService,
-		search:    config.SearchService,
-		logger:    config.Logger,
+		templates:     config.Templates,
+		cache:         config.CacheService,
+		filter:        config.FilterService,
+		search:        config.SearchService,
+		catalog:       config.CatalogService,
+		announcements: config.Announcements,
+		saved:         config.SavedSearches,
+		users:         config.UserService,
+		graphql:       config.GraphQL,
+		logger:        config.Logger,
+		trustProxy:    config.TrustProxy,
+		baseURL:       config.BaseURL,
+	}
+}
+
+// render executes the named page template as an HTML response
+func (h *Handler) render(w http.ResponseWriter, r *http.Request, name string, data interface{}) {
+	tpl, err := h.templates.Template(name)
+	if err != nil {
+		h.logger.ErrorContext(r.Context(), "error loading template", "template", name, "error", err)
+		h.sendError(w, "Failed to render template", http.StatusInternalServerError)
+		return
+	}
+
+	// Render into a buffer so a failing template still yields a clean error
+	var buf bytes.Buffer
+	if err := tpl.Execute(&buf, data); err != nil {
+		h.logger.ErrorContext(r.Context(), "error executing template", "template", name, "error", err)
+		h.sendError(w, "Failed to render template", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	buf.WriteTo(w)
+}
+
+// sendJSON encodes v as the JSON response body
+func (h *Handler) sendJSON(w http.ResponseWriter, r *http.Request, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		h.logger.ErrorContext(r.Context(), "error encoding response", "error", err)
+	}
+}
+
+// sendError sends a JSON error response
+func (h *Handler) sendError(w http.ResponseWriter, message string, code int) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+
+	err := models.Error{
+		Code:    code,
+		Message: message,
+	}
+
+	if encodeErr := json.NewEncoder(w).Encode(err); encodeErr != nil {
+		h.logger.Error("error encoding error response", "error", encodeErr)
 	}
 }
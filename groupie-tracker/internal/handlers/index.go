@@ -5,22 +5,47 @@ import (
 	"net/http"
 )
 
+// HandleIndex serves the home page at GET / with the artist grid and
+// filters rendered server-side. The URL may carry a ?q= query and
+// FilterParams as understood by /api/search, which restores their state.
 func (h *Handler) HandleIndex() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		// Only handle the root path
-		if r.URL.Path != "/" {
-			http.NotFound(w, r)
+		cachedData, err := h.cache.GetCachedData(r.Context())
+		if err != nil {
+			h.logger.ErrorContext(r.Context(), "error getting cached data", "error", err)
+			h.sendError(w, "Failed to fetch data", http.StatusInternalServerError)
 			return
 		}
 
-		// Set headers
-		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		meta, err := h.filter.Meta(r.Context())
+		if err != nil {
+			h.logger.ErrorContext(r.Context(), "error getting filter metadata", "error", err)
+			h.sendError(w, "Failed to fetch data", http.StatusInternalServerError)
+			return
+		}
 
-		// Execute template
-		if err := h.indexTpl.Execute(w, nil); err != nil {
-			h.logger.Printf("Error executing index template: %v", err)
-			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		query, filters, err := h.searchParams(r, meta)
+		if err != nil {
+			h.sendError(w, "Invalid filter parameters: "+err.Error(), http.StatusBadRequest)
 			return
 		}
+
+		artists := cachedData.ArtistsData
+		if len(r.URL.Query()) > 0 {
+			artists, err = h.search.SearchArtists(r.Context(), query, filters)
+			if err != nil {
+				h.logger.ErrorContext(r.Context(), "error searching artists", "error", err)
+				h.sendError(w, "Failed to search artists", http.StatusInternalServerError)
+				return
+			}
+		}
+
+		page := indexPage{
+			Query:   query,
+			Artists: artists,
+			Filters: newFilterOptions(meta, filters),
+		}
+
+		h.render(w, r, "index.html", page)
 	}
 }
@@ -0,0 +1,151 @@
+// internal/handlers/pages.go
+package handlers
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"groupie-tracker/internal/models"
+	"groupie-tracker/internal/service"
+)
+
+// indexPage is the data rendered by index.html
+type indexPage struct {
+	Query   string
+	Artists []models.Artist
+	Filters filterOptions
+}
+
+// filterOptions describes the filter controls rendered on the index page
+// and their current state
+type filterOptions struct {
+	CreationYearMin   int
+	CreationYearMax   int
+	CreationYear      int
+	FirstAlbumYearMin int
+	FirstAlbumYearMax int
+	FirstAlbumYear    int
+	Members           []filterChoice
+	Locations         []filterChoice
+}
+
+// filterChoice is a checkbox of the index page filters
+type filterChoice struct {
+	Value   string
+	Label   string
+	Checked bool
+}
+
+// artistPage is the data rendered by artist-details.html
+type artistPage struct {
+	Artist    models.Artist
+	Members   string
+	Locations []models.NamedPlace
+	Dates     []string
+	Concerts  []models.LocationDates
+	// Shows are the concerts a signed-in user can log as attended
+	Shows []models.Concert
+}
+
+// myConcertsPage is the data rendered by my-concerts.html
+type myConcertsPage struct {
+	Username  string
+	Guest     bool
+	CSRFToken string
+	Follows   []models.Follow
+	Concerts  []models.Concert
+	Home      *models.Home
+	// Selection of the concerts shown
+	From     string
+	NearHome bool
+	RadiusKm float64
+}
+
+// myShowsPage is the data rendered by my-shows.html
+type myShowsPage struct {
+	Username  string
+	CSRFToken string
+	Attended  []models.Attendance
+	Stats     models.AttendanceStats
+	// Ratings are the choices of the rating selects
+	Ratings []int
+}
+
+// newFilterOptions converts the filter metadata into the index page
+// controls, selecting the values of the active filters
+func newFilterOptions(meta models.FilterMeta, filters models.FilterParams) filterOptions {
+	options := filterOptions{
+		CreationYearMin:   meta.CreationYear.Min,
+		CreationYearMax:   meta.CreationYear.Max,
+		CreationYear:      max(filters.CreationYearMin, meta.CreationYear.Min),
+		FirstAlbumYearMin: meta.FirstAlbumYear.Min,
+		FirstAlbumYearMax: meta.FirstAlbumYear.Max,
+		FirstAlbumYear:    max(filters.FirstAlbumYearMin, meta.FirstAlbumYear.Min),
+	}
+	for _, count := range meta.MemberCounts {
+		options.Members = append(options.Members, filterChoice{
+			Value:   strconv.Itoa(count),
+			Label:   strconv.Itoa(count),
+			Checked: models.ContainsInt(filters.Members, count),
+		})
+	}
+	for _, place := range meta.Locations {
+		options.Locations = append(options.Locations, filterChoice{
+			Value:   place.Slug,
+			Label:   place.Name,
+			Checked: models.ContainsString(filters.Locations, place.Slug),
+		})
+	}
+	return options
+}
+
+// newArtistPage gathers the details of an artist for server-side rendering
+func newArtistPage(artist models.Artist, data models.Datas) artistPage {
+	page := artistPage{
+		Artist:  artist,
+		Members: strings.Join(artist.Members, ", "),
+	}
+
+	for _, entry := range data.LocationsData.Index {
+		if entry.ID == artist.ID {
+			for _, slug := range entry.Locations {
+				page.Locations = append(page.Locations, models.NamedPlace{Slug: slug, Name: models.FormatLocation(slug)})
+			}
+			break
+		}
+	}
+	for _, entry := range data.DatesData.Index {
+		if entry.ID == artist.ID {
+			for _, raw := range entry.Dates {
+				page.Dates = append(page.Dates, displayDate(raw))
+			}
+			break
+		}
+	}
+	for _, entry := range data.RelationsData.Index {
+		if entry.ID == artist.ID {
+			page.Concerts = service.NewLocationDates(entry.DatesLocations)
+			for i := range page.Concerts {
+				for j, iso := range page.Concerts[i].Dates {
+					if t, err := time.Parse("2006-01-02", iso); err == nil {
+						page.Concerts[i].Dates[j] = t.Format("January 2, 2006")
+					}
+				}
+			}
+			break
+		}
+	}
+	page.Shows = service.ArtistConcerts(data, []int{artist.ID})
+
+	return page
+}
+
+// displayDate formats an upstream concert date for humans
+func displayDate(raw string) string {
+	formatted, err := models.FormatDate(strings.TrimPrefix(raw, "*"))
+	if err != nil {
+		return raw
+	}
+	return formatted
+}
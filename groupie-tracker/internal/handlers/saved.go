@@ -0,0 +1,87 @@
+// internal/handlers/saved.go
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"groupie-tracker/internal/models"
+	"groupie-tracker/internal/service"
+)
+
+// HandleSaveSearch serves POST /api/searches?q= and stores the query with
+// its FilterParams, read like those of /api/search. It replies 201 with the
+// saved search and its shareable /s/{id} URL.
+func (h *Handler) HandleSaveSearch(w http.ResponseWriter, r *http.Request) {
+	meta, err := h.filter.Meta(r.Context())
+	if err != nil {
+		h.logger.ErrorContext(r.Context(), "error getting filter metadata", "error", err)
+		h.sendError(w, "Failed to fetch data", http.StatusInternalServerError)
+		return
+	}
+
+	query, filters, err := h.searchParams(r, meta)
+	if err != nil {
+		h.sendError(w, "Invalid filter parameters: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	saved, err := h.saved.Save(r.Context(), query, compactFilters(filters, service.DefaultFilters(meta)))
+	if errors.Is(err, service.ErrSavedSearchLimit) {
+		h.sendError(w, "Too many saved searches", http.StatusInsufficientStorage)
+		return
+	}
+	if err != nil {
+		h.logger.ErrorContext(r.Context(), "error saving search", "error", err)
+		h.sendError(w, "Failed to save search", http.StatusInternalServerError)
+		return
+	}
+
+	saved.URL = h.absoluteURL("/s/" + saved.ID)
+	w.Header().Set("Location", saved.URL)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	h.sendJSON(w, r, saved)
+}
+
+// HandleSavedSearch serves GET /api/searches/{id}
+func (h *Handler) HandleSavedSearch(w http.ResponseWriter, r *http.Request) {
+	saved, ok := h.savedSearch(w, r)
+	if !ok {
+		return
+	}
+
+	saved.URL = h.absoluteURL("/s/" + saved.ID)
+	h.sendJSON(w, r, saved)
+}
+
+// HandleSavedSearchRedirect serves GET /s/{id} by redirecting to the home
+// page with the saved query and filters in its URL
+func (h *Handler) HandleSavedSearchRedirect(w http.ResponseWriter, r *http.Request) {
+	saved, ok := h.savedSearch(w, r)
+	if !ok {
+		return
+	}
+
+	target := "/"
+	if values := encodeFilterQuery(saved.Query, saved.Filters); len(values) > 0 {
+		target += "?" + values.Encode()
+	}
+	http.Redirect(w, r, target, http.StatusFound)
+}
+
+// savedSearch looks up the {id} path parameter, replying 404 when no search
+// was saved under it
+func (h *Handler) savedSearch(w http.ResponseWriter, r *http.Request) (models.SavedSearch, bool) {
+	saved, found, err := h.saved.Get(r.Context(), r.PathValue("id"))
+	if err != nil {
+		h.logger.ErrorContext(r.Context(), "error looking up saved search", "error", err)
+		h.sendError(w, "Failed to look up saved search", http.StatusInternalServerError)
+		return models.SavedSearch{}, false
+	}
+	if !found {
+		h.sendError(w, "Saved search not found", http.StatusNotFound)
+		return models.SavedSearch{}, false
+	}
+	return saved, true
+}
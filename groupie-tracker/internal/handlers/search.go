@@ -5,176 +5,364 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"net/url"
 	"strconv"
 	"strings"
 	"time"
 
+	"groupie-tracker/internal/geo"
 	"groupie-tracker/internal/models"
+	"groupie-tracker/internal/service"
 )
 
-// HandleSearch handles the search API endpoint
+// HandleSearch serves POST /api/search?q= with optional FilterParams in the
+// body or the query string
 func (h *Handler) HandleSearch(w http.ResponseWriter, r *http.Request) {
-	// Set CORS and content type headers
-	w.Header().Set("Content-Type", "application/json")
-	w.Header().Set("Access-Control-Allow-Methods", "POST, OPTIONS")
-	w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
-
-	// Handle preflight requests
-	if r.Method == http.MethodOptions {
+	results, cachedData, filters, ok := h.runSearch(w, r)
+	if !ok {
 		return
 	}
 
-	// Only allow POST method
-	if r.Method != http.MethodPost {
-		h.sendError(w, "Method not allowed", http.StatusMethodNotAllowed)
-		return
+	// Send response
+	facets := service.ComputeFacets(cachedData, results)
+	response := models.SearchResult{
+		Artists:    results,
+		Total:      len(results),
+		Facets:     &facets,
+		Ungeocoded: h.search.Ungeocoded(cachedData, filters),
 	}
 
-	// Get and validate query parameter
-	query := strings.TrimSpace(r.URL.Query().Get("q"))
+	h.sendJSON(w, r, response)
+}
 
+// runSearch parses the query and FilterParams of a search request and
+// returns the matching artists with the dataset they came from and the
+// filters applied. On failure it has already written the error response.
+func (h *Handler) runSearch(w http.ResponseWriter, r *http.Request) ([]models.Artist, models.Datas, models.FilterParams, bool) {
 	// Get cached data
-	cachedData, err := h.cache.GetCachedData()
+	cachedData, err := h.cache.GetCachedData(r.Context())
 	if err != nil {
-		h.logger.Printf("Error getting cached data: %v", err)
+		h.logger.ErrorContext(r.Context(), "error getting cached data", "error", err)
 		h.sendError(w, "Failed to fetch data", http.StatusInternalServerError)
-		return
+		return nil, models.Datas{}, models.FilterParams{}, false
+	}
+
+	meta, err := h.filter.Meta(r.Context())
+	if err != nil {
+		h.logger.ErrorContext(r.Context(), "error getting filter metadata", "error", err)
+		h.sendError(w, "Failed to fetch data", http.StatusInternalServerError)
+		return nil, models.Datas{}, models.FilterParams{}, false
 	}
 
+	query, filters, err := h.searchParams(r, meta)
+	if err != nil {
+		h.logger.DebugContext(r.Context(), "invalid filter parameters", "error", err)
+		h.sendError(w, "Invalid filter parameters: "+err.Error(), http.StatusBadRequest)
+		return nil, models.Datas{}, models.FilterParams{}, false
+	}
+
+	// Search for artists
+	results, err := h.search.SearchArtists(r.Context(), query, filters)
+	if err != nil {
+		h.logger.ErrorContext(r.Context(), "error searching artists", "error", err)
+		h.sendError(w, "Failed to search artists", http.StatusInternalServerError)
+		return nil, models.Datas{}, models.FilterParams{}, false
+	}
+
+	return results, cachedData, filters, true
+}
+
+// searchParams reads the ?q= query and the FilterParams of a request,
+// validated against the filter metadata. FilterParams are read from a JSON
+// body when present, otherwise from the URL query.
+func (h *Handler) searchParams(r *http.Request, meta models.FilterMeta) (string, models.FilterParams, error) {
+	query := strings.TrimSpace(r.URL.Query().Get("q"))
+
 	// Initialize filter parameters with data-driven defaults
-	filters := h.getDefaultFilters(cachedData)
+	filters := service.DefaultFilters(meta)
 
 	// Parse filter parameters from request body if present
 	if r.Body != nil && r.ContentLength > 0 {
 		if err := json.NewDecoder(r.Body).Decode(&filters); err != nil {
-			h.logger.Printf("Warning: Error decoding filter parameters: %v", err)
+			h.logger.WarnContext(r.Context(), "error decoding filter parameters", "error", err)
 			// Continue with default filters
 		}
+	} else if err := parseFilterQuery(r.URL.Query(), &filters); err != nil {
+		return "", models.FilterParams{}, err
 	}
 
 	// Validate and normalize filter parameters
-	if err := h.validateFilters(&filters, cachedData); err != nil {
-		h.logger.Printf("Invalid filter parameters: %v", err)
-		h.sendError(w, "Invalid filter parameters: "+err.Error(), http.StatusBadRequest)
-		return
+	if err := h.validateFilters(&filters, meta); err != nil {
+		return "", models.FilterParams{}, err
 	}
+	return query, filters, nil
+}
 
-	// Search for artists
-	results, err := h.search.SearchArtists(query, filters)
-	if err != nil {
-		h.logger.Printf("Error searching artists: %v", err)
-		h.sendError(w, "Failed to search artists", http.StatusInternalServerError)
-		return
+// parseFilterQuery overrides filters with the FilterParams present in a URL
+// query, using the JSON field names. List parameters may be repeated or
+// comma-separated.
+func parseFilterQuery(values url.Values, filters *models.FilterParams) error {
+	ints := []struct {
+		name   string
+		target *int
+	}{
+		{"creationYearMin", &filters.CreationYearMin},
+		{"creationYearMax", &filters.CreationYearMax},
+		{"firstAlbumYearMin", &filters.FirstAlbumYearMin},
+		{"firstAlbumYearMax", &filters.FirstAlbumYearMax},
+		{"membersMin", &filters.MembersMin},
+		{"membersMax", &filters.MembersMax},
+	}
+	for _, param := range ints {
+		raw := values.Get(param.name)
+		if raw == "" {
+			continue
+		}
+		v, err := strconv.Atoi(raw)
+		if err != nil {
+			return fmt.Errorf("%s must be a number, got %q", param.name, raw)
+		}
+		*param.target = v
 	}
 
-	// Send response
-	response := models.SearchResult{
-		Artists: results,
-		Total:   len(results),
+	// Range shorthands such as creationYear=1990-1999, 1990- or -1999
+	ranges := []struct {
+		name     string
+		min, max *int
+	}{
+		{"creationYear", &filters.CreationYearMin, &filters.CreationYearMax},
+		{"firstAlbumYear", &filters.FirstAlbumYearMin, &filters.FirstAlbumYearMax},
+		{"membersRange", &filters.MembersMin, &filters.MembersMax},
+	}
+	for _, param := range ranges {
+		raw := values.Get(param.name)
+		if raw == "" {
+			continue
+		}
+		min, max, err := parseRange(raw)
+		if err != nil {
+			return fmt.Errorf("%s: %w", param.name, err)
+		}
+		*param.min, *param.max = min, max
 	}
 
-	if err := json.NewEncoder(w).Encode(response); err != nil {
-		h.logger.Printf("Error encoding search results: %v", err)
-		h.sendError(w, "Failed to encode response", http.StatusInternalServerError)
-		return
+	for _, param := range []struct {
+		name   string
+		target *[]int
+	}{
+		{"members", &filters.Members},
+		{"excludeMembers", &filters.ExcludeMembers},
+	} {
+		for _, raw := range queryList(values, param.name) {
+			count, err := strconv.Atoi(raw)
+			if err != nil {
+				return fmt.Errorf("%s must be numbers, got %q", param.name, raw)
+			}
+			*param.target = append(*param.target, count)
+		}
 	}
-}
 
-// getDefaultFilters returns data-driven default filter values
-func (h *Handler) getDefaultFilters(data models.Datas) models.FilterParams {
-	currentYear := time.Now().Year()
+	filters.Locations = append(filters.Locations, queryList(values, "locations")...)
+	filters.Countries = append(filters.Countries, queryList(values, "countries")...)
+	filters.Continents = append(filters.Continents, queryList(values, "continents")...)
+	filters.ExcludeLocations = append(filters.ExcludeLocations, queryList(values, "excludeLocations")...)
+	filters.ExcludeCountries = append(filters.ExcludeCountries, queryList(values, "excludeCountries")...)
+	if match := values.Get("locationMatch"); match != "" {
+		filters.LocationMatch = strings.ToLower(match)
+	}
 
-	// Find the earliest creation year from actual data
-	minCreationYear := currentYear
-	for _, artist := range data.ArtistsData {
-		if artist.CreationDate < minCreationYear {
-			minCreationYear = artist.CreationDate
+	if raw := values.Get("near"); raw != "" {
+		near, err := models.ParseGeoPoint(raw)
+		if err != nil {
+			return fmt.Errorf("near: %w", err)
 		}
+		filters.Near = &near
 	}
-
-	// Find the earliest first album year
-	minAlbumYear := currentYear
-	for _, artist := range data.ArtistsData {
-		if year, err := parseFirstAlbumYear(artist.FirstAlbum); err == nil && year < minAlbumYear {
-			minAlbumYear = year
+	if raw := values.Get("radiusKm"); raw != "" {
+		radius, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return fmt.Errorf("radiusKm must be a number, got %q", raw)
 		}
+		filters.RadiusKm = radius
 	}
-
-	return models.FilterParams{
-		CreationYearMin:   minCreationYear,
-		CreationYearMax:   currentYear,
-		FirstAlbumYearMin: minAlbumYear,
-		FirstAlbumYearMax: currentYear,
+	if raw := values.Get("bbox"); raw != "" {
+		bbox, err := models.ParseBBox(raw)
+		if err != nil {
+			return fmt.Errorf("bbox: %w", err)
+		}
+		filters.BBox = &bbox
 	}
+	return nil
 }
 
-// validateFilters validates and normalizes filter parameters
-func (h *Handler) validateFilters(filters *models.FilterParams, data models.Datas) error {
+// compactFilters drops the values of validated filters that match the
+// defaults, leaving zero values wherever a bound or list is open
+func compactFilters(filters, defaults models.FilterParams) models.FilterParams {
 	currentYear := time.Now().Year()
-
-	// Validate year ranges
-	defaults := h.getDefaultFilters(data)
-
-	if filters.CreationYearMin < defaults.CreationYearMin {
-		filters.CreationYearMin = defaults.CreationYearMin
+	if filters.CreationYearMin <= defaults.CreationYearMin {
+		filters.CreationYearMin = 0
+	}
+	if filters.CreationYearMax >= currentYear {
+		filters.CreationYearMax = 0
+	}
+	if filters.FirstAlbumYearMin <= defaults.FirstAlbumYearMin {
+		filters.FirstAlbumYearMin = 0
 	}
-	if filters.CreationYearMax > currentYear {
-		filters.CreationYearMax = currentYear
+	if filters.FirstAlbumYearMax >= currentYear {
+		filters.FirstAlbumYearMax = 0
 	}
-	if filters.CreationYearMin > filters.CreationYearMax {
-		return fmt.Errorf("invalid creation year range: min (%d) > max (%d)",
-			filters.CreationYearMin, filters.CreationYearMax)
+	if filters.Near == nil || filters.RadiusKm == service.DefaultRadiusKm {
+		filters.RadiusKm = 0
 	}
+	if filters.LocationMatch == models.MatchAny {
+		filters.LocationMatch = ""
+	}
+	return filters
+}
 
-	if filters.FirstAlbumYearMin < defaults.FirstAlbumYearMin {
-		filters.FirstAlbumYearMin = defaults.FirstAlbumYearMin
+// encodeFilterQuery is the inverse of parseFilterQuery for compacted
+// filters: it only sets parameters for non-zero values
+func encodeFilterQuery(query string, filters models.FilterParams) url.Values {
+	values := url.Values{}
+	if query != "" {
+		values.Set("q", query)
 	}
-	if filters.FirstAlbumYearMax > currentYear {
-		filters.FirstAlbumYearMax = currentYear
+
+	ints := []struct {
+		name  string
+		value int
+	}{
+		{"creationYearMin", filters.CreationYearMin},
+		{"creationYearMax", filters.CreationYearMax},
+		{"firstAlbumYearMin", filters.FirstAlbumYearMin},
+		{"firstAlbumYearMax", filters.FirstAlbumYearMax},
+		{"membersMin", filters.MembersMin},
+		{"membersMax", filters.MembersMax},
 	}
-	if filters.FirstAlbumYearMin > filters.FirstAlbumYearMax {
-		return fmt.Errorf("invalid first album year range: min (%d) > max (%d)",
-			filters.FirstAlbumYearMin, filters.FirstAlbumYearMax)
+	for _, param := range ints {
+		if param.value != 0 {
+			values.Set(param.name, strconv.Itoa(param.value))
+		}
 	}
 
-	// Validate member counts
-	for _, count := range filters.Members {
-		if count < 1 {
-			return fmt.Errorf("invalid member count: %d (must be positive)", count)
+	lists := []struct {
+		name  string
+		items []string
+	}{
+		{"members", joinInts(filters.Members)},
+		{"excludeMembers", joinInts(filters.ExcludeMembers)},
+		{"locations", filters.Locations},
+		{"excludeLocations", filters.ExcludeLocations},
+		{"countries", filters.Countries},
+		{"excludeCountries", filters.ExcludeCountries},
+		{"continents", filters.Continents},
+	}
+	for _, param := range lists {
+		if len(param.items) > 0 {
+			values.Set(param.name, strings.Join(param.items, ","))
 		}
 	}
 
-	// Validate locations (trim and normalize)
-	for i, loc := range filters.Locations {
-		filters.Locations[i] = strings.TrimSpace(loc)
-		if filters.Locations[i] == "" {
-			return fmt.Errorf("empty location not allowed")
+	if filters.LocationMatch != "" {
+		values.Set("locationMatch", filters.LocationMatch)
+	}
+	if filters.Near != nil {
+		values.Set("near", formatFloats(filters.Near.Lat, filters.Near.Lon))
+		if filters.RadiusKm != 0 {
+			values.Set("radiusKm", formatFloats(filters.RadiusKm))
 		}
 	}
+	if filters.BBox != nil {
+		values.Set("bbox", formatFloats(filters.BBox[:]...))
+	}
+	return values
+}
 
-	return nil
+// joinInts formats integers for a list parameter
+func joinInts(ints []int) []string {
+	items := make([]string, len(ints))
+	for i, v := range ints {
+		items[i] = strconv.Itoa(v)
+	}
+	return items
+}
+
+// formatFloats formats numbers as a comma-separated parameter value
+func formatFloats(floats ...float64) string {
+	items := make([]string, len(floats))
+	for i, v := range floats {
+		items[i] = strconv.FormatFloat(v, 'f', -1, 64)
+	}
+	return strings.Join(items, ",")
+}
+
+// parseRange parses "min-max" where either side may be empty for an open
+// bound, returned as 0
+func parseRange(raw string) (int, int, error) {
+	lo, hi, ok := strings.Cut(raw, "-")
+	if !ok {
+		return 0, 0, fmt.Errorf("want min-max, got %q", raw)
+	}
+	var bounds [2]int
+	for i, part := range []string{lo, hi} {
+		if part = strings.TrimSpace(part); part == "" {
+			continue
+		}
+		v, err := strconv.Atoi(part)
+		if err != nil || v < 0 {
+			return 0, 0, fmt.Errorf("invalid bound %q", part)
+		}
+		bounds[i] = v
+	}
+	return bounds[0], bounds[1], nil
 }
 
-// Helper function to parse first album year
-func parseFirstAlbumYear(firstAlbum string) (int, error) {
-	parts := strings.Split(firstAlbum, "-")
-	if len(parts) != 3 {
-		return 0, fmt.Errorf("invalid first album date format")
+// queryList returns the values of a repeated or comma-separated parameter
+func queryList(values url.Values, name string) []string {
+	var items []string
+	for _, value := range values[name] {
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
 	}
-	return strconv.Atoi(parts[2])
+	return items
 }
 
-// sendError sends a JSON error response
-func (h *Handler) sendError(w http.ResponseWriter, message string, code int) {
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(code)
+// validateFilters validates and normalizes filter parameters
+func (h *Handler) validateFilters(filters *models.FilterParams, meta models.FilterMeta) error {
+	if err := service.ValidateFilters(filters, meta); err != nil {
+		return err
+	}
 
-	err := models.Error{
-		Code:    code,
-		Message: message,
+	// Normalize countries and continents to their codes
+	for _, list := range [][]string{filters.Countries, filters.ExcludeCountries} {
+		for i, name := range list {
+			country, ok := geo.ParseCountry(name)
+			if !ok {
+				return fmt.Errorf("unknown country %q", name)
+			}
+			list[i] = country.Code
+		}
+	}
+	for i, name := range filters.Continents {
+		code, ok := geo.ParseContinent(name)
+		if !ok {
+			return fmt.Errorf("unknown continent %q", name)
+		}
+		filters.Continents[i] = code
 	}
 
-	if encodeErr := json.NewEncoder(w).Encode(err); encodeErr != nil {
-		h.logger.Printf("Error encoding error response: %v", encodeErr)
+	// Validate locations (trim and normalize)
+	for _, list := range [][]string{filters.Locations, filters.ExcludeLocations} {
+		for i, loc := range list {
+			list[i] = strings.TrimSpace(loc)
+			if list[i] == "" {
+				return fmt.Errorf("empty location not allowed")
+			}
+		}
 	}
+
+	return nil
 }
@@ -0,0 +1,327 @@
+// internal/handlers/v1.go
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"groupie-tracker/internal/models"
+	"groupie-tracker/internal/openapi"
+	"groupie-tracker/internal/service"
+)
+
+// Pagination defaults for v1 list endpoints
+const (
+	defaultPageLimit = 20
+	maxPageLimit     = 100
+)
+
+// HandleV1Artists serves GET /api/v1/artists
+func (h *Handler) HandleV1Artists(w http.ResponseWriter, r *http.Request) {
+	listResources(h, w, r, h.catalog.ListArtists)
+}
+
+// HandleV1Artist serves GET /api/v1/artists/{id}
+func (h *Handler) HandleV1Artist(w http.ResponseWriter, r *http.Request) {
+	getResource(h, w, r, h.catalog.GetArtist)
+}
+
+// HandleV1Locations serves GET /api/v1/locations
+func (h *Handler) HandleV1Locations(w http.ResponseWriter, r *http.Request) {
+	listResources(h, w, r, h.catalog.ListLocations)
+}
+
+// HandleV1Location serves GET /api/v1/locations/{id}
+func (h *Handler) HandleV1Location(w http.ResponseWriter, r *http.Request) {
+	getResource(h, w, r, h.catalog.GetLocation)
+}
+
+// HandleV1Dates serves GET /api/v1/dates
+func (h *Handler) HandleV1Dates(w http.ResponseWriter, r *http.Request) {
+	listResources(h, w, r, h.catalog.ListDates)
+}
+
+// HandleV1Date serves GET /api/v1/dates/{id}
+func (h *Handler) HandleV1Date(w http.ResponseWriter, r *http.Request) {
+	getResource(h, w, r, h.catalog.GetDate)
+}
+
+// HandleV1Relations serves GET /api/v1/relations
+func (h *Handler) HandleV1Relations(w http.ResponseWriter, r *http.Request) {
+	listResources(h, w, r, h.catalog.ListRelations)
+}
+
+// HandleV1Relation serves GET /api/v1/relations/{id}
+func (h *Handler) HandleV1Relation(w http.ResponseWriter, r *http.Request) {
+	getResource(h, w, r, h.catalog.GetRelation)
+}
+
+var (
+	openAPIOnce sync.Once
+	openAPIDoc  []byte
+)
+
+// HandleV1OpenAPI serves the generated OpenAPI document at GET /api/v1/openapi.json
+func (h *Handler) HandleV1OpenAPI(w http.ResponseWriter, r *http.Request) {
+	openAPIOnce.Do(func() {
+		doc := openapi.Build(openapi.Info{
+			Title:       "Groupie Tracker API",
+			Version:     "1.0.0",
+			Description: "Artists, concert locations, dates and relations from the Groupie Trackers dataset.",
+		}, V1Operations())
+		openAPIDoc, _ = json.MarshalIndent(doc, "", "  ")
+	})
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(openAPIDoc)
+}
+
+// V1Operations documents the v1 routes for the OpenAPI document
+func V1Operations() []openapi.Operation {
+	list := []openapi.Param{
+		{Name: "page", In: "query", Type: "integer", Description: "Page number, starting at 1"},
+		{Name: "limit", In: "query", Type: "integer", Description: "Items per page (max " + strconv.Itoa(maxPageLimit) + ")"},
+		{Name: "fields", In: "query", Description: "Comma-separated list of fields to include, e.g. name,members"},
+	}
+	get := []openapi.Param{
+		{Name: "id", In: "path", Type: "integer", Description: "Artist ID"},
+		{Name: "fields", In: "query", Description: "Comma-separated list of fields to include"},
+	}
+
+	resources := []struct {
+		path, tag string
+		sample    interface{}
+		samples   interface{}
+	}{
+		{"artists", "Artists", models.ArtistResource{}, []models.ArtistResource{}},
+		{"locations", "Locations", models.LocationResource{}, []models.LocationResource{}},
+		{"dates", "Dates", models.DateResource{}, []models.DateResource{}},
+		{"relations", "Relations", models.RelationResource{}, []models.RelationResource{}},
+	}
+
+	var ops []openapi.Operation
+	for _, res := range resources {
+		ops = append(ops,
+			openapi.Operation{
+				Method: http.MethodGet, Path: "/api/v1/" + res.path,
+				Summary: "List " + res.path, Tags: []string{res.tag},
+				Params: list, Response: res.samples, Envelope: models.Envelope{},
+			},
+			openapi.Operation{
+				Method: http.MethodGet, Path: "/api/v1/" + res.path + "/{id}",
+				Summary: "Get " + res.path + " by artist ID", Tags: []string{res.tag},
+				Params: get, Response: res.sample, Envelope: models.Envelope{},
+			},
+		)
+	}
+	return ops
+}
+
+// listResources writes a paginated, field-filtered list envelope
+func listResources[T any](h *Handler, w http.ResponseWriter, r *http.Request, list func(context.Context) ([]T, error)) {
+	query := r.URL.Query()
+
+	page, err := positiveInt(query.Get("page"), 1)
+	if err != nil {
+		h.sendV1Error(w, "Invalid page: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	limit, err := positiveInt(query.Get("limit"), defaultPageLimit)
+	if err != nil {
+		h.sendV1Error(w, "Invalid limit: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	if limit > maxPageLimit {
+		limit = maxPageLimit
+	}
+
+	fields, err := parseFields[T](query.Get("fields"))
+	if err != nil {
+		h.sendV1Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	items, err := list(r.Context())
+	if err != nil {
+		h.logger.ErrorContext(r.Context(), "error listing resources", "error", err)
+		h.sendV1Error(w, "Failed to fetch data", http.StatusInternalServerError)
+		return
+	}
+
+	// Pages past the end are empty; comparing before multiplying keeps
+	// huge page numbers from overflowing
+	total := len(items)
+	start := total
+	if page-1 <= total/limit {
+		start = min((page-1)*limit, total)
+	}
+	end := min(start+limit, total)
+
+	data, err := selectFields(items[start:end], fields)
+	if err != nil {
+		h.logger.ErrorContext(r.Context(), "error selecting fields", "error", err)
+		h.sendV1Error(w, "Failed to encode response", http.StatusInternalServerError)
+		return
+	}
+
+	h.sendJSON(w, r, models.Envelope{
+		Data: data,
+		Meta: &models.Meta{Total: total, Page: page, Limit: limit},
+	})
+}
+
+// getResource writes a single field-filtered resource envelope
+func getResource[T any](h *Handler, w http.ResponseWriter, r *http.Request, get func(context.Context, int) (T, error)) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil || id < 1 {
+		h.sendV1Error(w, "Invalid ID", http.StatusBadRequest)
+		return
+	}
+
+	fields, err := parseFields[T](r.URL.Query().Get("fields"))
+	if err != nil {
+		h.sendV1Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	item, err := get(r.Context(), id)
+	if errors.Is(err, service.ErrNotFound) {
+		h.sendV1Error(w, "Resource not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		h.logger.ErrorContext(r.Context(), "error getting resource", "error", err)
+		h.sendV1Error(w, "Failed to fetch data", http.StatusInternalServerError)
+		return
+	}
+
+	data, err := selectFields(item, fields)
+	if err != nil {
+		h.logger.ErrorContext(r.Context(), "error selecting fields", "error", err)
+		h.sendV1Error(w, "Failed to encode response", http.StatusInternalServerError)
+		return
+	}
+
+	h.sendJSON(w, r, models.Envelope{Data: data})
+}
+
+// sendV1Error sends an error wrapped in the v1 envelope
+func (h *Handler) sendV1Error(w http.ResponseWriter, message string, code int) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+
+	envelope := models.Envelope{Error: &models.Error{Code: code, Message: message}}
+	if err := json.NewEncoder(w).Encode(envelope); err != nil {
+		h.logger.Error("error encoding error response", "error", err)
+	}
+}
+
+// parseFields validates a fields=a,b list against the JSON fields of T
+func parseFields[T any](raw string) ([]string, error) {
+	if strings.TrimSpace(raw) == "" {
+		return nil, nil
+	}
+
+	var zero T
+	allowed := jsonFieldNames(reflect.TypeOf(zero))
+
+	var fields []string
+	for _, f := range strings.Split(raw, ",") {
+		f = strings.TrimSpace(f)
+		if f == "" {
+			continue
+		}
+		if !allowed[f] {
+			names := make([]string, 0, len(allowed))
+			for name := range allowed {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			return nil, errors.New("unknown field " + strconv.Quote(f) + ", allowed: " + strings.Join(names, ","))
+		}
+		fields = append(fields, f)
+	}
+	return fields, nil
+}
+
+// selectFields keeps only the given JSON fields (plus id) of v, or of each
+// element when v is a slice. With no fields v is returned unchanged.
+func selectFields(v interface{}, fields []string) (interface{}, error) {
+	if len(fields) == 0 {
+		return v, nil
+	}
+
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	keep := map[string]bool{"id": true}
+	for _, f := range fields {
+		keep[f] = true
+	}
+	filter := func(obj map[string]json.RawMessage) map[string]json.RawMessage {
+		for k := range obj {
+			if !keep[k] {
+				delete(obj, k)
+			}
+		}
+		return obj
+	}
+
+	if reflect.TypeOf(v).Kind() == reflect.Slice {
+		var items []map[string]json.RawMessage
+		if err := json.Unmarshal(raw, &items); err != nil {
+			return nil, err
+		}
+		for i := range items {
+			items[i] = filter(items[i])
+		}
+		return items, nil
+	}
+
+	var obj map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &obj); err != nil {
+		return nil, err
+	}
+	return filter(obj), nil
+}
+
+// jsonFieldNames returns the top-level JSON property names of a struct type
+func jsonFieldNames(t reflect.Type) map[string]bool {
+	names := map[string]bool{}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		names[name] = true
+	}
+	return names
+}
+
+// positiveInt parses an optional positive integer query value
+func positiveInt(raw string, fallback int) (int, error) {
+	if raw == "" {
+		return fallback, nil
+	}
+	n, err := strconv.Atoi(raw)
+	if err != nil || n < 1 {
+		return 0, errors.New("must be a positive integer")
+	}
+	return n, nil
+}
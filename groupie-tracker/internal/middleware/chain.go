@@ -0,0 +1,16 @@
+// internal/middleware/chain.go
+package middleware
+
+import "net/http"
+
+// Middleware wraps an http.Handler with additional behaviour
+type Middleware func(http.Handler) http.Handler
+
+// Chain wraps h with the given middlewares. The first middleware is the
+// outermost one and sees the request first.
+func Chain(h http.Handler, middlewares ...Middleware) http.Handler {
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		h = middlewares[i](h)
+	}
+	return h
+}
@@ -0,0 +1,133 @@
+// internal/middleware/logger.go
+package middleware
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// routeKey is the context key of the route pattern recorded by SetRoute
+type routeKey struct{}
+
+// secretWildcards names the path wildcards whose values are credentials,
+// such as the token of a private calendar feed
+var secretWildcards = map[string]bool{"token": true}
+
+// redacted replaces secret path segments in logs
+const redacted = "REDACTED"
+
+// SetRoute records the route pattern that matched r, such as
+// GET /calendar/{token}/concerts.ics, for Logger to log next to the path
+// and to find the path segments it must redact
+func SetRoute(r *http.Request, pattern string) {
+	if route, ok := r.Context().Value(routeKey{}).(*string); ok {
+		*route = pattern
+	}
+}
+
+// redactPath replaces the segments of path matched by secret wildcards of
+// pattern
+func redactPath(pattern, path string) string {
+	// Drop the method and host of the pattern
+	if i := strings.IndexByte(pattern, '/'); i >= 0 {
+		pattern = pattern[i:]
+	}
+	segments := strings.Split(path, "/")
+	for i, wildcard := range strings.Split(pattern, "/") {
+		if i >= len(segments) {
+			break
+		}
+		if !strings.HasPrefix(wildcard, "{") || !strings.HasSuffix(wildcard, "}") {
+			continue
+		}
+		name, rest := strings.CutSuffix(wildcard[1:len(wildcard)-1], "...")
+		if !secretWildcards[name] {
+			continue
+		}
+		segments[i] = redacted
+		if rest {
+			// The wildcard matches the remainder of the path
+			segments = segments[:i+1]
+			break
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+// responseRecorder captures the status code and body size of a response
+type responseRecorder struct {
+	http.ResponseWriter
+	status int
+	bytes  int
+}
+
+func (r *responseRecorder) WriteHeader(code int) {
+	if r.status == 0 {
+		r.status = code
+	}
+	r.ResponseWriter.WriteHeader(code)
+}
+
+func (r *responseRecorder) Write(b []byte) (int, error) {
+	if r.status == 0 {
+		r.status = http.StatusOK
+	}
+	n, err := r.ResponseWriter.Write(b)
+	r.bytes += n
+	return n, err
+}
+
+// Unwrap exposes the underlying writer to http.ResponseController
+func (r *responseRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
+
+// Logger logs one structured record per request with its method, path,
+// route pattern when one matched, status, response size and duration.
+// Secret path segments, such as calendar tokens, are redacted. It must run
+// inside RequestID so the record carries the request ID.
+func Logger(logger *slog.Logger) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			rec := &responseRecorder{ResponseWriter: w}
+			route := new(string)
+
+			next.ServeHTTP(rec, r.WithContext(context.WithValue(r.Context(), routeKey{}, route)))
+
+			status := rec.status
+			if status == 0 {
+				status = http.StatusOK
+			}
+
+			level := slog.LevelInfo
+			switch {
+			case status >= 500:
+				level = slog.LevelError
+			case status >= 400:
+				level = slog.LevelWarn
+			}
+
+			attrs := []slog.Attr{slog.String("method", r.Method)}
+			if *route != "" {
+				attrs = append(attrs,
+					slog.String("path", redactPath(*route, r.URL.EscapedPath())),
+					slog.String("route", *route),
+				)
+			} else {
+				attrs = append(attrs, slog.String("path", r.URL.Path))
+			}
+			attrs = append(attrs,
+				slog.Int("status", status),
+				slog.Int("bytes", rec.bytes),
+				slog.Duration("duration", time.Since(start)),
+				slog.String("remote_addr", r.RemoteAddr),
+			)
+
+			logger.LogAttrs(r.Context(), level, "request completed", attrs...)
+		})
+	}
+}
@@ -0,0 +1,21 @@
+// internal/middleware/logger_test.go
+package middleware
+
+import "testing"
+
+func TestRedactPath(t *testing.T) {
+	tests := []struct {
+		pattern, path, want string
+	}{
+		{"GET /calendar/{token}/concerts.ics", "/calendar/abc123/concerts.ics", "/calendar/REDACTED/concerts.ics"},
+		{"GET /artist/{id}", "/artist/7", "/artist/7"},
+		{"GET /api/v1/artists", "/api/v1/artists", "/api/v1/artists"},
+		{"GET /static/", "/static/app.js", "/static/app.js"},
+		{"GET example.com/calendar/{token...}", "/calendar/a/b", "/calendar/REDACTED"},
+	}
+	for _, tt := range tests {
+		if got := redactPath(tt.pattern, tt.path); got != tt.want {
+			t.Errorf("redactPath(%q, %q) = %q, want %q", tt.pattern, tt.path, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,161 @@
+// internal/middleware/ratelimit_test.go
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// fakeClock is a settable time source for limiters
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time { return c.now }
+
+func newTestLimiter(limit Limit) (*RateLimiter, *fakeClock) {
+	clock := &fakeClock{now: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)}
+	limiter := NewRateLimiter(limit)
+	limiter.now = clock.Now
+	return limiter, clock
+}
+
+func TestRateLimiterBurstAndRefill(t *testing.T) {
+	limiter, clock := newTestLimiter(Limit{Rate: 2, Burst: 3})
+
+	for i := 0; i < 3; i++ {
+		if ok, _ := limiter.Allow("a"); !ok {
+			t.Fatalf("request %d within the burst was rejected", i+1)
+		}
+	}
+	ok, wait := limiter.Allow("a")
+	if ok {
+		t.Fatal("request past the burst was allowed")
+	}
+	if wait != 500*time.Millisecond {
+		t.Errorf("wait = %v, want 500ms at 2 tokens per second", wait)
+	}
+
+	// Other keys have their own bucket
+	if ok, _ := limiter.Allow("b"); !ok {
+		t.Error("another key was rejected")
+	}
+
+	clock.now = clock.now.Add(500 * time.Millisecond)
+	if ok, _ := limiter.Allow("a"); !ok {
+		t.Error("request after a refill was rejected")
+	}
+	if ok, _ := limiter.Allow("a"); ok {
+		t.Error("the refill granted more than one token")
+	}
+
+	// Refills never exceed the burst
+	clock.now = clock.now.Add(time.Hour)
+	for i := 0; i < 3; i++ {
+		if ok, _ := limiter.Allow("a"); !ok {
+			t.Fatalf("request %d after a long pause was rejected", i+1)
+		}
+	}
+	if ok, _ := limiter.Allow("a"); ok {
+		t.Error("bucket refilled past its burst")
+	}
+}
+
+func TestRateLimiterDisabled(t *testing.T) {
+	limiter, _ := newTestLimiter(Limit{Rate: 0, Burst: 0})
+	for i := 0; i < 100; i++ {
+		if ok, _ := limiter.Allow("a"); !ok {
+			t.Fatalf("request %d rejected with rate limiting disabled", i+1)
+		}
+	}
+}
+
+func TestRateLimiterZeroBurst(t *testing.T) {
+	limiter, clock := newTestLimiter(Limit{Rate: 1, Burst: 0})
+	if ok, _ := limiter.Allow("a"); !ok {
+		t.Fatal("first request rejected with a zero burst")
+	}
+	if ok, _ := limiter.Allow("a"); ok {
+		t.Error("second request allowed before a refill")
+	}
+	clock.now = clock.now.Add(time.Second)
+	if ok, _ := limiter.Allow("a"); !ok {
+		t.Error("request after a refill rejected with a zero burst")
+	}
+}
+
+func TestRateLimiterSweep(t *testing.T) {
+	limiter, clock := newTestLimiter(Limit{Rate: 1, Burst: 1})
+	limiter.Allow("idle")
+
+	clock.now = clock.now.Add(bucketIdleTTL + time.Second)
+	limiter.Allow("active")
+
+	if _, ok := limiter.buckets["idle"]; ok {
+		t.Error("idle bucket was not evicted")
+	}
+	if _, ok := limiter.buckets["active"]; !ok {
+		t.Error("active bucket was evicted")
+	}
+}
+
+func TestRateLimit(t *testing.T) {
+	limiter, _ := newTestLimiter(Limit{Rate: 0.5, Burst: 1})
+	handler := RateLimit(limiter, false)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	request := func(remoteAddr string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodGet, "/api/artists", nil)
+		r.RemoteAddr = remoteAddr
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+		return w
+	}
+
+	if w := request("192.0.2.1:1234"); w.Code != http.StatusNoContent {
+		t.Fatalf("first request: status %d, want %d", w.Code, http.StatusNoContent)
+	}
+	w := request("192.0.2.1:5678")
+	if w.Code != http.StatusTooManyRequests {
+		t.Fatalf("second request: status %d, want %d", w.Code, http.StatusTooManyRequests)
+	}
+	if got := w.Header().Get("Retry-After"); got != "2" {
+		t.Errorf("Retry-After = %q, want 2", got)
+	}
+	if w := request("192.0.2.2:1234"); w.Code != http.StatusNoContent {
+		t.Errorf("another client: status %d, want %d", w.Code, http.StatusNoContent)
+	}
+}
+
+func TestClientIP(t *testing.T) {
+	tests := []struct {
+		name       string
+		remoteAddr string
+		forwarded  string
+		trustProxy bool
+		want       string
+	}{
+		{"remote address", "192.0.2.1:1234", "", false, "192.0.2.1"},
+		{"ipv6 remote address", "[2001:db8::1]:1234", "", false, "2001:db8::1"},
+		{"no port", "192.0.2.1", "", false, "192.0.2.1"},
+		{"forwarded but untrusted", "192.0.2.1:1234", "198.51.100.7", false, "192.0.2.1"},
+		{"forwarded and trusted", "192.0.2.1:1234", "198.51.100.7, 192.0.2.1", true, "198.51.100.7"},
+		{"trusted without header", "192.0.2.1:1234", "", true, "192.0.2.1"},
+		{"trusted with empty entry", "192.0.2.1:1234", " , 198.51.100.7", true, "192.0.2.1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			r.RemoteAddr = tt.remoteAddr
+			if tt.forwarded != "" {
+				r.Header.Set("X-Forwarded-For", tt.forwarded)
+			}
+			if got := ClientIP(r, tt.trustProxy); got != tt.want {
+				t.Errorf("ClientIP = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,52 @@
+// internal/middleware/recovery.go
+package middleware
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"runtime/debug"
+
+	"groupie-tracker/internal/models"
+)
+
+// Recover turns panics in downstream handlers into a JSON 500 response
+// and logs the panic value with its stack trace.
+func Recover(logger *slog.Logger) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			defer func() {
+				rec := recover()
+				if rec == nil {
+					return
+				}
+				// Let the server abort the connection as intended
+				if rec == http.ErrAbortHandler {
+					panic(rec)
+				}
+
+				logger.ErrorContext(r.Context(), "panic serving request",
+					"panic", fmt.Sprint(rec),
+					"stack", string(debug.Stack()),
+				)
+
+				WriteError(w, "Internal Server Error", http.StatusInternalServerError)
+			}()
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// WriteError writes a models.Error JSON body with the given status code
+func WriteError(w http.ResponseWriter, message string, code int) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Del("Content-Length")
+	w.WriteHeader(code)
+
+	_ = json.NewEncoder(w).Encode(models.Error{
+		Code:    code,
+		Message: message,
+	})
+}
@@ -0,0 +1,22 @@
+// internal/models/announcement.go
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+// Announcement is a concert date that was not in the dataset before a
+// cache refresh
+type Announcement struct {
+	ArtistID int       `json:"artistId"`
+	Artist   string    `json:"artist"`
+	Location string    `json:"location"`
+	Date     string    `json:"date"`
+	FoundAt  time.Time `json:"foundAt"`
+}
+
+// Key identifies the concert independently of when it was announced
+func (a Announcement) Key() string {
+	return fmt.Sprintf("%d/%s/%s", a.ArtistID, a.Location, a.Date)
+}
@@ -0,0 +1,141 @@
+// internal/models/api.go
+package models
+
+import (
+	"strings"
+	"time"
+)
+
+// ArtistResource is an artist as exposed by the v1 API, enriched with
+// derived fields and links to its related v1 resources
+type ArtistResource struct {
+	ID             int      `json:"id"`
+	Name           string   `json:"name"`
+	Image          string   `json:"image"`
+	Members        []string `json:"members"`
+	MemberCount    int      `json:"memberCount"`
+	CreationDate   int      `json:"creationDate"`
+	FirstAlbum     string   `json:"firstAlbum"`
+	FirstAlbumYear int      `json:"firstAlbumYear,omitempty"`
+	LocationCount  int      `json:"locationCount"`
+	ConcertCount   int      `json:"concertCount"`
+	Links          Links    `json:"links"`
+}
+
+// Links points to the related v1 resources of an artist
+type Links struct {
+	Self      string `json:"self"`
+	Locations string `json:"locations"`
+	Dates     string `json:"dates"`
+	Relations string `json:"relations"`
+}
+
+// LocationResource lists the concert locations of an artist
+type LocationResource struct {
+	ID         int          `json:"id"`
+	ArtistName string       `json:"artistName"`
+	Locations  []NamedPlace `json:"locations"`
+}
+
+// NamedPlace pairs an upstream location slug with a display name
+type NamedPlace struct {
+	Slug string `json:"slug"`
+	Name string `json:"name"`
+}
+
+// DateResource lists the concert dates of an artist
+type DateResource struct {
+	ID         int         `json:"id"`
+	ArtistName string      `json:"artistName"`
+	Dates      []EventDate `json:"dates"`
+}
+
+// EventDate is a concert date in both its upstream and ISO 8601 forms
+type EventDate struct {
+	Raw  string `json:"raw"`
+	Date string `json:"date"`
+}
+
+// RelationResource pairs each concert location of an artist with its dates
+type RelationResource struct {
+	ID         int             `json:"id"`
+	ArtistName string          `json:"artistName"`
+	Concerts   []LocationDates `json:"concerts"`
+}
+
+// LocationDates lists the dates an artist played at a location
+type LocationDates struct {
+	Location NamedPlace `json:"location"`
+	Dates    []string   `json:"dates"`
+}
+
+// Envelope wraps every v1 API response
+type Envelope struct {
+	Data  interface{} `json:"data,omitempty"`
+	Meta  *Meta       `json:"meta,omitempty"`
+	Error *Error      `json:"error,omitempty"`
+}
+
+// Meta carries pagination details for list responses
+type Meta struct {
+	Total int `json:"total"`
+	Page  int `json:"page"`
+	Limit int `json:"limit"`
+}
+
+// concertDateLayout is the upstream day-month-year date format
+const concertDateLayout = "02-01-2006"
+
+// ParseConcertDate parses an upstream concert date such as "*23-08-2019".
+// The leading asterisk some entries carry is ignored.
+func ParseConcertDate(date string) (time.Time, error) {
+	return time.Parse(concertDateLayout, strings.TrimPrefix(strings.TrimSpace(date), "*"))
+}
+
+// NormalizeConcertDate returns an upstream concert date as YYYY-MM-DD, or
+// unchanged when it does not parse
+func NormalizeConcertDate(date string) string {
+	t, err := ParseConcertDate(date)
+	if err != nil {
+		return date
+	}
+	return t.Format("2006-01-02")
+}
+
+// FormatLocation turns an upstream location slug such as
+// "north_carolina-usa" into a display name like "North Carolina, USA"
+func FormatLocation(slug string) string {
+	parts := strings.Split(strings.TrimSpace(slug), "-")
+	for i, part := range parts {
+		words := strings.Fields(strings.ReplaceAll(part, "_", " "))
+		for j, word := range words {
+			// Short country codes such as usa or uk stay uppercase
+			if i == len(parts)-1 && i > 0 && len(word) <= 3 && len(words) == 1 {
+				words[j] = strings.ToUpper(word)
+				continue
+			}
+			words[j] = strings.ToUpper(word[:1]) + word[1:]
+		}
+		parts[i] = strings.Join(words, " ")
+	}
+	return strings.Join(parts, ", ")
+}
+
+// FilterMeta describes the ranges and choices available to search filters,
+// derived from the whole dataset
+type FilterMeta struct {
+	CreationYear   YearRange    `json:"creationYear"`
+	FirstAlbumYear YearRange    `json:"firstAlbumYear"`
+	MemberCounts   []int        `json:"memberCounts"`
+	Locations      []NamedPlace `json:"locations"`
+	// Countries and Continents count the artists who played there
+	Countries   []FacetCount `json:"countries"`
+	Continents  []FacetCount `json:"continents"`
+	GeneratedAt time.Time    `json:"generatedAt"`
+}
+
+// YearRange is an inclusive range of years
+type YearRange struct {
+	Min int `json:"min"`
+	Max int `json:"max"`
+}
@@ -0,0 +1,68 @@
+// internal/models/attendance.go
+package models
+
+import "time"
+
+// AttendanceLogVersion is the version of the attendance export format
+const AttendanceLogVersion = 1
+
+// Attendance is a concert a user attended, identified by artist, location
+// slug and YYYY-MM-DD date. Artist, Place and Country are derived from the
+// dataset when listed.
+type Attendance struct {
+	ArtistID int    `json:"artistId"`
+	Artist   string `json:"artist,omitempty"`
+	Location string `json:"location"`
+	Place    string `json:"place,omitempty"`
+	Country  string `json:"country,omitempty"`
+	Date     string `json:"date"`
+	// Rating is from 1 to 5 stars, 0 when unrated
+	Rating  int       `json:"rating,omitempty"`
+	Notes   string    `json:"notes,omitempty"`
+	AddedAt time.Time `json:"addedAt"`
+}
+
+// AttendanceNote is the body of a request marking a concert as attended
+type AttendanceNote struct {
+	Rating int    `json:"rating"`
+	Notes  string `json:"notes"`
+}
+
+// AttendanceLog is the JSON export and import format of a user's attended
+// concerts
+type AttendanceLog struct {
+	Version    int          `json:"version"`
+	ExportedAt time.Time    `json:"exportedAt"`
+	Attended   []Attendance `json:"attended"`
+}
+
+// AttendanceImport reports the outcome of an import; Skipped lists entries
+// matching no concert of the dataset
+type AttendanceImport struct {
+	Imported int          `json:"imported"`
+	Skipped  []Attendance `json:"skipped,omitempty"`
+}
+
+// AttendanceStats summarizes a user's attended concerts
+type AttendanceStats struct {
+	Shows     int           `json:"shows"`
+	Artists   []SeenArtist  `json:"artists"`
+	Countries []SeenCountry `json:"countries"`
+	FirstShow *Attendance   `json:"firstShow,omitempty"`
+	LastShow  *Attendance   `json:"lastShow,omitempty"`
+	// AverageRating only counts rated shows
+	AverageRating float64 `json:"averageRating,omitempty"`
+}
+
+// SeenArtist counts the attended concerts of an artist
+type SeenArtist struct {
+	ArtistID int    `json:"artistId"`
+	Name     string `json:"name"`
+	Shows    int    `json:"shows"`
+}
+
+// SeenCountry counts the attended concerts in a country
+type SeenCountry struct {
+	Country string `json:"country"`
+	Shows   int    `json:"shows"`
+}
@@ -0,0 +1,79 @@
+// internal/models/geo.go
+package models
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// GeoPoint is a position in degrees
+type GeoPoint struct {
+	Lat float64 `json:"lat"`
+	Lon float64 `json:"lon"`
+}
+
+// ParseGeoPoint parses "lat,lon"
+func ParseGeoPoint(s string) (GeoPoint, error) {
+	values, err := parseFloats(s, 2)
+	if err != nil {
+		return GeoPoint{}, fmt.Errorf("want lat,lon: %w", err)
+	}
+	p := GeoPoint{Lat: values[0], Lon: values[1]}
+	return p, p.Validate()
+}
+
+// Validate checks that the point lies within the coordinate ranges
+func (p GeoPoint) Validate() error {
+	if !(p.Lat >= -90 && p.Lat <= 90) {
+		return fmt.Errorf("latitude %g out of range", p.Lat)
+	}
+	if !(p.Lon >= -180 && p.Lon <= 180) {
+		return fmt.Errorf("longitude %g out of range", p.Lon)
+	}
+	return nil
+}
+
+// BBox is a bounding box in GeoJSON order: min lon, min lat, max lon, max
+// lat. A box whose min lon exceeds its max lon crosses the antimeridian.
+type BBox [4]float64
+
+// ParseBBox parses "minLon,minLat,maxLon,maxLat"
+func ParseBBox(s string) (BBox, error) {
+	values, err := parseFloats(s, 4)
+	if err != nil {
+		return BBox{}, fmt.Errorf("want minLon,minLat,maxLon,maxLat: %w", err)
+	}
+	b := BBox{values[0], values[1], values[2], values[3]}
+	return b, b.Validate()
+}
+
+// Validate checks the corners of the box
+func (b BBox) Validate() error {
+	if err := (GeoPoint{Lat: b[1], Lon: b[0]}).Validate(); err != nil {
+		return err
+	}
+	if err := (GeoPoint{Lat: b[3], Lon: b[2]}).Validate(); err != nil {
+		return err
+	}
+	if b[1] > b[3] {
+		return fmt.Errorf("min latitude %g exceeds max latitude %g", b[1], b[3])
+	}
+	return nil
+}
+
+func parseFloats(s string, n int) ([]float64, error) {
+	parts := strings.Split(s, ",")
+	if len(parts) != n {
+		return nil, fmt.Errorf("got %d values", len(parts))
+	}
+	values := make([]float64, n)
+	for i, part := range parts {
+		v, err := strconv.ParseFloat(strings.TrimSpace(part), 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number %q", part)
+		}
+		values[i] = v
+	}
+	return values, nil
+}
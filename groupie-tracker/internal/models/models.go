@@ -68,6 +68,27 @@ type SearchResult struct {
 	Artists []Artist `json:"artists"`
 	Total   int      `json:"total,omitempty"`
 	Page    int      `json:"page,omitempty"`
+	Facets  *Facets  `json:"facets,omitempty"`
+	// Ungeocoded counts the locations a near or bbox filter skipped, as
+	// they were never geocoded; the result is partial when it is set
+	Ungeocoded int `json:"ungeocoded,omitempty"`
+}
+
+// Facets counts the artists of a result set per filter value
+type Facets struct {
+	Members           []FacetCount `json:"members"`
+	Locations         []FacetCount `json:"locations"`
+	Countries         []FacetCount `json:"countries"`
+	Continents        []FacetCount `json:"continents"`
+	CreationDecades   []FacetCount `json:"creationDecades"`
+	FirstAlbumDecades []FacetCount `json:"firstAlbumDecades"`
+}
+
+// FacetCount is the number of artists matching one filter value
+type FacetCount struct {
+	Value string `json:"value"`
+	Label string `json:"label"`
+	Count int    `json:"count"`
 }
 
 // Suggestion represents a search suggestion
@@ -106,8 +127,33 @@ type FilterParams struct {
 	FirstAlbumYearMax int      `json:"firstAlbumYearMax"`
 	Members           []int    `json:"members"`
 	Locations         []string `json:"locations"`
+	// Near and RadiusKm keep artists who played within RadiusKm of Near
+	Near     *GeoPoint `json:"near,omitempty"`
+	RadiusKm float64   `json:"radiusKm,omitempty"`
+	// BBox keeps artists who played inside the box
+	BBox *BBox `json:"bbox,omitempty"`
+	// Countries (ISO codes or names) and Continents (codes such as EU or
+	// names) keep artists who played in any of them
+	Countries  []string `json:"countries,omitempty"`
+	Continents []string `json:"continents,omitempty"`
+	// MembersMin and MembersMax bound the member count; 0 leaves a side open
+	MembersMin int `json:"membersMin,omitempty"`
+	MembersMax int `json:"membersMax,omitempty"`
+	// Exclude* drop artists with any of the listed values
+	ExcludeMembers   []int    `json:"excludeMembers,omitempty"`
+	ExcludeLocations []string `json:"excludeLocations,omitempty"`
+	ExcludeCountries []string `json:"excludeCountries,omitempty"`
+	// LocationMatch is "any" (default) to keep artists who played at one of
+	// Locations, or "all" to require every one of them
+	LocationMatch string `json:"locationMatch,omitempty"`
 }
 
+// Location list semantics
+const (
+	MatchAny = "any"
+	MatchAll = "all"
+)
+
 // Error represents an API error response
 type Error struct {
 	Code    int    `json:"code"`
@@ -0,0 +1,37 @@
+// internal/models/route.go
+package models
+
+// Route is an artist's tour reconstructed from the relations: concerts in
+// chronological order with the distances between them
+type Route struct {
+	ArtistID int         `json:"artistId"`
+	Stops    []RouteStop `json:"stops"`
+	Legs     []RouteLeg  `json:"legs"`
+	// TotalKm sums the leg distances
+	TotalKm   float64   `json:"totalKm"`
+	Countries []string  `json:"countries"`
+	Longest   *RouteLeg `json:"longestHop,omitempty"`
+	// Ungeocoded lists locations without coordinates; their stops are
+	// left out of the legs
+	Ungeocoded []string `json:"ungeocoded,omitempty"`
+}
+
+// RouteStop is one concert of a route
+type RouteStop struct {
+	Location string  `json:"location"`
+	Name     string  `json:"name"`
+	Country  string  `json:"country"`
+	Date     string  `json:"date"`
+	Lat      float64 `json:"lat,omitempty"`
+	Lon      float64 `json:"lon,omitempty"`
+	Geocoded bool    `json:"geocoded"`
+}
+
+// RouteLeg is the journey between two consecutive geocoded stops, indexing
+// into Route.Stops
+type RouteLeg struct {
+	From       int     `json:"from"`
+	To         int     `json:"to"`
+	DistanceKm float64 `json:"distanceKm"`
+	Days       int     `json:"days"`
+}
@@ -0,0 +1,16 @@
+// internal/models/saved.go
+package models
+
+import "time"
+
+// SavedSearch is a search query and its filters stored under a short ID.
+// Filters only hold the values that differ from the defaults, so open
+// bounds stay open as the dataset grows.
+type SavedSearch struct {
+	ID        string       `json:"id"`
+	Query     string       `json:"query"`
+	Filters   FilterParams `json:"filters"`
+	CreatedAt time.Time    `json:"createdAt"`
+	// URL is the shareable link, set in responses only
+	URL string `json:"url,omitempty"`
+}
@@ -0,0 +1,59 @@
+// internal/models/user.go
+package models
+
+import "time"
+
+// User is a local account. Guests are visitors who follow artists without
+// registering; they have no password and a generated username.
+type User struct {
+	ID        int64     `json:"id"`
+	Username  string    `json:"username"`
+	Guest     bool      `json:"guest,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// Favorite is an artist a user marked as favorite
+type Favorite struct {
+	ArtistID int       `json:"artistId"`
+	Name     string    `json:"name,omitempty"`
+	AddedAt  time.Time `json:"addedAt"`
+}
+
+// Credentials is the body of the register and login requests. Favorites
+// lists artist IDs favorited before signing in, merged into the account.
+type Credentials struct {
+	Username  string `json:"username"`
+	Password  string `json:"password"`
+	Favorites []int  `json:"favorites,omitempty"`
+}
+
+// Account describes the signed-in user. CSRFToken must be sent back in the
+// X-CSRF-Token header of every request that changes state.
+type Account struct {
+	User      User   `json:"user"`
+	CSRFToken string `json:"csrfToken"`
+}
+
+// Follow is an artist whose concerts a user follows
+type Follow struct {
+	ArtistID   int       `json:"artistId"`
+	Name       string    `json:"name,omitempty"`
+	FollowedAt time.Time `json:"followedAt"`
+}
+
+// Home is where a user lives; concerts within RadiusKm of it are nearby
+type Home struct {
+	GeoPoint
+	RadiusKm float64 `json:"radiusKm"`
+}
+
+// Concert is a concert of a followed artist. DistanceKm is only set when
+// concerts are filtered by distance.
+type Concert struct {
+	ArtistID   int      `json:"artistId"`
+	Artist     string   `json:"artist"`
+	Location   string   `json:"location"`
+	Place      string   `json:"place"`
+	Date       string   `json:"date"`
+	DistanceKm *float64 `json:"distanceKm,omitempty"`
+}
@@ -0,0 +1,223 @@
+// internal/openapi/openapi.go
+package openapi
+
+import (
+	"reflect"
+	"strings"
+	"time"
+)
+
+// Version of the OpenAPI specification the documents follow
+const Version = "3.0.3"
+
+// Info describes the API in the generated document
+type Info struct {
+	Title       string `json:"title"`
+	Version     string `json:"version"`
+	Description string `json:"description,omitempty"`
+}
+
+// Param describes a path or query parameter
+type Param struct {
+	Name        string
+	In          string // "path" or "query"
+	Description string
+	Type        string // JSON schema type, defaults to string
+	Required    bool
+}
+
+// Operation describes one route of the API
+type Operation struct {
+	Method  string
+	Path    string
+	Summary string
+	Tags    []string
+	Params  []Param
+	// Response is a sample value of the data returned by the route. Slices
+	// are documented as arrays of their element schema.
+	Response interface{}
+	// Envelope wraps the response schema in the given envelope type's
+	// "data" property.
+	Envelope interface{}
+	// ContentType overrides the default application/json response type
+	ContentType string
+}
+
+// Document is a generated OpenAPI document
+type Document map[string]interface{}
+
+// Build generates an OpenAPI document whose schemas are derived from the Go
+// types of the operation responses via their json struct tags
+func Build(info Info, operations []Operation) Document {
+	g := &generator{components: map[string]interface{}{}}
+	paths := map[string]interface{}{}
+
+	for _, op := range operations {
+		item, _ := paths[op.Path].(map[string]interface{})
+		if item == nil {
+			item = map[string]interface{}{}
+			paths[op.Path] = item
+		}
+		item[strings.ToLower(op.Method)] = g.operation(op)
+	}
+
+	return Document{
+		"openapi": Version,
+		"info":    info,
+		"paths":   paths,
+		"components": map[string]interface{}{
+			"schemas": g.components,
+		},
+	}
+}
+
+type generator struct {
+	components map[string]interface{}
+}
+
+func (g *generator) operation(op Operation) map[string]interface{} {
+	params := make([]interface{}, 0, len(op.Params))
+	for _, p := range op.Params {
+		typ := p.Type
+		if typ == "" {
+			typ = "string"
+		}
+		params = append(params, map[string]interface{}{
+			"name":        p.Name,
+			"in":          p.In,
+			"description": p.Description,
+			"required":    p.Required || p.In == "path",
+			"schema":      map[string]interface{}{"type": typ},
+		})
+	}
+
+	contentType := op.ContentType
+	if contentType == "" {
+		contentType = "application/json"
+	}
+
+	var schema interface{} = map[string]interface{}{"type": "object"}
+	if op.Response != nil {
+		schema = g.schema(reflect.TypeOf(op.Response))
+	}
+	if op.Envelope != nil {
+		envelope := g.schema(reflect.TypeOf(op.Envelope))
+		schema = map[string]interface{}{
+			"allOf": []interface{}{
+				envelope,
+				map[string]interface{}{
+					"type":       "object",
+					"properties": map[string]interface{}{"data": schema},
+				},
+			},
+		}
+	}
+
+	responses := map[string]interface{}{
+		"200": map[string]interface{}{
+			"description": "OK",
+			"content": map[string]interface{}{
+				contentType: map[string]interface{}{"schema": schema},
+			},
+		},
+	}
+	if op.Envelope != nil {
+		errSchema := g.schema(reflect.TypeOf(op.Envelope))
+		for _, code := range []string{"400", "404"} {
+			responses[code] = map[string]interface{}{
+				"description": "Error",
+				"content": map[string]interface{}{
+					"application/json": map[string]interface{}{"schema": errSchema},
+				},
+			}
+		}
+	}
+
+	result := map[string]interface{}{
+		"summary":   op.Summary,
+		"responses": responses,
+	}
+	if len(op.Tags) > 0 {
+		result["tags"] = op.Tags
+	}
+	if len(params) > 0 {
+		result["parameters"] = params
+	}
+	return result
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// schema returns the JSON schema of t, registering named structs as components
+func (g *generator) schema(t reflect.Type) map[string]interface{} {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	switch {
+	case t == timeType:
+		return map[string]interface{}{"type": "string", "format": "date-time"}
+	case t.Kind() == reflect.Struct && t.Name() != "":
+		if _, ok := g.components[t.Name()]; !ok {
+			// Reserve the name first so recursive types terminate
+			g.components[t.Name()] = map[string]interface{}{}
+			g.components[t.Name()] = g.structSchema(t)
+		}
+		return map[string]interface{}{"$ref": "#/components/schemas/" + t.Name()}
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		return g.structSchema(t)
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]interface{}{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+	case reflect.Slice, reflect.Array:
+		return map[string]interface{}{"type": "array", "items": g.schema(t.Elem())}
+	case reflect.Map:
+		return map[string]interface{}{"type": "object", "additionalProperties": g.schema(t.Elem())}
+	default:
+		// interface{} and anything else accepts any value
+		return map[string]interface{}{}
+	}
+}
+
+func (g *generator) structSchema(t reflect.Type) map[string]interface{} {
+	properties := map[string]interface{}{}
+	var required []string
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		name, opts, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		properties[name] = g.schema(field.Type)
+		if !strings.Contains(opts, "omitempty") {
+			required = append(required, name)
+		}
+	}
+
+	schema := map[string]interface{}{
+		"type":       "object",
+		"properties": properties,
+	}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+	return schema
+}
@@ -0,0 +1,204 @@
+// internal/router/router.go
+package router
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"groupie-tracker/internal/feed"
+	"groupie-tracker/internal/handlers"
+	"groupie-tracker/internal/middleware"
+	"groupie-tracker/internal/models"
+)
+
+// Config holds the router dependencies and per-route settings
+type Config struct {
+	Handler *handlers.Handler
+	Static  http.Handler
+
+	// APILimit applies to every API route, GeocodeLimit additionally to
+	// routes that geocode locations against the Mapbox quota
+	APILimit     middleware.Limit
+	GeocodeLimit middleware.Limit
+	// AuthLimit additionally applies to sign-in and registration to slow
+	// down password guessing
+	AuthLimit  middleware.Limit
+	TrustProxy bool
+}
+
+// Router dispatches requests using method-aware ServeMux patterns and
+// answers unmatched requests with JSON 404/405 errors
+type Router struct {
+	mux *http.ServeMux
+}
+
+// New registers every route and returns the router
+func New(config Config) *Router {
+	h := config.Handler
+	mux := http.NewServeMux()
+
+	// Rate limit API routes per client; each route gets its own budget and
+	// routes that geocode get a stricter one on top
+	limited := func(handler http.HandlerFunc, limits ...middleware.Limit) http.Handler {
+		var wrapped http.Handler = handler
+		for _, limit := range limits {
+			wrapped = middleware.RateLimit(middleware.NewRateLimiter(limit), config.TrustProxy)(wrapped)
+		}
+		return wrapped
+	}
+
+	// Pages
+	mux.HandleFunc("GET /{$}", h.HandleIndex())
+	mux.HandleFunc("GET /artist/{id}", h.HandleArtistDetails())
+	mux.HandleFunc("GET /artist/{id}/concerts.ics", h.HandleArtistCalendar)
+	mux.HandleFunc("GET /s/{id}", h.HandleSavedSearchRedirect)
+	mux.HandleFunc("GET /login", h.HandleLoginPage)
+	mux.HandleFunc("GET /me/concerts", h.HandleMyConcertsPage)
+	mux.HandleFunc("GET /me/shows", h.HandleMyShowsPage)
+	mux.HandleFunc("GET /calendar/{token}/concerts.ics", h.HandlePrivateCalendar)
+
+	// Feeds of newly announced concerts
+	for _, format := range []feed.Format{feed.Atom, feed.RSS} {
+		ext := "." + string(format)
+		mux.HandleFunc("GET /feeds/concerts"+ext, h.HandleConcertFeed(format))
+		mux.HandleFunc("GET /feeds/artists/{id}/concerts"+ext, h.HandleArtistFeed(format))
+		mux.HandleFunc("GET /feeds/locations/{location}/concerts"+ext, h.HandleLocationFeed(format))
+	}
+
+	// API
+	mux.Handle("POST /api/search", limited(h.HandleSearch, config.APILimit))
+	mux.Handle("GET /api/suggestions", limited(h.HandleSuggestions, config.APILimit))
+	mux.Handle("GET /api/filters/meta", limited(h.HandleFiltersMeta, config.APILimit))
+	mux.Handle("POST /api/searches", limited(h.HandleSaveSearch, config.APILimit))
+	mux.Handle("GET /api/searches/{id}", limited(h.HandleSavedSearch, config.APILimit))
+	exports := limited(h.HandleExport, config.APILimit)
+	mux.Handle("GET /api/export", exports)
+	mux.Handle("POST /api/export", exports)
+	artist := limited(h.HandleArtist, config.GeocodeLimit, config.APILimit)
+	mux.Handle("GET /api/artists/{id}", artist)
+	mux.Handle("GET /api/artist/{id}", artist) // legacy path
+	artistGeoJSON := limited(h.HandleArtistGeoJSON, config.GeocodeLimit, config.APILimit)
+	mux.Handle("GET /api/artists/{id}/locations.geojson", artistGeoJSON)
+	mux.Handle("GET /api/artist/{id}/locations.geojson", artistGeoJSON)
+	route := limited(h.HandleArtistRoute, config.GeocodeLimit, config.APILimit)
+	mux.Handle("GET /api/artists/{id}/route", route)
+	mux.Handle("GET /api/artist/{id}/route", route)
+	routeGeoJSON := limited(h.HandleArtistRouteGeoJSON, config.GeocodeLimit, config.APILimit)
+	mux.Handle("GET /api/artists/{id}/route.geojson", routeGeoJSON)
+	mux.Handle("GET /api/artist/{id}/route.geojson", routeGeoJSON)
+	mux.Handle("GET /api/locations.geojson", limited(h.HandleLocationsGeoJSON, config.APILimit))
+
+	// Accounts
+	mux.Handle("POST /api/auth/register", limited(h.HandleRegister, config.AuthLimit, config.APILimit))
+	mux.Handle("POST /api/auth/login", limited(h.HandleLogin, config.AuthLimit, config.APILimit))
+	mux.Handle("POST /api/auth/guest", limited(h.HandleGuest, config.AuthLimit, config.APILimit))
+	mux.Handle("POST /api/auth/logout", limited(h.HandleLogout, config.APILimit))
+	mux.Handle("GET /api/me", limited(h.HandleMe, config.APILimit))
+	mux.Handle("GET /api/me/favorites", limited(h.HandleFavorites, config.APILimit))
+	mux.Handle("PUT /api/me/favorites/{id}", limited(h.HandleAddFavorite, config.APILimit))
+	mux.Handle("DELETE /api/me/favorites/{id}", limited(h.HandleRemoveFavorite, config.APILimit))
+	mux.Handle("GET /api/me/follows", limited(h.HandleFollows, config.APILimit))
+	mux.Handle("PUT /api/me/follows/{id}", limited(h.HandleFollow, config.APILimit))
+	mux.Handle("DELETE /api/me/follows/{id}", limited(h.HandleUnfollow, config.APILimit))
+	mux.Handle("GET /api/me/home", limited(h.HandleHome, config.APILimit))
+	mux.Handle("PUT /api/me/home", limited(h.HandleSetHome, config.APILimit))
+	mux.Handle("DELETE /api/me/home", limited(h.HandleClearHome, config.APILimit))
+	mux.Handle("GET /api/me/concerts", limited(h.HandleMyConcerts, config.GeocodeLimit, config.APILimit))
+	mux.Handle("POST /api/me/calendar", limited(h.HandleCreateCalendar, config.APILimit))
+	mux.Handle("DELETE /api/me/calendar", limited(h.HandleRevokeCalendar, config.APILimit))
+	mux.Handle("GET /api/me/attendance", limited(h.HandleAttended, config.APILimit))
+	mux.Handle("GET /api/me/attendance/stats", limited(h.HandleAttendanceStats, config.APILimit))
+	mux.Handle("GET /api/me/attendance/export", limited(h.HandleExportAttendance, config.APILimit))
+	mux.Handle("POST /api/me/attendance/import", limited(h.HandleImportAttendance, config.APILimit))
+	mux.Handle("PUT /api/me/attendance/{id}/{location}/{date}", limited(h.HandleAttend, config.APILimit))
+	mux.Handle("DELETE /api/me/attendance/{id}/{location}/{date}", limited(h.HandleUnattend, config.APILimit))
+
+	// Versioned API
+	mux.Handle("GET /api/v1/artists", limited(h.HandleV1Artists, config.APILimit))
+	mux.Handle("GET /api/v1/artists/{id}", limited(h.HandleV1Artist, config.APILimit))
+	mux.Handle("GET /api/v1/locations", limited(h.HandleV1Locations, config.APILimit))
+	mux.Handle("GET /api/v1/locations/{id}", limited(h.HandleV1Location, config.APILimit))
+	mux.Handle("GET /api/v1/dates", limited(h.HandleV1Dates, config.APILimit))
+	mux.Handle("GET /api/v1/dates/{id}", limited(h.HandleV1Date, config.APILimit))
+	mux.Handle("GET /api/v1/relations", limited(h.HandleV1Relations, config.APILimit))
+	mux.Handle("GET /api/v1/relations/{id}", limited(h.HandleV1Relation, config.APILimit))
+	mux.HandleFunc("GET /api/v1/openapi.json", h.HandleV1OpenAPI)
+
+	// GraphQL
+	graphQL := limited(h.HandleGraphQL, config.APILimit)
+	mux.Handle("GET /graphql", graphQL)
+	mux.Handle("POST /graphql", graphQL)
+
+	// Static files
+	mux.Handle("GET /static/", config.Static)
+
+	return &Router{mux: mux}
+}
+
+// ServeHTTP implements http.Handler
+func (rt *Router) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	handler, pattern := rt.mux.Handler(r)
+	if pattern != "" {
+		// The logger redacts secrets such as calendar tokens by route
+		middleware.SetRoute(r, pattern)
+
+		// Serve through the mux so path values are populated
+		rt.mux.ServeHTTP(w, r)
+		return
+	}
+
+	// No route matched: let the mux decide between 404, 405 and redirects,
+	// then rewrite its plain-text errors as JSON
+	rec := &statusRecorder{header: make(http.Header)}
+	handler.ServeHTTP(rec, r)
+
+	writeError := middleware.WriteError
+	if strings.HasPrefix(r.URL.Path, "/api/v1/") {
+		writeError = writeV1Error
+	}
+
+	switch rec.status {
+	case http.StatusNotFound:
+		writeError(w, "Not found", http.StatusNotFound)
+	case http.StatusMethodNotAllowed:
+		w.Header().Set("Allow", rec.header.Get("Allow"))
+		writeError(w, "Method not allowed", http.StatusMethodNotAllowed)
+	default:
+		for k, v := range rec.header {
+			w.Header()[k] = v
+		}
+		w.WriteHeader(rec.status)
+	}
+}
+
+// writeV1Error writes an error wrapped in the v1 API envelope
+func writeV1Error(w http.ResponseWriter, message string, code int) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+
+	_ = json.NewEncoder(w).Encode(models.Envelope{
+		Error: &models.Error{Code: code, Message: message},
+	})
+}
+
+// statusRecorder captures the status and headers of the mux's fallback handlers
+type statusRecorder struct {
+	header http.Header
+	status int
+}
+
+func (r *statusRecorder) Header() http.Header { return r.header }
+
+func (r *statusRecorder) Write(b []byte) (int, error) {
+	if r.status == 0 {
+		r.status = http.StatusOK
+	}
+	return len(b), nil
+}
+
+func (r *statusRecorder) WriteHeader(code int) {
+	if r.status == 0 {
+		r.status = code
+	}
+}
@@ -0,0 +1,156 @@
+// internal/service/announce.go
+package service
+
+import (
+	"context"
+	"log/slog"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"groupie-tracker/internal/models"
+	"groupie-tracker/internal/store"
+)
+
+// AnnouncementService records concert dates that appear between cache
+// refreshes, newest first, keeping at most limit of them
+type AnnouncementService struct {
+	announcements []models.Announcement
+	limit         int
+	mutex         sync.RWMutex
+	logger        *slog.Logger
+	// store, when attached, keeps announcements across restarts
+	store store.AnnouncementStore
+}
+
+// NewAnnouncementService starts watching the cache for new concert dates
+func NewAnnouncementService(cache *CacheService, limit int, logger *slog.Logger) *AnnouncementService {
+	s := &AnnouncementService{
+		limit:  limit,
+		logger: logger,
+	}
+	cache.OnRefresh(s.record)
+	return s
+}
+
+// Attach loads the announcements kept in st and saves later ones to it. It
+// must be called before the first cache refresh.
+func (s *AnnouncementService) Attach(ctx context.Context, st store.AnnouncementStore) error {
+	stored, err := st.Announcements(ctx, s.storeLimit())
+	if err != nil {
+		return err
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.announcements = stored
+	s.store = st
+	return nil
+}
+
+// storeLimit is the number of announcements to keep in the store, where a
+// limit of zero keeps them all
+func (s *AnnouncementService) storeLimit() int {
+	if s.limit > 0 {
+		return s.limit
+	}
+	return math.MaxInt32
+}
+
+// Len returns the number of remembered announcements
+func (s *AnnouncementService) Len() int {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return len(s.announcements)
+}
+
+// record diffs the concerts of two datasets. Without data from an earlier
+// run, the first load has nothing to compare against and announces nothing.
+func (s *AnnouncementService) record(ctx context.Context, previous, current models.Datas) {
+	if len(previous.ArtistsData) == 0 {
+		return
+	}
+
+	known := make(map[string]bool)
+	for _, a := range concerts(previous, time.Time{}) {
+		known[a.Key()] = true
+	}
+
+	now := time.Now().UTC()
+	var found []models.Announcement
+	for _, a := range concerts(current, now) {
+		if !known[a.Key()] {
+			found = append(found, a)
+		}
+	}
+	if len(found) == 0 {
+		return
+	}
+
+	sort.Slice(found, func(i, j int) bool {
+		if found[i].Date != found[j].Date {
+			return found[i].Date > found[j].Date
+		}
+		return found[i].Key() < found[j].Key()
+	})
+
+	s.mutex.Lock()
+	s.announcements = append(found, s.announcements...)
+	if s.limit > 0 && len(s.announcements) > s.limit {
+		s.announcements = s.announcements[:s.limit]
+	}
+	st := s.store
+	s.mutex.Unlock()
+
+	if st != nil {
+		if err := st.AddAnnouncements(ctx, found, s.storeLimit()); err != nil {
+			s.logger.WarnContext(ctx, "failed to store announcements", "error", err)
+		}
+	}
+
+	s.logger.InfoContext(ctx, "new concerts announced", "count", len(found))
+}
+
+// Recent returns announcements matching keep, newest first
+func (s *AnnouncementService) Recent(keep func(models.Announcement) bool) []models.Announcement {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	var result []models.Announcement
+	for _, a := range s.announcements {
+		if keep == nil || keep(a) {
+			result = append(result, a)
+		}
+	}
+	return result
+}
+
+// concerts flattens the relations of a dataset, normalizing dates to
+// YYYY-MM-DD when they parse
+func concerts(data models.Datas, foundAt time.Time) []models.Announcement {
+	names := make(map[int]string, len(data.ArtistsData))
+	for _, artist := range data.ArtistsData {
+		names[artist.ID] = artist.Name
+	}
+
+	var result []models.Announcement
+	for _, entry := range data.RelationsData.Index {
+		for location, dates := range entry.DatesLocations {
+			for _, raw := range dates {
+				date := raw
+				if t, err := models.ParseConcertDate(raw); err == nil {
+					date = t.Format("2006-01-02")
+				}
+				result = append(result, models.Announcement{
+					ArtistID: entry.ID,
+					Artist:   names[entry.ID],
+					Location: location,
+					Date:     date,
+					FoundAt:  foundAt,
+				})
+			}
+		}
+	}
+	return result
+}
@@ -0,0 +1,167 @@
+// internal/service/attendance.go
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+	"unicode/utf8"
+
+	"groupie-tracker/internal/models"
+	"groupie-tracker/internal/store"
+)
+
+// MaxNotesLength bounds the notes of an attended concert, in characters
+const MaxNotesLength = 2000
+
+// ErrConcertNotFound is returned when an artist did not play a location on
+// a date
+var ErrConcertNotFound = errors.New("concert not found")
+
+// ValidateAttendanceNote checks the rating and notes of an attended concert
+func ValidateAttendanceNote(note models.AttendanceNote) error {
+	if note.Rating < 0 || note.Rating > 5 {
+		return errors.New("rating must be between 1 and 5, or 0 for none")
+	}
+	if utf8.RuneCountInString(note.Notes) > MaxNotesLength {
+		return fmt.Errorf("notes must be at most %d characters", MaxNotesLength)
+	}
+	return nil
+}
+
+// FindConcert looks up a concert in the relations. The date may be given as
+// YYYY-MM-DD or in the upstream format; the concert carries the former.
+func FindConcert(data models.Datas, artistID int, location, date string) (models.Concert, error) {
+	date = models.NormalizeConcertDate(date)
+	name := ""
+	for _, artist := range data.ArtistsData {
+		if artist.ID == artistID {
+			name = artist.Name
+			break
+		}
+	}
+	for _, entry := range data.RelationsData.Index {
+		if entry.ID != artistID {
+			continue
+		}
+		for _, raw := range entry.DatesLocations[location] {
+			if models.NormalizeConcertDate(raw) == date {
+				return models.Concert{
+					ArtistID: artistID,
+					Artist:   name,
+					Location: location,
+					Place:    models.FormatLocation(location),
+					Date:     date,
+				}, nil
+			}
+		}
+		break
+	}
+	return models.Concert{}, ErrConcertNotFound
+}
+
+// Attended lists the concerts a user attended, oldest first
+func (s *UserService) Attended(ctx context.Context, userID int64) ([]models.Attendance, error) {
+	return s.store.Attended(ctx, userID)
+}
+
+// Attend marks a concert as attended by a user, or updates its rating and
+// notes when it already is
+func (s *UserService) Attend(ctx context.Context, userID int64, a models.Attendance) (models.Attendance, error) {
+	if a.AddedAt.IsZero() {
+		a.AddedAt = time.Now().UTC()
+	}
+	return s.store.PutAttendance(ctx, userID, a)
+}
+
+// Unattend removes a concert from the ones a user attended
+func (s *UserService) Unattend(ctx context.Context, userID int64, artistID int, location, date string) error {
+	return s.store.DeleteAttendance(ctx, userID, artistID, location, date)
+}
+
+// ImportAttended marks every concert as attended in one transaction;
+// imported ratings and notes replace existing ones
+func (s *UserService) ImportAttended(ctx context.Context, userID int64, attended []models.Attendance) error {
+	now := time.Now().UTC()
+	return s.store.InTx(ctx, func(tx store.Tx) error {
+		for _, a := range attended {
+			if a.AddedAt.IsZero() {
+				a.AddedAt = now
+			}
+			if _, err := tx.PutAttendance(ctx, userID, a); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// DescribeAttended fills in the artist names, places and countries of
+// attended concerts
+func DescribeAttended(data models.Datas, attended []models.Attendance) {
+	names := ArtistNames(data)
+	for i := range attended {
+		attended[i].Artist = names[attended[i].ArtistID]
+		attended[i].Place = models.FormatLocation(attended[i].Location)
+		attended[i].Country = locationCountry(attended[i].Location)
+	}
+}
+
+// NewAttendanceStats summarizes described attended concerts, sorted by
+// date. Artists and countries are ordered by shows, then name.
+func NewAttendanceStats(attended []models.Attendance) models.AttendanceStats {
+	stats := models.AttendanceStats{
+		Shows:     len(attended),
+		Artists:   []models.SeenArtist{},
+		Countries: []models.SeenCountry{},
+	}
+	if len(attended) == 0 {
+		return stats
+	}
+	first, last := attended[0], attended[len(attended)-1]
+	stats.FirstShow, stats.LastShow = &first, &last
+
+	artists := make(map[int]int)
+	countries := make(map[string]int)
+	rated, total := 0, 0
+	for _, a := range attended {
+		if _, ok := artists[a.ArtistID]; !ok {
+			artists[a.ArtistID] = len(stats.Artists)
+			stats.Artists = append(stats.Artists, models.SeenArtist{ArtistID: a.ArtistID, Name: a.Artist})
+		}
+		stats.Artists[artists[a.ArtistID]].Shows++
+
+		if _, ok := countries[a.Country]; !ok {
+			countries[a.Country] = len(stats.Countries)
+			stats.Countries = append(stats.Countries, models.SeenCountry{Country: a.Country})
+		}
+		stats.Countries[countries[a.Country]].Shows++
+
+		if a.Rating > 0 {
+			rated++
+			total += a.Rating
+		}
+	}
+	if rated > 0 {
+		stats.AverageRating = math.Round(float64(total)/float64(rated)*10) / 10
+	}
+
+	sort.SliceStable(stats.Artists, func(i, j int) bool {
+		a, b := stats.Artists[i], stats.Artists[j]
+		if a.Shows != b.Shows {
+			return a.Shows > b.Shows
+		}
+		return a.Name < b.Name
+	})
+	sort.SliceStable(stats.Countries, func(i, j int) bool {
+		a, b := stats.Countries[i], stats.Countries[j]
+		if a.Shows != b.Shows {
+			return a.Shows > b.Shows
+		}
+		return a.Country < b.Country
+	})
+	return stats
+}
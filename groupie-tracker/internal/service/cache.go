@@ -2,67 +2,261 @@
 package service
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"log/slog"
 	"net/http"
 	"sync"
 	"time"
 
+	"golang.org/x/sync/singleflight"
+
 	"groupie-tracker/internal/models"
+	"groupie-tracker/internal/snapshot"
+	"groupie-tracker/internal/store"
 )
 
+// UpstreamSource describes data fetched from the upstream API
+const UpstreamSource = "https://groupietrackers.herokuapp.com/api"
+
 type CacheService struct {
 	data      models.Datas
+	info      CacheInfo
 	expiresAt time.Time
 	duration  time.Duration
 	mutex     sync.RWMutex
+	logger    *slog.Logger
+
+	// snapshot, when set, replaces the upstream API as the data source
+	snapshot *models.Datas
+	// store keeps the latest upstream data for when the API is down
+	store store.SnapshotStore
+
+	listeners []RefreshFunc
+	// refresh lets concurrent requests after expiry share one refresh
+	refresh singleflight.Group
 }
 
-func NewCacheService(duration time.Duration) *CacheService {
-	return &CacheService{
+// RefreshFunc is called after a refresh with the data it replaced and the
+// new data. On the first load, the data replaced is the snapshot stored by
+// an earlier run, or empty.
+type RefreshFunc func(ctx context.Context, previous, current models.Datas)
+
+// CacheInfo describes where the cached data came from
+type CacheInfo struct {
+	FetchedAt time.Time `json:"fetchedAt"`
+	Source    string    `json:"source"`
+}
+
+// CacheOption configures a CacheService
+type CacheOption func(*CacheService)
+
+// WithSnapshot serves data from a snapshot instead of the network; info
+// records when and where the snapshot was originally fetched
+func WithSnapshot(data models.Datas, info CacheInfo) CacheOption {
+	return func(c *CacheService) {
+		c.snapshot = &data
+		c.info = info
+	}
+}
+
+// WithStore saves every dataset fetched upstream to st, and serves the
+// saved one when the first fetch fails
+func WithStore(st store.SnapshotStore) CacheOption {
+	return func(c *CacheService) {
+		c.store = st
+	}
+}
+
+func NewCacheService(duration time.Duration, logger *slog.Logger, opts ...CacheOption) *CacheService {
+	c := &CacheService{
 		duration: duration,
+		logger:   logger,
+	}
+	for _, opt := range opts {
+		opt(c)
 	}
+	return c
 }
 
-func (c *CacheService) RefreshCache() error {
-	var newData models.Datas
-	if err := c.fetchAllData(&newData); err != nil {
-		return fmt.Errorf("failed to fetch data: %w", err)
+func (c *CacheService) RefreshCache(ctx context.Context) error {
+	start := time.Now()
+
+	var (
+		newData models.Datas
+		stored  models.Datas
+		info    CacheInfo
+	)
+	restored := false
+	if c.snapshot != nil {
+		newData = *c.snapshot
+	} else {
+		var err error
+		if newData, err = c.Fetch(ctx); err != nil {
+			saved, ok := c.restore(ctx, err)
+			if !ok {
+				return err
+			}
+			newData = saved.Data
+			info = CacheInfo{FetchedAt: saved.Meta.FetchedAt, Source: saved.Meta.Source}
+			restored = true
+		} else {
+			info = CacheInfo{FetchedAt: start, Source: UpstreamSource}
+			stored = c.stored(ctx)
+			c.save(ctx, newData, start)
+		}
 	}
 
 	c.mutex.Lock()
-	defer c.mutex.Unlock()
-
+	previous := c.data
+	if len(previous.ArtistsData) == 0 {
+		previous = stored
+	}
 	c.data = newData
+	if c.snapshot == nil {
+		c.info = info
+	}
 	c.expiresAt = time.Now().Add(c.duration)
+	listeners := c.listeners
+	c.mutex.Unlock()
+
+	switch {
+	case restored:
+		c.logger.WarnContext(ctx, "cache restored from stored snapshot",
+			"artists", len(newData.ArtistsData),
+			"fetched_at", c.info.FetchedAt,
+		)
+	case c.snapshot != nil:
+		c.logger.InfoContext(ctx, "cache loaded from snapshot",
+			"artists", len(newData.ArtistsData),
+			"source", c.info.Source,
+			"fetched_at", c.info.FetchedAt,
+		)
+	default:
+		c.logger.InfoContext(ctx, "cache refreshed",
+			"artists", len(newData.ArtistsData),
+			"duration", time.Since(start),
+		)
+	}
+
+	for _, fn := range listeners {
+		fn(ctx, previous, newData)
+	}
 
 	return nil
 }
 
-func (c *CacheService) GetCachedData() (models.Datas, error) {
-	c.mutex.RLock()
-	if time.Now().Before(c.expiresAt) {
-		defer c.mutex.RUnlock()
-		return c.data, nil
+// save stores data fetched upstream, so later runs can start without the
+// API
+func (c *CacheService) save(ctx context.Context, data models.Datas, fetchedAt time.Time) {
+	if c.store == nil {
+		return
 	}
+	if err := c.store.SaveSnapshot(ctx, snapshot.New(data, nil, UpstreamSource, fetchedAt)); err != nil {
+		c.logger.WarnContext(ctx, "failed to store snapshot", "error", err)
+	}
+}
+
+// earlier returns the snapshot stored by an earlier run, as long as this
+// run has loaded no data
+func (c *CacheService) earlier(ctx context.Context) (snapshot.Snapshot, bool) {
+	c.mutex.RLock()
+	loaded := !c.expiresAt.IsZero()
 	c.mutex.RUnlock()
+	if c.store == nil || loaded {
+		return snapshot.Snapshot{}, false
+	}
 
-	if err := c.RefreshCache(); err != nil {
+	saved, err := c.store.LatestSnapshot(ctx)
+	if err != nil {
+		if !errors.Is(err, store.ErrNotFound) {
+			c.logger.WarnContext(ctx, "failed to load stored snapshot", "error", err)
+		}
+		return snapshot.Snapshot{}, false
+	}
+	return saved, true
+}
+
+// stored returns the data of an earlier run before the first load, so
+// listeners can compare against it
+func (c *CacheService) stored(ctx context.Context) models.Datas {
+	saved, _ := c.earlier(ctx)
+	return saved.Data
+}
+
+// restore returns the stored snapshot when fetching failed with fetchErr
+// before any data was loaded; later failures keep reporting errors
+func (c *CacheService) restore(ctx context.Context, fetchErr error) (snapshot.Snapshot, bool) {
+	saved, ok := c.earlier(ctx)
+	if ok {
+		c.logger.WarnContext(ctx, "upstream unavailable, using stored snapshot", "error", fetchErr)
+	}
+	return saved, ok
+}
+
+// OnRefresh registers fn to be called after every successful refresh
+func (c *CacheService) OnRefresh(fn RefreshFunc) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.listeners = append(c.listeners, fn)
+}
+
+func (c *CacheService) GetCachedData(ctx context.Context) (models.Datas, error) {
+	if data, ok := c.fresh(); ok {
+		return data, nil
+	}
+
+	// The refresh serves every waiting request, so one client going away
+	// must not cancel it
+	_, err, _ := c.refresh.Do("refresh", func() (interface{}, error) {
+		if _, ok := c.fresh(); ok {
+			return nil, nil
+		}
+		return nil, c.RefreshCache(context.WithoutCancel(ctx))
+	})
+	if err != nil {
 		return models.Datas{}, fmt.Errorf("failed to refresh cache: %w", err)
 	}
 
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
 	return c.data, nil
 }
 
-func (c *CacheService) fetchAllData(data *models.Datas) error {
+// fresh returns the cached data unless it has expired
+func (c *CacheService) fresh() (models.Datas, bool) {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	return c.data, time.Now().Before(c.expiresAt)
+}
+
+// Info reports where the cached data came from and when it was fetched
+func (c *CacheService) Info() CacheInfo {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	return c.info
+}
+
+// Fetch downloads the full dataset from the upstream API without caching it
+func (c *CacheService) Fetch(ctx context.Context) (models.Datas, error) {
+	var data models.Datas
+	if err := c.fetchAllData(ctx, &data); err != nil {
+		return models.Datas{}, fmt.Errorf("failed to fetch data: %w", err)
+	}
+	return data, nil
+}
+
+func (c *CacheService) fetchAllData(ctx context.Context, data *models.Datas) error {
 	var wg sync.WaitGroup
 	errChan := make(chan error, 4)
 
 	wg.Add(4)
-	go c.fetchData(models.ArtistsAPI, &data.ArtistsData, &wg, errChan)
-	go c.fetchData(models.LocationsAPI, &data.LocationsData, &wg, errChan)
-	go c.fetchData(models.DatesAPI, &data.DatesData, &wg, errChan)
-	go c.fetchData(models.RelationsAPI, &data.RelationsData, &wg, errChan)
+	go c.fetchData(ctx, models.ArtistsAPI, &data.ArtistsData, &wg, errChan)
+	go c.fetchData(ctx, models.LocationsAPI, &data.LocationsData, &wg, errChan)
+	go c.fetchData(ctx, models.DatesAPI, &data.DatesData, &wg, errChan)
+	go c.fetchData(ctx, models.RelationsAPI, &data.RelationsData, &wg, errChan)
 
 	go func() {
 		wg.Wait()
@@ -78,14 +272,22 @@ func (c *CacheService) fetchAllData(data *models.Datas) error {
 	return nil
 }
 
-func (c *CacheService) fetchData(url string, target interface{}, wg *sync.WaitGroup, errChan chan<- error) {
+func (c *CacheService) fetchData(ctx context.Context, url string, target interface{}, wg *sync.WaitGroup, errChan chan<- error) {
 	defer wg.Done()
 
 	client := &http.Client{
 		Timeout: 10 * time.Second,
 	}
 
-	resp, err := client.Get(url)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		errChan <- fmt.Errorf("failed to build request for %s: %w", url, err)
+		return
+	}
+
+	c.logger.DebugContext(ctx, "fetching upstream data", "url", url)
+
+	resp, err := client.Do(req)
 	if err != nil {
 		errChan <- fmt.Errorf("failed to fetch data from %s: %w", url, err)
 		return
@@ -0,0 +1,245 @@
+// internal/service/catalog.go
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+
+	"groupie-tracker/internal/models"
+)
+
+// ErrNotFound is returned when a requested resource does not exist
+var ErrNotFound = errors.New("not found")
+
+// CatalogService exposes the cached upstream data as enriched API resources
+type CatalogService struct {
+	cache *CacheService
+}
+
+func NewCatalogService(cache *CacheService) *CatalogService {
+	return &CatalogService{cache: cache}
+}
+
+// ListArtists returns every artist resource ordered by ID
+func (s *CatalogService) ListArtists(ctx context.Context) ([]models.ArtistResource, error) {
+	data, err := s.cache.GetCachedData(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get cached data: %w", err)
+	}
+
+	artists := make([]models.ArtistResource, 0, len(data.ArtistsData))
+	for _, artist := range data.ArtistsData {
+		artists = append(artists, NewArtistResource(artist, data))
+	}
+	sort.Slice(artists, func(i, j int) bool { return artists[i].ID < artists[j].ID })
+
+	return artists, nil
+}
+
+// GetArtist returns a single artist resource
+func (s *CatalogService) GetArtist(ctx context.Context, id int) (models.ArtistResource, error) {
+	data, err := s.cache.GetCachedData(ctx)
+	if err != nil {
+		return models.ArtistResource{}, fmt.Errorf("failed to get cached data: %w", err)
+	}
+
+	for _, artist := range data.ArtistsData {
+		if artist.ID == id {
+			return NewArtistResource(artist, data), nil
+		}
+	}
+	return models.ArtistResource{}, ErrNotFound
+}
+
+// ListLocations returns the location resources of every artist
+func (s *CatalogService) ListLocations(ctx context.Context) ([]models.LocationResource, error) {
+	data, err := s.cache.GetCachedData(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get cached data: %w", err)
+	}
+
+	names := ArtistNames(data)
+	resources := make([]models.LocationResource, 0, len(data.LocationsData.Index))
+	for _, entry := range data.LocationsData.Index {
+		resources = append(resources, newLocationResource(entry.ID, names[entry.ID], entry.Locations))
+	}
+	return resources, nil
+}
+
+// GetLocation returns the location resource of an artist
+func (s *CatalogService) GetLocation(ctx context.Context, id int) (models.LocationResource, error) {
+	resources, err := s.ListLocations(ctx)
+	if err != nil {
+		return models.LocationResource{}, err
+	}
+	for _, r := range resources {
+		if r.ID == id {
+			return r, nil
+		}
+	}
+	return models.LocationResource{}, ErrNotFound
+}
+
+// ListDates returns the date resources of every artist
+func (s *CatalogService) ListDates(ctx context.Context) ([]models.DateResource, error) {
+	data, err := s.cache.GetCachedData(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get cached data: %w", err)
+	}
+
+	names := ArtistNames(data)
+	resources := make([]models.DateResource, 0, len(data.DatesData.Index))
+	for _, entry := range data.DatesData.Index {
+		dates := make([]models.EventDate, 0, len(entry.Dates))
+		for _, raw := range entry.Dates {
+			dates = append(dates, newEventDate(raw))
+		}
+		resources = append(resources, models.DateResource{
+			ID:         entry.ID,
+			ArtistName: names[entry.ID],
+			Dates:      dates,
+		})
+	}
+	return resources, nil
+}
+
+// GetDate returns the date resource of an artist
+func (s *CatalogService) GetDate(ctx context.Context, id int) (models.DateResource, error) {
+	resources, err := s.ListDates(ctx)
+	if err != nil {
+		return models.DateResource{}, err
+	}
+	for _, r := range resources {
+		if r.ID == id {
+			return r, nil
+		}
+	}
+	return models.DateResource{}, ErrNotFound
+}
+
+// ListRelations returns the relation resources of every artist
+func (s *CatalogService) ListRelations(ctx context.Context) ([]models.RelationResource, error) {
+	data, err := s.cache.GetCachedData(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get cached data: %w", err)
+	}
+
+	names := ArtistNames(data)
+	resources := make([]models.RelationResource, 0, len(data.RelationsData.Index))
+	for _, entry := range data.RelationsData.Index {
+		resources = append(resources, models.RelationResource{
+			ID:         entry.ID,
+			ArtistName: names[entry.ID],
+			Concerts:   NewLocationDates(entry.DatesLocations),
+		})
+	}
+	return resources, nil
+}
+
+// GetRelation returns the relation resource of an artist
+func (s *CatalogService) GetRelation(ctx context.Context, id int) (models.RelationResource, error) {
+	resources, err := s.ListRelations(ctx)
+	if err != nil {
+		return models.RelationResource{}, err
+	}
+	for _, r := range resources {
+		if r.ID == id {
+			return r, nil
+		}
+	}
+	return models.RelationResource{}, ErrNotFound
+}
+
+// NewArtistResource builds the v1 representation of an artist
+func NewArtistResource(artist models.Artist, data models.Datas) models.ArtistResource {
+	resource := models.ArtistResource{
+		ID:           artist.ID,
+		Name:         artist.Name,
+		Image:        artist.Image,
+		Members:      artist.Members,
+		MemberCount:  len(artist.Members),
+		CreationDate: artist.CreationDate,
+		FirstAlbum:   artist.FirstAlbum,
+		Links: models.Links{
+			Self:      fmt.Sprintf("/api/v1/artists/%d", artist.ID),
+			Locations: fmt.Sprintf("/api/v1/locations/%d", artist.ID),
+			Dates:     fmt.Sprintf("/api/v1/dates/%d", artist.ID),
+			Relations: fmt.Sprintf("/api/v1/relations/%d", artist.ID),
+		},
+	}
+
+	if year, err := models.ParseFirstAlbumYear(artist.FirstAlbum); err == nil {
+		resource.FirstAlbumYear = year
+	}
+	for _, entry := range data.LocationsData.Index {
+		if entry.ID == artist.ID {
+			resource.LocationCount = len(entry.Locations)
+			break
+		}
+	}
+	for _, entry := range data.RelationsData.Index {
+		if entry.ID == artist.ID {
+			for _, dates := range entry.DatesLocations {
+				resource.ConcertCount += len(dates)
+			}
+			break
+		}
+	}
+
+	return resource
+}
+
+func newLocationResource(id int, name string, slugs []string) models.LocationResource {
+	places := make([]models.NamedPlace, 0, len(slugs))
+	for _, slug := range slugs {
+		places = append(places, newNamedPlace(slug))
+	}
+	return models.LocationResource{ID: id, ArtistName: name, Locations: places}
+}
+
+func newNamedPlace(slug string) models.NamedPlace {
+	return models.NamedPlace{Slug: slug, Name: models.FormatLocation(slug)}
+}
+
+func newEventDate(raw string) models.EventDate {
+	date := models.EventDate{Raw: raw}
+	if t, err := models.ParseConcertDate(raw); err == nil {
+		date.Date = t.Format("2006-01-02")
+	}
+	return date
+}
+
+// NewLocationDates converts a relation map into a list sorted by location
+func NewLocationDates(datesLocations map[string][]string) []models.LocationDates {
+	concerts := make([]models.LocationDates, 0, len(datesLocations))
+	for slug, raw := range datesLocations {
+		dates := make([]string, 0, len(raw))
+		for _, d := range raw {
+			date := newEventDate(d)
+			if date.Date == "" {
+				date.Date = date.Raw
+			}
+			dates = append(dates, date.Date)
+		}
+		sort.Strings(dates)
+		concerts = append(concerts, models.LocationDates{
+			Location: newNamedPlace(slug),
+			Dates:    dates,
+		})
+	}
+	sort.Slice(concerts, func(i, j int) bool {
+		return concerts[i].Location.Slug < concerts[j].Location.Slug
+	})
+	return concerts
+}
+
+// ArtistNames maps artist IDs to names
+func ArtistNames(data models.Datas) map[int]string {
+	names := make(map[int]string, len(data.ArtistsData))
+	for _, artist := range data.ArtistsData {
+		names[artist.ID] = artist.Name
+	}
+	return names
+}
@@ -0,0 +1,106 @@
+// internal/service/facets.go
+package service
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+
+	"groupie-tracker/internal/geo"
+	"groupie-tracker/internal/models"
+)
+
+// ComputeFacets counts the given artists, usually a search result, per
+// filter value so clients can show which choices still match
+func ComputeFacets(data models.Datas, artists []models.Artist) models.Facets {
+	locations := make(map[int][]string, len(data.LocationsData.Index))
+	for _, entry := range data.LocationsData.Index {
+		locations[entry.ID] = entry.Locations
+	}
+
+	members := newFacetCounter()
+	places := newFacetCounter()
+	countries := newFacetCounter()
+	continents := newFacetCounter()
+	creation := newFacetCounter()
+	firstAlbum := newFacetCounter()
+
+	for _, artist := range artists {
+		count := strconv.Itoa(len(artist.Members))
+		members.add(artist.ID, count, count)
+
+		for _, loc := range locations[artist.ID] {
+			places.add(artist.ID, loc, models.FormatLocation(loc))
+			if country, ok := geo.LookupCountry(loc); ok {
+				countries.add(artist.ID, country.Code, country.Name)
+				continents.add(artist.ID, country.Continent, geo.ContinentName(country.Continent))
+			}
+		}
+
+		decade := artist.CreationDate / 10 * 10
+		creation.add(artist.ID, strconv.Itoa(decade), fmt.Sprintf("%ds", decade))
+
+		if year, err := models.ParseFirstAlbumYear(artist.FirstAlbum); err == nil {
+			decade := year / 10 * 10
+			firstAlbum.add(artist.ID, strconv.Itoa(decade), fmt.Sprintf("%ds", decade))
+		}
+	}
+
+	return models.Facets{
+		Members:           members.counts(byNumber),
+		Locations:         places.counts(byCount),
+		Countries:         countries.counts(byCount),
+		Continents:        continents.counts(byCount),
+		CreationDecades:   creation.counts(byNumber),
+		FirstAlbumDecades: firstAlbum.counts(byNumber),
+	}
+}
+
+// facetOrder compares two facet counts
+type facetOrder func(a, b models.FacetCount) bool
+
+// byCount puts the most common values first, then orders by value
+func byCount(a, b models.FacetCount) bool {
+	if a.Count != b.Count {
+		return a.Count > b.Count
+	}
+	return a.Value < b.Value
+}
+
+// byNumber orders numeric values ascending
+func byNumber(a, b models.FacetCount) bool {
+	x, _ := strconv.Atoi(a.Value)
+	y, _ := strconv.Atoi(b.Value)
+	return x < y
+}
+
+// facetCounter counts distinct artists per value
+type facetCounter struct {
+	labels  map[string]string
+	artists map[string]map[int]bool
+}
+
+func newFacetCounter() *facetCounter {
+	return &facetCounter{
+		labels:  make(map[string]string),
+		artists: make(map[string]map[int]bool),
+	}
+}
+
+func (f *facetCounter) add(artistID int, value, label string) {
+	if f.artists[value] == nil {
+		f.artists[value] = make(map[int]bool)
+		f.labels[value] = label
+	}
+	f.artists[value][artistID] = true
+}
+
+// counts returns the values in the given order
+func (f *facetCounter) counts(less facetOrder) []models.FacetCount {
+	counts := make([]models.FacetCount, 0, len(f.artists))
+	for value, artists := range f.artists {
+		counts = append(counts, models.FacetCount{Value: value, Label: f.labels[value], Count: len(artists)})
+	}
+	sort.Slice(counts, func(i, j int) bool { return less(counts[i], counts[j]) })
+	return counts
+}
@@ -2,31 +2,211 @@
 package service
 
 import (
+	"context"
 	"fmt"
+	"sort"
 	"strconv"
 	"strings"
+	"sync"
+	"time"
 
 	"groupie-tracker/internal/models"
 )
 
 type FilterService struct {
 	cache *CacheService
+
+	// meta is recomputed on every cache refresh
+	meta  *models.FilterMeta
+	mutex sync.RWMutex
 }
 
 func NewFilterService(cache *CacheService) *FilterService {
-	return &FilterService{
+	s := &FilterService{
 		cache: cache,
 	}
+	cache.OnRefresh(func(_ context.Context, _, current models.Datas) {
+		meta := BuildFilterMeta(current)
+		s.mutex.Lock()
+		s.meta = &meta
+		s.mutex.Unlock()
+	})
+	return s
+}
+
+// Meta returns the filter ranges and choices of the cached dataset
+func (s *FilterService) Meta(ctx context.Context) (models.FilterMeta, error) {
+	s.mutex.RLock()
+	meta := s.meta
+	s.mutex.RUnlock()
+	if meta != nil {
+		return *meta, nil
+	}
+
+	// Data loaded before the service was created has not been seen yet
+	data, err := s.cache.GetCachedData(ctx)
+	if err != nil {
+		return models.FilterMeta{}, fmt.Errorf("failed to get cached data: %w", err)
+	}
+	computed := BuildFilterMeta(data)
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.meta == nil {
+		s.meta = &computed
+	}
+	return *s.meta, nil
+}
+
+// BuildFilterMeta computes the filter ranges and choices of a dataset.
+// Years range from the earliest in the data, or the current year without
+// data, to the latest.
+func BuildFilterMeta(data models.Datas) models.FilterMeta {
+	currentYear := time.Now().Year()
+	meta := models.FilterMeta{
+		CreationYear:   models.YearRange{Min: currentYear},
+		FirstAlbumYear: models.YearRange{Min: currentYear},
+		MemberCounts:   []int{},
+		Locations:      []models.NamedPlace{},
+		GeneratedAt:    time.Now().UTC(),
+	}
+
+	memberCounts := map[int]bool{}
+	for _, artist := range data.ArtistsData {
+		meta.CreationYear.Min = min(meta.CreationYear.Min, artist.CreationDate)
+		meta.CreationYear.Max = max(meta.CreationYear.Max, artist.CreationDate)
+		if year, err := models.ParseFirstAlbumYear(artist.FirstAlbum); err == nil {
+			meta.FirstAlbumYear.Min = min(meta.FirstAlbumYear.Min, year)
+			meta.FirstAlbumYear.Max = max(meta.FirstAlbumYear.Max, year)
+		}
+		if n := len(artist.Members); !memberCounts[n] {
+			memberCounts[n] = true
+			meta.MemberCounts = append(meta.MemberCounts, n)
+		}
+	}
+	meta.CreationYear.Max = max(meta.CreationYear.Max, meta.CreationYear.Min)
+	meta.FirstAlbumYear.Max = max(meta.FirstAlbumYear.Max, meta.FirstAlbumYear.Min)
+	sort.Ints(meta.MemberCounts)
+
+	seen := map[string]bool{}
+	for _, entry := range data.LocationsData.Index {
+		for _, slug := range entry.Locations {
+			slug = strings.TrimSpace(slug)
+			if slug == "" || seen[slug] {
+				continue
+			}
+			seen[slug] = true
+			meta.Locations = append(meta.Locations, newNamedPlace(slug))
+		}
+	}
+	sort.Slice(meta.Locations, func(i, j int) bool {
+		return meta.Locations[i].Name < meta.Locations[j].Name
+	})
+
+	facets := ComputeFacets(data, data.ArtistsData)
+	meta.Countries = facets.Countries
+	meta.Continents = facets.Continents
+
+	return meta
+}
+
+// DefaultFilters returns filter parameters spanning the whole dataset
+// described by meta: from the earliest creation and first album years up to
+// the current year
+func DefaultFilters(meta models.FilterMeta) models.FilterParams {
+	currentYear := time.Now().Year()
+	return models.FilterParams{
+		CreationYearMin:   meta.CreationYear.Min,
+		CreationYearMax:   currentYear,
+		FirstAlbumYearMin: meta.FirstAlbumYear.Min,
+		FirstAlbumYearMax: currentYear,
+	}
+}
+
+// ValidateFilters validates the year, member, location match and geographic
+// filters and normalizes open bounds against meta. Every search entry point
+// runs it before filters reach the search service.
+func ValidateFilters(filters *models.FilterParams, meta models.FilterMeta) error {
+	currentYear := time.Now().Year()
+
+	// Validate year ranges; a zero bound is open and spans the dataset
+	defaults := DefaultFilters(meta)
+
+	if filters.CreationYearMin < defaults.CreationYearMin {
+		filters.CreationYearMin = defaults.CreationYearMin
+	}
+	if filters.CreationYearMax == 0 || filters.CreationYearMax > currentYear {
+		filters.CreationYearMax = currentYear
+	}
+	if filters.CreationYearMin > filters.CreationYearMax {
+		return fmt.Errorf("invalid creation year range: min (%d) > max (%d)",
+			filters.CreationYearMin, filters.CreationYearMax)
+	}
+
+	if filters.FirstAlbumYearMin < defaults.FirstAlbumYearMin {
+		filters.FirstAlbumYearMin = defaults.FirstAlbumYearMin
+	}
+	if filters.FirstAlbumYearMax == 0 || filters.FirstAlbumYearMax > currentYear {
+		filters.FirstAlbumYearMax = currentYear
+	}
+	if filters.FirstAlbumYearMin > filters.FirstAlbumYearMax {
+		return fmt.Errorf("invalid first album year range: min (%d) > max (%d)",
+			filters.FirstAlbumYearMin, filters.FirstAlbumYearMax)
+	}
+
+	// Validate member counts
+	for _, count := range append(filters.Members, filters.ExcludeMembers...) {
+		if count < 1 {
+			return fmt.Errorf("invalid member count: %d (must be positive)", count)
+		}
+	}
+	if filters.MembersMin < 0 || filters.MembersMax < 0 {
+		return fmt.Errorf("invalid member range: bounds must be positive")
+	}
+	if filters.MembersMax > 0 && filters.MembersMin > filters.MembersMax {
+		return fmt.Errorf("invalid member range: min (%d) > max (%d)",
+			filters.MembersMin, filters.MembersMax)
+	}
+
+	// Validate location semantics
+	switch filters.LocationMatch {
+	case "":
+		filters.LocationMatch = models.MatchAny
+	case models.MatchAny, models.MatchAll:
+	default:
+		return fmt.Errorf("invalid locationMatch %q (want %s or %s)",
+			filters.LocationMatch, models.MatchAny, models.MatchAll)
+	}
+
+	// Validate geographic filters
+	if filters.Near != nil {
+		if err := filters.Near.Validate(); err != nil {
+			return fmt.Errorf("invalid near: %w", err)
+		}
+		if filters.RadiusKm == 0 {
+			filters.RadiusKm = DefaultRadiusKm
+		}
+	}
+	if filters.RadiusKm < 0 || filters.RadiusKm > MaxRadiusKm {
+		return fmt.Errorf("invalid radius: %g km (must be between 0 and %d)", filters.RadiusKm, MaxRadiusKm)
+	}
+	if filters.BBox != nil {
+		if err := filters.BBox.Validate(); err != nil {
+			return fmt.Errorf("invalid bbox: %w", err)
+		}
+	}
+
+	return nil
 }
 
-func (s *FilterService) FilterArtists(query string, filters models.FilterParams) ([]models.Artist, error) {
+func (s *FilterService) FilterArtists(ctx context.Context, query string, filters models.FilterParams) ([]models.Artist, error) {
 	// Validate filters
 	if err := filters.Validate(); err != nil {
 		return nil, fmt.Errorf("invalid filters: %w", err)
 	}
 
 	// Get cached data
-	data, err := s.cache.GetCachedData()
+	data, err := s.cache.GetCachedData(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get cached data: %w", err)
 	}
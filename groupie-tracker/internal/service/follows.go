@@ -0,0 +1,124 @@
+// internal/service/follows.go
+package service
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"time"
+
+	"groupie-tracker/internal/models"
+	"groupie-tracker/internal/store"
+)
+
+// ErrCalendarNotFound is returned for unknown or revoked calendar tokens
+var ErrCalendarNotFound = errors.New("calendar not found")
+
+// Follows lists the artists a user follows, oldest first
+func (s *UserService) Follows(ctx context.Context, userID int64) ([]models.Follow, error) {
+	return s.store.Follows(ctx, userID)
+}
+
+// Follow makes a user follow an artist
+func (s *UserService) Follow(ctx context.Context, userID int64, artistID int) error {
+	return s.store.AddFollow(ctx, userID, artistID, time.Now().UTC())
+}
+
+// Unfollow stops a user following an artist
+func (s *UserService) Unfollow(ctx context.Context, userID int64, artistID int) error {
+	return s.store.RemoveFollow(ctx, userID, artistID)
+}
+
+// Home returns the home location of a user, if they set one
+func (s *UserService) Home(ctx context.Context, userID int64) (models.Home, bool, error) {
+	home, err := s.store.Home(ctx, userID)
+	if errors.Is(err, store.ErrNotFound) {
+		return models.Home{}, false, nil
+	}
+	if err != nil {
+		return models.Home{}, false, err
+	}
+	return home, true, nil
+}
+
+// SetHome stores the home location of a user
+func (s *UserService) SetHome(ctx context.Context, userID int64, home models.Home) error {
+	return s.store.SetHome(ctx, userID, home)
+}
+
+// ClearHome forgets the home location of a user
+func (s *UserService) ClearHome(ctx context.Context, userID int64) error {
+	return s.store.ClearHome(ctx, userID)
+}
+
+// CreateCalendarToken returns a new secret token for the private calendar
+// of a user, revoking the previous one
+func (s *UserService) CreateCalendarToken(ctx context.Context, userID int64) (string, error) {
+	token, err := randomToken()
+	if err != nil {
+		return "", err
+	}
+	if err := s.store.SetCalendarToken(ctx, userID, hashToken(token), time.Now().UTC()); err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+// RevokeCalendarToken disables the private calendar of a user
+func (s *UserService) RevokeCalendarToken(ctx context.Context, userID int64) error {
+	return s.store.DeleteCalendarToken(ctx, userID)
+}
+
+// CalendarUser returns the user a calendar token belongs to
+func (s *UserService) CalendarUser(ctx context.Context, token string) (models.User, error) {
+	user, err := s.store.CalendarUser(ctx, hashToken(token))
+	if errors.Is(err, store.ErrNotFound) {
+		return models.User{}, ErrCalendarNotFound
+	}
+	return user, err
+}
+
+// ArtistConcerts lists every concert of the given artists, sorted by date
+func ArtistConcerts(data models.Datas, artistIDs []int) []models.Concert {
+	followed := make(map[int]bool, len(artistIDs))
+	for _, id := range artistIDs {
+		followed[id] = true
+	}
+	names := make(map[int]string, len(artistIDs))
+	for _, artist := range data.ArtistsData {
+		if followed[artist.ID] {
+			names[artist.ID] = artist.Name
+		}
+	}
+
+	var concerts []models.Concert
+	for _, entry := range data.RelationsData.Index {
+		name, ok := names[entry.ID]
+		if !ok {
+			continue
+		}
+		for slug, dates := range entry.DatesLocations {
+			for _, raw := range dates {
+				concerts = append(concerts, models.Concert{
+					ArtistID: entry.ID,
+					Artist:   name,
+					Location: slug,
+					Place:    models.FormatLocation(slug),
+					Date:     models.NormalizeConcertDate(raw),
+				})
+			}
+		}
+	}
+
+	sort.Slice(concerts, func(i, j int) bool {
+		a, b := concerts[i], concerts[j]
+		if a.Date != b.Date {
+			return a.Date < b.Date
+		}
+		if a.Artist != b.Artist {
+			return a.Artist < b.Artist
+		}
+		return a.Location < b.Location
+	})
+	return concerts
+}
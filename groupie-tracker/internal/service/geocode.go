@@ -0,0 +1,174 @@
+// internal/service/geocode.go
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"groupie-tracker/internal/models"
+	"groupie-tracker/internal/store"
+)
+
+// GeocodeCache remembers geocoding results by address so each location is
+// only looked up once against the Mapbox quota. Once attached to a store,
+// recorded results are written through to it.
+type GeocodeCache struct {
+	entries map[string]models.GeoLocation
+	store   store.GeocodeStore
+	mutex   sync.RWMutex
+	// version counts the writes to entries, so indexes built from them can
+	// tell when they are stale
+	version uint64
+}
+
+func NewGeocodeCache() *GeocodeCache {
+	return &GeocodeCache{entries: make(map[string]models.GeoLocation)}
+}
+
+// Get returns the cached result for an address
+func (c *GeocodeCache) Get(address string) (models.GeoLocation, bool) {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	loc, ok := c.entries[address]
+	return loc, ok
+}
+
+// Put stores the result for an address
+func (c *GeocodeCache) Put(address string, loc models.GeoLocation) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.entries[address] = loc
+	c.version++
+}
+
+// Record stores a fresh geocoding result, writing it through to the
+// attached store
+func (c *GeocodeCache) Record(ctx context.Context, address string, loc models.GeoLocation) error {
+	c.Put(address, loc)
+
+	c.mutex.RLock()
+	st := c.store
+	c.mutex.RUnlock()
+	if st == nil {
+		return nil
+	}
+	return st.PutGeocode(ctx, address, loc)
+}
+
+// Attach merges the results kept in st, saves to st the cached results it
+// lacks and writes later recorded results through to it
+func (c *GeocodeCache) Attach(ctx context.Context, st store.Store) error {
+	stored, err := st.Geocodes(ctx)
+	if err != nil {
+		return err
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	err = st.InTx(ctx, func(tx store.Tx) error {
+		for address, loc := range c.entries {
+			if _, ok := stored[address]; ok {
+				continue
+			}
+			if err := tx.PutGeocode(ctx, address, loc); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for address, loc := range stored {
+		c.entries[address] = loc
+	}
+	c.version++
+	c.store = st
+	return nil
+}
+
+// Len returns the number of cached addresses
+func (c *GeocodeCache) Len() int {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	return len(c.entries)
+}
+
+// Version changes whenever a result is added or replaced
+func (c *GeocodeCache) Version() uint64 {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	return c.version
+}
+
+// Entries returns a copy of every cached result
+func (c *GeocodeCache) Entries() map[string]models.GeoLocation {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	entries := make(map[string]models.GeoLocation, len(c.entries))
+	for k, v := range c.entries {
+		entries[k] = v
+	}
+	return entries
+}
+
+// LoadFile merges results saved by SaveFile. A missing file is not an error.
+func (c *GeocodeCache) LoadFile(path string) error {
+	b, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read geocode cache: %w", err)
+	}
+
+	var entries map[string]models.GeoLocation
+	if err := json.Unmarshal(b, &entries); err != nil {
+		return fmt.Errorf("failed to decode geocode cache %s: %w", path, err)
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	for k, v := range entries {
+		c.entries[k] = v
+	}
+	c.version++
+	return nil
+}
+
+// SaveFile writes every cached result to path atomically
+func (c *GeocodeCache) SaveFile(path string) error {
+	b, err := json.MarshalIndent(c.Entries(), "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode geocode cache: %w", err)
+	}
+	return writeFileAtomic(path, b)
+}
+
+// writeFileAtomic writes to a temporary file and renames it over path
+func writeFileAtomic(path string, b []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(b); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to replace %s: %w", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,127 @@
+// internal/service/route.go
+package service
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"groupie-tracker/internal/geo"
+	"groupie-tracker/internal/models"
+)
+
+// BuildRoute orders an artist's dated concerts chronologically and measures
+// the legs between them. geocode resolves location slugs; locations it
+// cannot resolve stay on the route without distances.
+func BuildRoute(data models.Datas, artistID int, geocode func(location string) (models.GeoLocation, bool)) models.Route {
+	route := models.Route{
+		ArtistID:  artistID,
+		Stops:     []models.RouteStop{},
+		Legs:      []models.RouteLeg{},
+		Countries: []string{},
+	}
+
+	var relations map[string][]string
+	for _, entry := range data.RelationsData.Index {
+		if entry.ID == artistID {
+			relations = entry.DatesLocations
+			break
+		}
+	}
+
+	coords := make(map[string]*models.GeoLocation)
+	for location, dates := range relations {
+		if _, seen := coords[location]; !seen {
+			coords[location] = nil
+			if g, ok := geocode(location); ok {
+				coords[location] = &g
+			} else {
+				route.Ungeocoded = append(route.Ungeocoded, location)
+			}
+		}
+
+		for _, raw := range dates {
+			t, err := models.ParseConcertDate(raw)
+			if err != nil {
+				continue
+			}
+			stop := models.RouteStop{
+				Location: location,
+				Name:     models.FormatLocation(location),
+				Country:  locationCountry(location),
+				Date:     t.Format("2006-01-02"),
+			}
+			if g := coords[location]; g != nil {
+				stop.Lat, stop.Lon, stop.Geocoded = g.Lat, g.Lon, true
+			}
+			route.Stops = append(route.Stops, stop)
+		}
+	}
+	sort.Strings(route.Ungeocoded)
+	sort.Slice(route.Stops, func(i, j int) bool {
+		if route.Stops[i].Date != route.Stops[j].Date {
+			return route.Stops[i].Date < route.Stops[j].Date
+		}
+		return route.Stops[i].Location < route.Stops[j].Location
+	})
+
+	countries := make(map[string]bool)
+	previous := -1
+	for i, stop := range route.Stops {
+		if !countries[stop.Country] {
+			countries[stop.Country] = true
+			route.Countries = append(route.Countries, stop.Country)
+		}
+		if !stop.Geocoded {
+			continue
+		}
+		if previous >= 0 {
+			from := route.Stops[previous]
+			leg := models.RouteLeg{
+				From:       previous,
+				To:         i,
+				DistanceKm: roundKm(geo.Distance(from.Lat, from.Lon, stop.Lat, stop.Lon)),
+				Days:       daysBetween(from.Date, stop.Date),
+			}
+			route.Legs = append(route.Legs, leg)
+			route.TotalKm += leg.DistanceKm
+			if route.Longest == nil || leg.DistanceKm > route.Longest.DistanceKm {
+				longest := leg
+				route.Longest = &longest
+			}
+		}
+		previous = i
+	}
+	route.TotalKm = roundKm(route.TotalKm)
+
+	return route
+}
+
+// locationCountry returns the display name of the country of a location
+// slug, falling back to the formatted segment after the last hyphen
+func locationCountry(slug string) string {
+	if country, ok := geo.LookupCountry(slug); ok {
+		return country.Name
+	}
+	name := models.FormatLocation(slug)
+	if i := strings.LastIndex(name, ", "); i >= 0 {
+		return name[i+2:]
+	}
+	return name
+}
+
+// daysBetween returns the number of days between two YYYY-MM-DD dates
+func daysBetween(from, to string) int {
+	a, errA := time.Parse("2006-01-02", from)
+	b, errB := time.Parse("2006-01-02", to)
+	if errA != nil || errB != nil {
+		return 0
+	}
+	return int(math.Round(b.Sub(a).Hours() / 24))
+}
+
+// roundKm rounds a distance to 100 metres
+func roundKm(km float64) float64 {
+	return math.Round(km*10) / 10
+}
@@ -0,0 +1,149 @@
+// internal/service/saved.go
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base32"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"groupie-tracker/internal/models"
+	"groupie-tracker/internal/store"
+)
+
+// ErrSavedSearchLimit is returned by Save once the store is full
+var ErrSavedSearchLimit = errors.New("saved search limit reached")
+
+// savedSearchIDLength is the length of new IDs; it only grows when two
+// different searches share a prefix
+const savedSearchIDLength = 8
+
+// searchIDEncoding keeps IDs lowercase and URL-safe
+var searchIDEncoding = base32.NewEncoding("abcdefghijklmnopqrstuvwxyz234567").WithPadding(base32.NoPadding)
+
+// SavedSearchService saves searches under IDs derived from their content,
+// keeping at most limit of them
+type SavedSearchService struct {
+	store store.SearchStore
+	limit int
+	// mutex serializes saves, so ID collisions and the limit are checked
+	// against what was saved before
+	mutex sync.Mutex
+}
+
+// NewSavedSearchService keeps saved searches in st; a limit of 0 keeps any
+// number of them
+func NewSavedSearchService(st store.SearchStore, limit int) *SavedSearchService {
+	return &SavedSearchService{store: st, limit: limit}
+}
+
+// ImportFile copies the searches saved in a JSON file by earlier releases
+// into the store and returns how many were new. A missing file is not an
+// error.
+func (s *SavedSearchService) ImportFile(ctx context.Context, path string) (int, error) {
+	b, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to read saved searches: %w", err)
+	}
+	var searches map[string]models.SavedSearch
+	if err := json.Unmarshal(b, &searches); err != nil {
+		return 0, fmt.Errorf("failed to decode saved searches %s: %w", path, err)
+	}
+
+	imported := 0
+	for id, search := range searches {
+		search.ID = id
+		err := s.store.PutSavedSearch(ctx, search)
+		if errors.Is(err, store.ErrConflict) {
+			continue
+		}
+		if err != nil {
+			return imported, err
+		}
+		imported++
+	}
+	return imported, nil
+}
+
+// Save stores a search and returns it with its ID. Saving the same search
+// twice returns the existing entry.
+func (s *SavedSearchService) Save(ctx context.Context, query string, filters models.FilterParams) (models.SavedSearch, error) {
+	b, err := json.Marshal(struct {
+		Query   string              `json:"query"`
+		Filters models.FilterParams `json:"filters"`
+	}{query, filters})
+	if err != nil {
+		return models.SavedSearch{}, fmt.Errorf("failed to encode search: %w", err)
+	}
+	sum := sha256.Sum256(b)
+	digest := searchIDEncoding.EncodeToString(sum[:])
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	// IDs are digest prefixes, lengthened on the rare collision
+	var id string
+	for n := savedSearchIDLength; n <= len(digest); n++ {
+		id = digest[:n]
+		existing, err := s.store.SavedSearch(ctx, id)
+		if errors.Is(err, store.ErrNotFound) {
+			break
+		}
+		if err != nil {
+			return models.SavedSearch{}, err
+		}
+		if sameSearch(existing, query, filters) {
+			return existing, nil
+		}
+	}
+
+	if s.limit > 0 {
+		n, err := s.store.CountSavedSearches(ctx)
+		if err != nil {
+			return models.SavedSearch{}, err
+		}
+		if n >= s.limit {
+			return models.SavedSearch{}, ErrSavedSearchLimit
+		}
+	}
+
+	search := models.SavedSearch{
+		ID:        id,
+		Query:     query,
+		Filters:   filters,
+		CreatedAt: time.Now().UTC(),
+	}
+	if err := s.store.PutSavedSearch(ctx, search); err != nil {
+		return models.SavedSearch{}, err
+	}
+	return search, nil
+}
+
+// Get returns the search saved under id, if any
+func (s *SavedSearchService) Get(ctx context.Context, id string) (models.SavedSearch, bool, error) {
+	search, err := s.store.SavedSearch(ctx, strings.ToLower(id))
+	if errors.Is(err, store.ErrNotFound) {
+		return models.SavedSearch{}, false, nil
+	}
+	if err != nil {
+		return models.SavedSearch{}, false, err
+	}
+	return search, true, nil
+}
+
+// sameSearch reports whether a saved search has the given query and filters
+func sameSearch(saved models.SavedSearch, query string, filters models.FilterParams) bool {
+	a, _ := json.Marshal(saved.Filters)
+	b, _ := json.Marshal(filters)
+	return saved.Query == query && string(a) == string(b)
+}
@@ -2,32 +2,53 @@
 package service
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
+	"log/slog"
 	"net/http"
 	"net/url"
 	"sort"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
+	"groupie-tracker/internal/geo"
 	"groupie-tracker/internal/models"
 )
 
 type SearchService struct {
-	cache *CacheService
+	cache    *CacheService
+	geocodes *GeocodeCache
+	logger   *slog.Logger
+
+	// spatial indexes the geocode cache for region filters; it is rebuilt
+	// when the cache version differs from spatialVersion
+	spatial        *geo.Index
+	spatialVersion uint64
+	spatialMutex   sync.Mutex
 }
 
-func NewSearchService(cache *CacheService) *SearchService {
-	return &SearchService{cache: cache}
+// spatialCellDeg is the cell size of the spatial index in degrees
+const spatialCellDeg = 5
+
+// Radius limits of the near filter, in kilometres
+const (
+	DefaultRadiusKm = 100
+	MaxRadiusKm     = 20000
+)
+
+func NewSearchService(cache *CacheService, geocodes *GeocodeCache, logger *slog.Logger) *SearchService {
+	return &SearchService{cache: cache, geocodes: geocodes, logger: logger}
 }
 
-func (s *SearchService) GetSuggestions(query string) ([]models.Suggestion, error) {
+func (s *SearchService) GetSuggestions(ctx context.Context, query string) ([]models.Suggestion, error) {
 	if query == "" {
 		return []models.Suggestion{}, nil
 	}
 
-	data, err := s.cache.GetCachedData()
+	data, err := s.cache.GetCachedData(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get cached data: %w", err)
 	}
@@ -99,7 +120,7 @@ func (s *SearchService) GetSuggestions(query string) ([]models.Suggestion, error
 		}
 
 		// Location suggestions with improved matching
-		locations := s.GetLocationsForArtist(artist.ID)
+		locations := s.GetLocationsForArtist(ctx, artist.ID)
 		for _, location := range locations {
 			location = strings.TrimSpace(location)
 			if containsQueryParts(location) {
@@ -156,8 +177,8 @@ func (s *SearchService) GetSuggestions(query string) ([]models.Suggestion, error
 	return result, nil
 }
 
-func (s *SearchService) SearchArtists(query string, filters models.FilterParams) ([]models.Artist, error) {
-	data, err := s.cache.GetCachedData()
+func (s *SearchService) SearchArtists(ctx context.Context, query string, filters models.FilterParams) ([]models.Artist, error) {
+	data, err := s.cache.GetCachedData(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get cached data: %w", err)
 	}
@@ -166,9 +187,16 @@ func (s *SearchService) SearchArtists(query string, filters models.FilterParams)
 	queryParts := strings.Fields(queryLower)
 	var results []models.Artist
 
+	region := s.regionLocations(filters)
+
 	for _, artist := range data.ArtistsData {
 		// Skip if doesn't match filters
-		if !s.matchesFilters(artist, filters) {
+		if !s.matchesFilters(ctx, artist, filters) {
+			continue
+		}
+
+		// Skip if the artist never played in the requested region
+		if region != nil && !playedIn(data, artist.ID, region) {
 			continue
 		}
 
@@ -179,7 +207,7 @@ func (s *SearchService) SearchArtists(query string, filters models.FilterParams)
 		}
 
 		// Check if matches any search criteria
-		if s.matchesArtist(artist, queryParts) {
+		if s.matchesArtist(ctx, artist, queryParts) {
 			results = append(results, artist)
 		}
 	}
@@ -187,7 +215,100 @@ func (s *SearchService) SearchArtists(query string, filters models.FilterParams)
 	return results, nil
 }
 
-func (s *SearchService) matchesArtist(artist models.Artist, queryParts []string) bool {
+// regionLocations returns the geocoded locations matching the Near and
+// BBox filters, or nil when neither is set. Locations that were never
+// geocoded cannot match; Ungeocoded counts them.
+func (s *SearchService) regionLocations(filters models.FilterParams) map[string]bool {
+	if filters.Near == nil && filters.BBox == nil {
+		return nil
+	}
+
+	index := s.spatialIndex()
+	region := make(map[string]bool)
+	if filters.Near != nil {
+		for _, id := range index.WithinRadius(filters.Near.Lat, filters.Near.Lon, filters.RadiusKm) {
+			region[id] = true
+		}
+	}
+	if filters.BBox != nil {
+		b := filters.BBox
+		inBox := make(map[string]bool)
+		for _, id := range index.WithinBBox(b[0], b[1], b[2], b[3]) {
+			inBox[id] = true
+		}
+		if filters.Near == nil {
+			region = inBox
+		} else {
+			// Both filters apply
+			for id := range region {
+				if !inBox[id] {
+					delete(region, id)
+				}
+			}
+		}
+	}
+	return region
+}
+
+// Ungeocoded returns how many locations of data the Near and BBox filters
+// skip for lack of coordinates, or 0 when neither is set
+func (s *SearchService) Ungeocoded(data models.Datas, filters models.FilterParams) int {
+	if filters.Near == nil && filters.BBox == nil {
+		return 0
+	}
+
+	seen := make(map[string]bool)
+	n := 0
+	for _, entry := range data.LocationsData.Index {
+		for _, loc := range entry.Locations {
+			if seen[loc] {
+				continue
+			}
+			seen[loc] = true
+			if _, ok := s.geocodes.Get(loc); !ok {
+				n++
+			}
+		}
+	}
+	return n
+}
+
+// spatialIndex returns an index of every geocoded location
+func (s *SearchService) spatialIndex() *geo.Index {
+	s.spatialMutex.Lock()
+	defer s.spatialMutex.Unlock()
+
+	// Read the version first: writes racing with the rebuild then cause
+	// another rebuild rather than being missed
+	version := s.geocodes.Version()
+	if s.spatial == nil || version != s.spatialVersion {
+		index := geo.NewIndex(spatialCellDeg)
+		for address, loc := range s.geocodes.Entries() {
+			index.Insert(address, loc.Lat, loc.Lon)
+		}
+		s.spatial = index
+		s.spatialVersion = version
+	}
+	return s.spatial
+}
+
+// playedIn reports whether any concert location of the artist is in region
+func playedIn(data models.Datas, artistID int, region map[string]bool) bool {
+	for _, entry := range data.LocationsData.Index {
+		if entry.ID != artistID {
+			continue
+		}
+		for _, loc := range entry.Locations {
+			if region[loc] {
+				return true
+			}
+		}
+		return false
+	}
+	return false
+}
+
+func (s *SearchService) matchesArtist(ctx context.Context, artist models.Artist, queryParts []string) bool {
 	// Helper function to check if text contains all query parts
 	containsAllParts := func(text string) bool {
 		text = strings.ToLower(text)
@@ -223,7 +344,7 @@ func (s *SearchService) matchesArtist(artist models.Artist, queryParts []string)
 	}
 
 	// Check locations
-	locations := s.GetLocationsForArtist(artist.ID)
+	locations := s.GetLocationsForArtist(ctx, artist.ID)
 	for _, location := range locations {
 		if containsAllParts(location) {
 			return true
@@ -233,9 +354,9 @@ func (s *SearchService) matchesArtist(artist models.Artist, queryParts []string)
 	return false
 }
 
-func (s *SearchService) matchesFilters(artist models.Artist, filters models.FilterParams) bool {
+func (s *SearchService) matchesFilters(ctx context.Context, artist models.Artist, filters models.FilterParams) bool {
 	// Get locations data for the artist
-	cachedData, err := s.cache.GetCachedData()
+	cachedData, err := s.cache.GetCachedData(ctx)
 	if err != nil {
 		return false
 	}
@@ -250,48 +371,52 @@ func (s *SearchService) matchesFilters(artist models.Artist, filters models.Filt
 	}
 
 	// Creation year filter
-	if artist.CreationDate < filters.CreationYearMin ||
-		artist.CreationDate > filters.CreationYearMax {
+	if !inRange(artist.CreationDate, filters.CreationYearMin, filters.CreationYearMax) {
 		return false
 	}
 
 	// First album year filter
 	firstAlbumYear, err := parseFirstAlbumYear(artist.FirstAlbum)
-	if err != nil || firstAlbumYear < filters.FirstAlbumYearMin ||
-		firstAlbumYear > filters.FirstAlbumYearMax {
+	if err != nil || !inRange(firstAlbumYear, filters.FirstAlbumYearMin, filters.FirstAlbumYearMax) {
 		return false
 	}
 
 	// Members filter
-	if len(filters.Members) > 0 {
-		memberCount := len(artist.Members)
-		found := false
-		for _, count := range filters.Members {
-			if count == memberCount {
-				found = true
-				break
-			}
-		}
-		if !found {
-			return false
-		}
+	memberCount := len(artist.Members)
+	if len(filters.Members) > 0 && !models.ContainsInt(filters.Members, memberCount) {
+		return false
+	}
+	if !inRange(memberCount, filters.MembersMin, filters.MembersMax) {
+		return false
+	}
+	if models.ContainsInt(filters.ExcludeMembers, memberCount) {
+		return false
 	}
 
 	// Locations filter
 	if len(filters.Locations) > 0 && len(artistLocations) > 0 {
-		found := false
+		matched := 0
 		for _, filterLoc := range filters.Locations {
-			for _, loc := range artistLocations {
-				if strings.Contains(strings.ToLower(loc), strings.ToLower(filterLoc)) {
-					found = true
-					break
-				}
-			}
-			if found {
-				break
+			if matchesLocation(artistLocations, filterLoc) {
+				matched++
 			}
 		}
-		if !found {
+		if matched == 0 || (filters.LocationMatch == models.MatchAll && matched < len(filters.Locations)) {
+			return false
+		}
+	}
+	for _, excluded := range filters.ExcludeLocations {
+		if matchesLocation(artistLocations, excluded) {
+			return false
+		}
+	}
+	if len(filters.ExcludeCountries) > 0 && playedInCountries(artistLocations, filters.ExcludeCountries, nil) {
+		return false
+	}
+
+	// Country and continent filters
+	if len(filters.Countries) > 0 || len(filters.Continents) > 0 {
+		if !playedInCountries(artistLocations, filters.Countries, filters.Continents) {
 			return false
 		}
 	}
@@ -299,6 +424,48 @@ func (s *SearchService) matchesFilters(artist models.Artist, filters models.Filt
 	return true
 }
 
+// inRange reports whether min <= v <= max, where a zero bound is open
+func inRange(v, min, max int) bool {
+	return (min == 0 || v >= min) && (max == 0 || v <= max)
+}
+
+// matchesLocation reports whether any location contains the filter value,
+// ignoring case
+func matchesLocation(locations []string, filterLoc string) bool {
+	filterLoc = strings.ToLower(filterLoc)
+	for _, loc := range locations {
+		if strings.Contains(strings.ToLower(loc), filterLoc) {
+			return true
+		}
+	}
+	return false
+}
+
+// playedInCountries reports whether any location is in one of the country
+// codes or, when continents are given, on one of the continent codes
+func playedInCountries(locations, countryCodes, continentCodes []string) bool {
+	for _, loc := range locations {
+		country, ok := geo.LookupCountry(loc)
+		if !ok {
+			continue
+		}
+		if (len(countryCodes) == 0 || contains(countryCodes, country.Code)) &&
+			(len(continentCodes) == 0 || contains(continentCodes, country.Continent)) {
+			return true
+		}
+	}
+	return false
+}
+
+func contains(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
+
 func parseFirstAlbumYear(firstAlbum string) (int, error) {
 	parts := strings.Split(firstAlbum, "-")
 	if len(parts) != 3 {
@@ -308,14 +475,15 @@ func parseFirstAlbumYear(firstAlbum string) (int, error) {
 }
 
 // GetLocations returns the geocoded locations for an artist
-func (s *SearchService) GetLocations(id int, locationsData models.Location) []models.GeoLocation {
+func (s *SearchService) GetLocations(ctx context.Context, id int, locationsData models.Location) []models.GeoLocation {
 	var locations []models.GeoLocation
 
 	for _, loc := range locationsData.Index {
 		if loc.ID == id {
 			for _, location := range loc.Locations {
-				geoLoc, err := s.geocode(location)
+				geoLoc, err := s.Geocode(ctx, location)
 				if err != nil {
+					s.logger.WarnContext(ctx, "geocoding failed", "location", location, "error", err)
 					continue
 				}
 				locations = append(locations, geoLoc)
@@ -347,8 +515,31 @@ func (s *SearchService) GetRelations(id int, relationsData models.Relation) map[
 	return nil
 }
 
-// geocode converts an address to coordinates using Mapbox API
-func (s *SearchService) geocode(address string) (models.GeoLocation, error) {
+// Geocode converts an address to coordinates, consulting the geocode cache
+// before calling the Mapbox API
+func (s *SearchService) Geocode(ctx context.Context, address string) (models.GeoLocation, error) {
+	if loc, ok := s.geocodes.Get(address); ok {
+		return loc, nil
+	}
+
+	loc, err := s.fetchGeocode(ctx, address)
+	if err != nil {
+		return models.GeoLocation{}, err
+	}
+	if err := s.geocodes.Record(ctx, address, loc); err != nil {
+		s.logger.WarnContext(ctx, "failed to store geocode", "address", address, "error", err)
+	}
+	return loc, nil
+}
+
+// CachedGeocode returns the coordinates of an address if it was geocoded
+// before, without calling the Mapbox API
+func (s *SearchService) CachedGeocode(address string) (models.GeoLocation, bool) {
+	return s.geocodes.Get(address)
+}
+
+// fetchGeocode looks up an address with the Mapbox Geocoding API
+func (s *SearchService) fetchGeocode(ctx context.Context, address string) (models.GeoLocation, error) {
 	mapboxGeocodingAPI := models.GetMapboxGeocodingAPI()
 	mapboxAccessToken := models.GetMapboxAccessToken()
 
@@ -362,7 +553,14 @@ func (s *SearchService) geocode(address string) (models.GeoLocation, error) {
 		Timeout: 10 * time.Second,
 	}
 
-	resp, err := client.Get(geocodingURL)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, geocodingURL, nil)
+	if err != nil {
+		return models.GeoLocation{}, fmt.Errorf("failed to build geocoding request: %w", err)
+	}
+
+	s.logger.DebugContext(ctx, "geocoding address", "address", address)
+
+	resp, err := client.Do(req)
 	if err != nil {
 		return models.GeoLocation{}, fmt.Errorf("failed to geocode address: %w", err)
 	}
@@ -393,9 +591,9 @@ func (s *SearchService) geocode(address string) (models.GeoLocation, error) {
 	}, nil
 }
 
-// Add this helper function
-func (s *SearchService) GetLocationsForArtist(artistID int) []string {
-	data, err := s.cache.GetCachedData()
+// GetLocationsForArtist returns the raw location slugs for an artist
+func (s *SearchService) GetLocationsForArtist(ctx context.Context, artistID int) []string {
+	data, err := s.cache.GetCachedData(ctx)
 	if err != nil {
 		return nil
 	}
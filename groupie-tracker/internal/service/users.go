@@ -0,0 +1,355 @@
+// internal/service/users.go
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+
+	"groupie-tracker/internal/models"
+	"groupie-tracker/internal/store"
+)
+
+var (
+	ErrUsernameTaken      = errors.New("username already taken")
+	ErrInvalidCredentials = errors.New("invalid username or password")
+	ErrSessionNotFound    = errors.New("session not found or expired")
+)
+
+// Password length bounds; bcrypt ignores bytes past 72
+const (
+	MinPasswordLength = 8
+	MaxPasswordLength = 72
+)
+
+// SessionDuration is how long a session stays valid after sign-in
+const SessionDuration = 30 * 24 * time.Hour
+
+// guestPrefix starts the generated usernames of guests; accounts cannot
+// take it
+const guestPrefix = "guest-"
+
+// usernamePattern restricts usernames to lowercase, URL-safe characters
+var usernamePattern = regexp.MustCompile(`^[a-z0-9_.-]{3,32}$`)
+
+// dummyHash is compared against when a username does not exist, so both
+// failures take as long as a wrong password
+var dummyHash, _ = bcrypt.GenerateFromPassword([]byte("groupie-tracker"), bcrypt.DefaultCost)
+
+// Session is a signed-in browser. Token is only known when the session is
+// created; the database keeps its hash.
+type Session struct {
+	Token     string
+	UserID    int64
+	CSRFToken string
+	ExpiresAt time.Time
+}
+
+// UserService manages local accounts, their sessions and favorites
+type UserService struct {
+	store  store.Store
+	logger *slog.Logger
+}
+
+func NewUserService(store store.Store, logger *slog.Logger) *UserService {
+	return &UserService{store: store, logger: logger}
+}
+
+// ValidateCredentials checks a username and password before registration.
+// Usernames are case-insensitive and returned lowercased.
+func ValidateCredentials(username, password string) (string, error) {
+	username = strings.ToLower(strings.TrimSpace(username))
+	if !usernamePattern.MatchString(username) {
+		return "", errors.New("username must be 3 to 32 letters, digits, '_', '.' or '-'")
+	}
+	if strings.HasPrefix(username, guestPrefix) {
+		return "", fmt.Errorf("usernames starting with %q are reserved", guestPrefix)
+	}
+	if len(password) < MinPasswordLength || len(password) > MaxPasswordLength {
+		return "", fmt.Errorf("password must be %d to %d bytes long", MinPasswordLength, MaxPasswordLength)
+	}
+	return username, nil
+}
+
+// Register creates an account; callers validate the credentials first
+func (s *UserService) Register(ctx context.Context, username, password string) (models.User, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return models.User{}, fmt.Errorf("failed to hash password: %w", err)
+	}
+
+	user, err := s.store.CreateUser(ctx, username, string(hash), time.Now().UTC())
+	if errors.Is(err, store.ErrConflict) {
+		return models.User{}, ErrUsernameTaken
+	}
+	if err != nil {
+		return models.User{}, err
+	}
+
+	s.logger.InfoContext(ctx, "User registered", "user_id", user.ID)
+	return user, nil
+}
+
+// Authenticate returns the user with the given credentials
+func (s *UserService) Authenticate(ctx context.Context, username, password string) (models.User, error) {
+	user, hash, err := s.store.UserByName(ctx, strings.ToLower(strings.TrimSpace(username)))
+	if errors.Is(err, store.ErrNotFound) {
+		_ = bcrypt.CompareHashAndPassword(dummyHash, []byte(password))
+		return models.User{}, ErrInvalidCredentials
+	}
+	if err != nil {
+		return models.User{}, err
+	}
+
+	if user.Guest {
+		// Guests have no password to sign in with
+		_ = bcrypt.CompareHashAndPassword(dummyHash, []byte(password))
+		return models.User{}, ErrInvalidCredentials
+	}
+
+	if bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)) != nil {
+		return models.User{}, ErrInvalidCredentials
+	}
+	return user, nil
+}
+
+// StartGuest creates a guest for a visitor who has not registered and signs
+// it in, first dropping the guests whose sessions all expired
+func (s *UserService) StartGuest(ctx context.Context) (models.User, Session, error) {
+	suffix := make([]byte, 8)
+	if _, err := rand.Read(suffix); err != nil {
+		return models.User{}, Session{}, fmt.Errorf("failed to generate guest name: %w", err)
+	}
+
+	now := time.Now().UTC()
+	session, stored, err := newSession(0, now)
+	if err != nil {
+		return models.User{}, Session{}, err
+	}
+
+	var user models.User
+	err = s.store.InTx(ctx, func(tx store.Tx) error {
+		if err := tx.DeleteStaleGuests(ctx, now); err != nil {
+			return err
+		}
+		var err error
+		if user, err = tx.CreateGuest(ctx, guestPrefix+hex.EncodeToString(suffix), now); err != nil {
+			return err
+		}
+		session.UserID, stored.UserID = user.ID, user.ID
+		return tx.CreateSession(ctx, stored)
+	})
+	if err != nil {
+		return models.User{}, Session{}, err
+	}
+	return user, session, nil
+}
+
+// ClaimGuest registers a guest as an account, keeping what it saved;
+// callers validate the credentials first
+func (s *UserService) ClaimGuest(ctx context.Context, guestID int64, username, password string) (models.User, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return models.User{}, fmt.Errorf("failed to hash password: %w", err)
+	}
+
+	var user models.User
+	err = s.store.InTx(ctx, func(tx store.Tx) error {
+		if err := tx.ClaimGuest(ctx, guestID, username, string(hash)); err != nil {
+			return err
+		}
+		var err error
+		user, _, err = tx.UserByName(ctx, username)
+		return err
+	})
+	if errors.Is(err, store.ErrConflict) {
+		return models.User{}, ErrUsernameTaken
+	}
+	if err != nil {
+		return models.User{}, err
+	}
+
+	s.logger.InfoContext(ctx, "Guest registered", "user_id", user.ID)
+	return user, nil
+}
+
+// MergeGuest moves what a guest saved into an account and drops the guest.
+// Entries the account already has keep their dates, notes and ratings, and
+// its home location wins; the guest's calendar link stops working.
+func (s *UserService) MergeGuest(ctx context.Context, guestID, userID int64) error {
+	return s.store.InTx(ctx, func(tx store.Tx) error {
+		favorites, err := tx.Favorites(ctx, guestID)
+		if err != nil {
+			return err
+		}
+		for _, f := range favorites {
+			if err := tx.AddFavorite(ctx, userID, f.ArtistID, f.AddedAt); err != nil {
+				return err
+			}
+		}
+
+		follows, err := tx.Follows(ctx, guestID)
+		if err != nil {
+			return err
+		}
+		for _, f := range follows {
+			if err := tx.AddFollow(ctx, userID, f.ArtistID, f.FollowedAt); err != nil {
+				return err
+			}
+		}
+
+		attended, err := tx.Attended(ctx, userID)
+		if err != nil {
+			return err
+		}
+		type concert struct {
+			artistID       int
+			location, date string
+		}
+		known := make(map[concert]bool, len(attended))
+		for _, a := range attended {
+			known[concert{a.ArtistID, a.Location, a.Date}] = true
+		}
+		if attended, err = tx.Attended(ctx, guestID); err != nil {
+			return err
+		}
+		for _, a := range attended {
+			if known[concert{a.ArtistID, a.Location, a.Date}] {
+				continue
+			}
+			if _, err := tx.PutAttendance(ctx, userID, a); err != nil {
+				return err
+			}
+		}
+
+		if _, err := tx.Home(ctx, userID); errors.Is(err, store.ErrNotFound) {
+			home, err := tx.Home(ctx, guestID)
+			if err == nil {
+				err = tx.SetHome(ctx, userID, home)
+			}
+			if err != nil && !errors.Is(err, store.ErrNotFound) {
+				return err
+			}
+		} else if err != nil {
+			return err
+		}
+
+		return tx.DeleteUser(ctx, guestID)
+	})
+}
+
+// CreateSession signs a user in, dropping their expired sessions
+func (s *UserService) CreateSession(ctx context.Context, userID int64) (Session, error) {
+	now := time.Now().UTC()
+	session, stored, err := newSession(userID, now)
+	if err != nil {
+		return Session{}, err
+	}
+
+	if err := s.store.DeleteExpiredSessions(ctx, userID, now); err != nil {
+		return Session{}, err
+	}
+	if err := s.store.CreateSession(ctx, stored); err != nil {
+		return Session{}, err
+	}
+	return session, nil
+}
+
+// newSession generates the tokens of a session starting at now, returning
+// it as given to the browser and as stored
+func newSession(userID int64, now time.Time) (Session, store.Session, error) {
+	token, err := randomToken()
+	if err != nil {
+		return Session{}, store.Session{}, err
+	}
+	csrf, err := randomToken()
+	if err != nil {
+		return Session{}, store.Session{}, err
+	}
+
+	session := Session{
+		Token:     token,
+		UserID:    userID,
+		CSRFToken: csrf,
+		ExpiresAt: now.Add(SessionDuration),
+	}
+	return session, store.Session{
+		TokenHash: hashToken(token),
+		UserID:    userID,
+		CSRFToken: csrf,
+		CreatedAt: now,
+		ExpiresAt: session.ExpiresAt,
+	}, nil
+}
+
+// Session returns the user signed in with a session token
+func (s *UserService) Session(ctx context.Context, token string) (models.User, Session, error) {
+	user, stored, err := s.store.SessionUser(ctx, hashToken(token), time.Now().UTC())
+	if errors.Is(err, store.ErrNotFound) {
+		return models.User{}, Session{}, ErrSessionNotFound
+	}
+	if err != nil {
+		return models.User{}, Session{}, err
+	}
+	return user, Session{
+		Token:     token,
+		UserID:    user.ID,
+		CSRFToken: stored.CSRFToken,
+		ExpiresAt: stored.ExpiresAt,
+	}, nil
+}
+
+// DeleteSession signs a session out
+func (s *UserService) DeleteSession(ctx context.Context, token string) error {
+	return s.store.DeleteSession(ctx, hashToken(token))
+}
+
+// Favorites lists the favorite artists of a user, oldest first
+func (s *UserService) Favorites(ctx context.Context, userID int64) ([]models.Favorite, error) {
+	return s.store.Favorites(ctx, userID)
+}
+
+// AddFavorites marks artists as favorites of a user; artists already
+// marked keep their original date
+func (s *UserService) AddFavorites(ctx context.Context, userID int64, artistIDs []int) error {
+	now := time.Now().UTC()
+	return s.store.InTx(ctx, func(tx store.Tx) error {
+		for _, id := range artistIDs {
+			if err := tx.AddFavorite(ctx, userID, id, now); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// RemoveFavorite unmarks a favorite artist of a user
+func (s *UserService) RemoveFavorite(ctx context.Context, userID int64, artistID int) error {
+	return s.store.RemoveFavorite(ctx, userID, artistID)
+}
+
+// randomToken returns 256 random bits, URL-safe encoded
+func randomToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate token: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// hashToken is how session tokens are stored, so a leaked database does not
+// leak live sessions
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
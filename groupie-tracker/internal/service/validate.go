@@ -0,0 +1,140 @@
+// internal/service/validate.go
+package service
+
+import (
+	"fmt"
+	"sort"
+
+	"groupie-tracker/internal/models"
+)
+
+// Issue describes an inconsistency found in the dataset
+type Issue struct {
+	ArtistID int    `json:"artistId,omitempty"`
+	Message  string `json:"message"`
+}
+
+func (i Issue) String() string {
+	if i.ArtistID == 0 {
+		return i.Message
+	}
+	return fmt.Sprintf("artist %d: %s", i.ArtistID, i.Message)
+}
+
+// ValidateData checks that artists, locations, dates and relations agree
+// with each other and that dates parse
+func ValidateData(data models.Datas) []Issue {
+	var issues []Issue
+	report := func(id int, format string, args ...interface{}) {
+		issues = append(issues, Issue{ArtistID: id, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if len(data.ArtistsData) == 0 {
+		report(0, "dataset has no artists")
+	}
+
+	artists := make(map[int]bool, len(data.ArtistsData))
+	for _, artist := range data.ArtistsData {
+		if artist.ID < 1 {
+			report(0, "artist %q has invalid ID %d", artist.Name, artist.ID)
+			continue
+		}
+		if artists[artist.ID] {
+			report(artist.ID, "duplicate artist ID")
+		}
+		artists[artist.ID] = true
+
+		if artist.Name == "" {
+			report(artist.ID, "missing name")
+		}
+		if len(artist.Members) == 0 {
+			report(artist.ID, "no members")
+		}
+		if _, err := models.ParseConcertDate(artist.FirstAlbum); err != nil {
+			report(artist.ID, "invalid first album date %q", artist.FirstAlbum)
+		}
+		if year, err := models.ParseFirstAlbumYear(artist.FirstAlbum); err == nil && year < artist.CreationDate {
+			report(artist.ID, "first album (%d) predates creation (%d)", year, artist.CreationDate)
+		}
+	}
+
+	locations := make(map[int][]string)
+	for _, entry := range data.LocationsData.Index {
+		if !artists[entry.ID] {
+			report(entry.ID, "locations entry without artist")
+		}
+		locations[entry.ID] = entry.Locations
+	}
+
+	dates := make(map[int][]string)
+	for _, entry := range data.DatesData.Index {
+		if !artists[entry.ID] {
+			report(entry.ID, "dates entry without artist")
+		}
+		dates[entry.ID] = entry.Dates
+		for _, d := range entry.Dates {
+			if _, err := models.ParseConcertDate(d); err != nil {
+				report(entry.ID, "invalid concert date %q", d)
+			}
+		}
+	}
+
+	relations := make(map[int]map[string][]string)
+	for _, entry := range data.RelationsData.Index {
+		if !artists[entry.ID] {
+			report(entry.ID, "relations entry without artist")
+		}
+		relations[entry.ID] = entry.DatesLocations
+	}
+
+	ids := make([]int, 0, len(artists))
+	for id := range artists {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+
+	for _, id := range ids {
+		locs, hasLocs := locations[id]
+		artistDates, hasDates := dates[id]
+		rel, hasRel := relations[id]
+		if !hasLocs {
+			report(id, "missing locations entry")
+		}
+		if !hasDates {
+			report(id, "missing dates entry")
+		}
+		if !hasRel {
+			report(id, "missing relations entry")
+			continue
+		}
+
+		// Every relation location must be listed in the locations entry and
+		// vice versa
+		listed := make(map[string]bool, len(locs))
+		for _, loc := range locs {
+			listed[loc] = true
+		}
+		relDates := 0
+		for loc, ds := range rel {
+			relDates += len(ds)
+			if hasLocs && !listed[loc] {
+				report(id, "relation location %q not in locations", loc)
+			}
+			for _, d := range ds {
+				if _, err := models.ParseConcertDate(d); err != nil {
+					report(id, "invalid relation date %q at %s", d, loc)
+				}
+			}
+		}
+		for _, loc := range locs {
+			if _, ok := rel[loc]; !ok {
+				report(id, "location %q has no relation dates", loc)
+			}
+		}
+		if hasDates && relDates != len(artistDates) {
+			report(id, "relations list %d dates but dates entry has %d", relDates, len(artistDates))
+		}
+	}
+
+	return issues
+}
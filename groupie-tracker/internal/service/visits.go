@@ -0,0 +1,49 @@
+// internal/service/visits.go
+package service
+
+import (
+	"sort"
+
+	"groupie-tracker/internal/models"
+)
+
+// LocationVisits aggregates the concerts held at one location
+type LocationVisits struct {
+	Location  string
+	ArtistIDs []int
+	// Dates are YYYY-MM-DD where the upstream date parses, sorted
+	Dates  []string
+	Visits int
+}
+
+// VisitsByLocation groups the concerts of the artists accepted by keep (all
+// artists when keep is nil) by location, sorted by location slug
+func VisitsByLocation(data models.Datas, keep func(artistID int) bool) []LocationVisits {
+	byLocation := make(map[string]*LocationVisits)
+	for _, entry := range data.RelationsData.Index {
+		if keep != nil && !keep(entry.ID) {
+			continue
+		}
+		for location, dates := range entry.DatesLocations {
+			v := byLocation[location]
+			if v == nil {
+				v = &LocationVisits{Location: location}
+				byLocation[location] = v
+			}
+			v.ArtistIDs = append(v.ArtistIDs, entry.ID)
+			for _, raw := range dates {
+				v.Dates = append(v.Dates, models.NormalizeConcertDate(raw))
+			}
+			v.Visits += len(dates)
+		}
+	}
+
+	visits := make([]LocationVisits, 0, len(byLocation))
+	for _, v := range byLocation {
+		sort.Ints(v.ArtistIDs)
+		sort.Strings(v.Dates)
+		visits = append(visits, *v)
+	}
+	sort.Slice(visits, func(i, j int) bool { return visits[i].Location < visits[j].Location })
+	return visits
+}
@@ -0,0 +1,159 @@
+// internal/snapshot/snapshot.go
+package snapshot
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"groupie-tracker/internal/models"
+)
+
+// Version is the current snapshot format version. Bump it whenever the
+// layout changes incompatibly.
+const Version = 1
+
+// ErrUnsupportedVersion is returned for snapshots written by a newer release
+var ErrUnsupportedVersion = errors.New("unsupported snapshot version")
+
+// Snapshot is a reproducible copy of the dataset together with the
+// geocoding results for its locations
+type Snapshot struct {
+	Version  int                           `json:"version"`
+	Meta     Meta                          `json:"meta"`
+	Data     models.Datas                  `json:"data"`
+	Geocodes map[string]models.GeoLocation `json:"geocodes,omitempty"`
+}
+
+// Meta describes where and when a snapshot's data was obtained
+type Meta struct {
+	FetchedAt time.Time `json:"fetchedAt"`
+	CreatedAt time.Time `json:"createdAt"`
+	Source    string    `json:"source"`
+	Artists   int       `json:"artists"`
+	Locations int       `json:"locations"`
+}
+
+// New builds a snapshot of data fetched from source at fetchedAt
+func New(data models.Datas, geocodes map[string]models.GeoLocation, source string, fetchedAt time.Time) Snapshot {
+	return Snapshot{
+		Version: Version,
+		Meta: Meta{
+			FetchedAt: fetchedAt.UTC(),
+			CreatedAt: time.Now().UTC(),
+			Source:    source,
+			Artists:   len(data.ArtistsData),
+			Locations: len(geocodes),
+		},
+		Data:     data,
+		Geocodes: geocodes,
+	}
+}
+
+// Write encodes the snapshot as gzip-compressed JSON
+func Write(w io.Writer, s Snapshot) error {
+	zw := gzip.NewWriter(w)
+	zw.Name = "snapshot.json"
+	zw.ModTime = s.Meta.CreatedAt
+
+	if err := json.NewEncoder(zw).Encode(s); err != nil {
+		zw.Close()
+		return fmt.Errorf("failed to encode snapshot: %w", err)
+	}
+	if err := zw.Close(); err != nil {
+		return fmt.Errorf("failed to compress snapshot: %w", err)
+	}
+	return nil
+}
+
+// Read decodes a snapshot. Uncompressed input is accepted, as is a bare
+// dataset as written by the fetch command, which becomes a snapshot
+// without geocodes or metadata.
+func Read(r io.Reader) (Snapshot, error) {
+	br := bufio.NewReader(r)
+	if magic, err := br.Peek(2); err == nil && bytes.Equal(magic, []byte{0x1f, 0x8b}) {
+		zr, err := gzip.NewReader(br)
+		if err != nil {
+			return Snapshot{}, fmt.Errorf("failed to decompress snapshot: %w", err)
+		}
+		defer zr.Close()
+		r = zr
+	} else {
+		r = br
+	}
+
+	var raw struct {
+		Snapshot
+		models.Datas
+	}
+	if err := json.NewDecoder(r).Decode(&raw); err != nil {
+		return Snapshot{}, fmt.Errorf("failed to decode snapshot: %w", err)
+	}
+
+	s := raw.Snapshot
+	switch {
+	case s.Version == 0:
+		s = Snapshot{Data: raw.Datas}
+		s.Meta.Artists = len(s.Data.ArtistsData)
+	case s.Version > Version:
+		return Snapshot{}, fmt.Errorf("%w %d (latest is %d)", ErrUnsupportedVersion, s.Version, Version)
+	}
+	return s, nil
+}
+
+// LoadFile reads a snapshot from path
+func LoadFile(path string) (Snapshot, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return Snapshot{}, fmt.Errorf("failed to open snapshot: %w", err)
+	}
+	defer f.Close()
+
+	s, err := Read(f)
+	if err != nil {
+		return Snapshot{}, fmt.Errorf("%s: %w", path, err)
+	}
+	// Bare datasets carry no metadata; describe them by the file itself
+	if s.Meta.Source == "" {
+		s.Meta.Source = path
+	}
+	if s.Meta.FetchedAt.IsZero() {
+		if info, err := f.Stat(); err == nil {
+			s.Meta.FetchedAt = info.ModTime().UTC()
+		}
+	}
+	return s, nil
+}
+
+// SaveFile writes a snapshot to path atomically
+func SaveFile(path string, s Snapshot) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	// Snapshots are shared; keep the permissions os.Create would give
+	if err := tmp.Chmod(0o644); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	if err := Write(tmp, s); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to replace %s: %w", path, err)
+	}
+	return nil
+}
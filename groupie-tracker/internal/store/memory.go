@@ -0,0 +1,496 @@
+// internal/store/memory.go
+package store
+
+import (
+	"context"
+	"maps"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"groupie-tracker/internal/models"
+	"groupie-tracker/internal/snapshot"
+)
+
+// Memory is a Store that keeps everything in memory, for tests and
+// throwaway servers. Transactions work on a copy of the data that replaces
+// it on commit, and run one at a time.
+type Memory struct {
+	*memoryState
+	mutex sync.Mutex
+}
+
+// memoryState holds the records of a Memory store. Its mutex is nil within
+// transactions, which hold the store's lock throughout.
+type memoryState struct {
+	mutex *sync.Mutex
+
+	geocodes  map[string]models.GeoLocation
+	snapshot  *snapshot.Snapshot
+	announced []models.Announcement
+	searches  map[string]models.SavedSearch
+	users     map[int64]memoryUser
+	usernames map[string]int64
+	lastID    int64
+	sessions  map[string]Session
+	favorites map[int64]map[int]time.Time
+	follows   map[int64]map[int]time.Time
+	homes     map[int64]models.Home
+	calendars map[int64]memoryToken
+	attended  map[int64]map[attendanceKey]models.Attendance
+}
+
+type memoryUser struct {
+	user         models.User
+	passwordHash string
+}
+
+type memoryToken struct {
+	hash      string
+	createdAt time.Time
+}
+
+type attendanceKey struct {
+	artistID       int
+	location, date string
+}
+
+// NewMemory returns an empty Memory store
+func NewMemory() *Memory {
+	m := &Memory{memoryState: &memoryState{
+		geocodes:  make(map[string]models.GeoLocation),
+		searches:  make(map[string]models.SavedSearch),
+		users:     make(map[int64]memoryUser),
+		usernames: make(map[string]int64),
+		sessions:  make(map[string]Session),
+		favorites: make(map[int64]map[int]time.Time),
+		follows:   make(map[int64]map[int]time.Time),
+		homes:     make(map[int64]models.Home),
+		calendars: make(map[int64]memoryToken),
+		attended:  make(map[int64]map[attendanceKey]models.Attendance),
+	}}
+	m.memoryState.mutex = &m.mutex
+	return m
+}
+
+// InTx implements Store
+func (m *Memory) InTx(_ context.Context, fn func(tx Tx) error) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	tx := m.memoryState.clone()
+	if err := fn(tx); err != nil {
+		return err
+	}
+	tx.mutex = &m.mutex
+	*m.memoryState = *tx
+	return nil
+}
+
+// Close implements Store
+func (m *Memory) Close() error {
+	return nil
+}
+
+// lock takes the store's lock unless within a transaction and returns the
+// function releasing it
+func (s *memoryState) lock() func() {
+	if s.mutex == nil {
+		return func() {}
+	}
+	s.mutex.Lock()
+	return s.mutex.Unlock
+}
+
+// clone copies the records for a transaction
+func (s *memoryState) clone() *memoryState {
+	c := &memoryState{
+		geocodes:  maps.Clone(s.geocodes),
+		snapshot:  s.snapshot,
+		announced: s.announced,
+		searches:  maps.Clone(s.searches),
+		users:     maps.Clone(s.users),
+		usernames: maps.Clone(s.usernames),
+		lastID:    s.lastID,
+		sessions:  maps.Clone(s.sessions),
+		favorites: make(map[int64]map[int]time.Time, len(s.favorites)),
+		follows:   make(map[int64]map[int]time.Time, len(s.follows)),
+		homes:     maps.Clone(s.homes),
+		calendars: maps.Clone(s.calendars),
+		attended:  make(map[int64]map[attendanceKey]models.Attendance, len(s.attended)),
+	}
+	for id, favorites := range s.favorites {
+		c.favorites[id] = maps.Clone(favorites)
+	}
+	for id, follows := range s.follows {
+		c.follows[id] = maps.Clone(follows)
+	}
+	for id, attended := range s.attended {
+		c.attended[id] = maps.Clone(attended)
+	}
+	return c
+}
+
+// Geocodes implements GeocodeStore
+func (s *memoryState) Geocodes(_ context.Context) (map[string]models.GeoLocation, error) {
+	defer s.lock()()
+	return maps.Clone(s.geocodes), nil
+}
+
+// PutGeocode implements GeocodeStore
+func (s *memoryState) PutGeocode(_ context.Context, address string, loc models.GeoLocation) error {
+	defer s.lock()()
+	s.geocodes[address] = loc
+	return nil
+}
+
+// SaveSnapshot implements SnapshotStore
+func (s *memoryState) SaveSnapshot(_ context.Context, snap snapshot.Snapshot) error {
+	defer s.lock()()
+	s.snapshot = &snap
+	return nil
+}
+
+// LatestSnapshot implements SnapshotStore
+func (s *memoryState) LatestSnapshot(_ context.Context) (snapshot.Snapshot, error) {
+	defer s.lock()()
+	if s.snapshot == nil {
+		return snapshot.Snapshot{}, ErrNotFound
+	}
+	return *s.snapshot, nil
+}
+
+// Announcements implements AnnouncementStore
+func (s *memoryState) Announcements(_ context.Context, limit int) ([]models.Announcement, error) {
+	defer s.lock()()
+	return slices.Clone(s.announced[:min(limit, len(s.announced))]), nil
+}
+
+// AddAnnouncements implements AnnouncementStore. The list is rebuilt
+// rather than appended to, as transactions share it until they commit.
+func (s *memoryState) AddAnnouncements(_ context.Context, found []models.Announcement, limit int) error {
+	defer s.lock()()
+	announced := append(slices.Clone(found), s.announced...)
+	s.announced = announced[:min(limit, len(announced))]
+	return nil
+}
+
+// PutSavedSearch implements SearchStore
+func (s *memoryState) PutSavedSearch(_ context.Context, search models.SavedSearch) error {
+	defer s.lock()()
+	if _, ok := s.searches[search.ID]; ok {
+		return ErrConflict
+	}
+	s.searches[search.ID] = search
+	return nil
+}
+
+// SavedSearch implements SearchStore
+func (s *memoryState) SavedSearch(_ context.Context, id string) (models.SavedSearch, error) {
+	defer s.lock()()
+	search, ok := s.searches[id]
+	if !ok {
+		return models.SavedSearch{}, ErrNotFound
+	}
+	return search, nil
+}
+
+// CountSavedSearches implements SearchStore
+func (s *memoryState) CountSavedSearches(_ context.Context) (int, error) {
+	defer s.lock()()
+	return len(s.searches), nil
+}
+
+// CreateUser implements UserStore
+func (s *memoryState) CreateUser(_ context.Context, username, passwordHash string, createdAt time.Time) (models.User, error) {
+	defer s.lock()()
+	return s.createUser(models.User{Username: username, CreatedAt: createdAt}, passwordHash)
+}
+
+// CreateGuest implements UserStore
+func (s *memoryState) CreateGuest(_ context.Context, username string, createdAt time.Time) (models.User, error) {
+	defer s.lock()()
+	return s.createUser(models.User{Username: username, Guest: true, CreatedAt: createdAt}, "")
+}
+
+// createUser adds user, returning it with its ID; callers hold the lock
+func (s *memoryState) createUser(user models.User, passwordHash string) (models.User, error) {
+	if _, ok := s.usernames[user.Username]; ok {
+		return models.User{}, ErrConflict
+	}
+	s.lastID++
+	user.ID = s.lastID
+	s.users[user.ID] = memoryUser{user: user, passwordHash: passwordHash}
+	s.usernames[user.Username] = user.ID
+	return user, nil
+}
+
+// UserByName implements UserStore
+func (s *memoryState) UserByName(_ context.Context, username string) (models.User, string, error) {
+	defer s.lock()()
+	id, ok := s.usernames[username]
+	if !ok {
+		return models.User{}, "", ErrNotFound
+	}
+	u := s.users[id]
+	return u.user, u.passwordHash, nil
+}
+
+// ClaimGuest implements UserStore
+func (s *memoryState) ClaimGuest(_ context.Context, userID int64, username, passwordHash string) error {
+	defer s.lock()()
+	u, ok := s.users[userID]
+	if !ok || !u.user.Guest {
+		return ErrNotFound
+	}
+	if _, ok := s.usernames[username]; ok {
+		return ErrConflict
+	}
+	delete(s.usernames, u.user.Username)
+	u.user.Username, u.user.Guest, u.passwordHash = username, false, passwordHash
+	s.users[userID] = u
+	s.usernames[username] = userID
+	return nil
+}
+
+// DeleteUser implements UserStore
+func (s *memoryState) DeleteUser(_ context.Context, userID int64) error {
+	defer s.lock()()
+	s.deleteUser(userID)
+	return nil
+}
+
+// DeleteStaleGuests implements UserStore
+func (s *memoryState) DeleteStaleGuests(_ context.Context, now time.Time) error {
+	defer s.lock()()
+	live := make(map[int64]bool)
+	for _, session := range s.sessions {
+		if session.ExpiresAt.After(now) {
+			live[session.UserID] = true
+		}
+	}
+	for id, u := range s.users {
+		if u.user.Guest && !live[id] {
+			s.deleteUser(id)
+		}
+	}
+	return nil
+}
+
+// deleteUser drops a user and everything they saved; callers hold the lock
+func (s *memoryState) deleteUser(userID int64) {
+	u, ok := s.users[userID]
+	if !ok {
+		return
+	}
+	delete(s.usernames, u.user.Username)
+	delete(s.users, userID)
+	for hash, session := range s.sessions {
+		if session.UserID == userID {
+			delete(s.sessions, hash)
+		}
+	}
+	delete(s.favorites, userID)
+	delete(s.follows, userID)
+	delete(s.homes, userID)
+	delete(s.calendars, userID)
+	delete(s.attended, userID)
+}
+
+// CreateSession implements UserStore
+func (s *memoryState) CreateSession(_ context.Context, session Session) error {
+	defer s.lock()()
+	s.sessions[session.TokenHash] = session
+	return nil
+}
+
+// SessionUser implements UserStore
+func (s *memoryState) SessionUser(_ context.Context, tokenHash string, now time.Time) (models.User, Session, error) {
+	defer s.lock()()
+	session, ok := s.sessions[tokenHash]
+	if !ok || !session.ExpiresAt.After(now) {
+		return models.User{}, Session{}, ErrNotFound
+	}
+	return s.users[session.UserID].user, session, nil
+}
+
+// DeleteSession implements UserStore
+func (s *memoryState) DeleteSession(_ context.Context, tokenHash string) error {
+	defer s.lock()()
+	delete(s.sessions, tokenHash)
+	return nil
+}
+
+// DeleteExpiredSessions implements UserStore
+func (s *memoryState) DeleteExpiredSessions(_ context.Context, userID int64, now time.Time) error {
+	defer s.lock()()
+	for hash, session := range s.sessions {
+		if session.UserID == userID && !session.ExpiresAt.After(now) {
+			delete(s.sessions, hash)
+		}
+	}
+	return nil
+}
+
+// Favorites implements UserStore
+func (s *memoryState) Favorites(_ context.Context, userID int64) ([]models.Favorite, error) {
+	defer s.lock()()
+	favorites := []models.Favorite{}
+	for id, at := range s.favorites[userID] {
+		favorites = append(favorites, models.Favorite{ArtistID: id, AddedAt: at})
+	}
+	sort.Slice(favorites, func(i, j int) bool {
+		a, b := favorites[i], favorites[j]
+		if !a.AddedAt.Equal(b.AddedAt) {
+			return a.AddedAt.Before(b.AddedAt)
+		}
+		return a.ArtistID < b.ArtistID
+	})
+	return favorites, nil
+}
+
+// AddFavorite implements UserStore
+func (s *memoryState) AddFavorite(_ context.Context, userID int64, artistID int, addedAt time.Time) error {
+	defer s.lock()()
+	addArtist(s.favorites, userID, artistID, addedAt)
+	return nil
+}
+
+// RemoveFavorite implements UserStore
+func (s *memoryState) RemoveFavorite(_ context.Context, userID int64, artistID int) error {
+	defer s.lock()()
+	delete(s.favorites[userID], artistID)
+	return nil
+}
+
+// Follows implements UserStore
+func (s *memoryState) Follows(_ context.Context, userID int64) ([]models.Follow, error) {
+	defer s.lock()()
+	follows := []models.Follow{}
+	for id, at := range s.follows[userID] {
+		follows = append(follows, models.Follow{ArtistID: id, FollowedAt: at})
+	}
+	sort.Slice(follows, func(i, j int) bool {
+		a, b := follows[i], follows[j]
+		if !a.FollowedAt.Equal(b.FollowedAt) {
+			return a.FollowedAt.Before(b.FollowedAt)
+		}
+		return a.ArtistID < b.ArtistID
+	})
+	return follows, nil
+}
+
+// AddFollow implements UserStore
+func (s *memoryState) AddFollow(_ context.Context, userID int64, artistID int, followedAt time.Time) error {
+	defer s.lock()()
+	addArtist(s.follows, userID, artistID, followedAt)
+	return nil
+}
+
+// RemoveFollow implements UserStore
+func (s *memoryState) RemoveFollow(_ context.Context, userID int64, artistID int) error {
+	defer s.lock()()
+	delete(s.follows[userID], artistID)
+	return nil
+}
+
+// Home implements UserStore
+func (s *memoryState) Home(_ context.Context, userID int64) (models.Home, error) {
+	defer s.lock()()
+	home, ok := s.homes[userID]
+	if !ok {
+		return models.Home{}, ErrNotFound
+	}
+	return home, nil
+}
+
+// SetHome implements UserStore
+func (s *memoryState) SetHome(_ context.Context, userID int64, home models.Home) error {
+	defer s.lock()()
+	s.homes[userID] = home
+	return nil
+}
+
+// ClearHome implements UserStore
+func (s *memoryState) ClearHome(_ context.Context, userID int64) error {
+	defer s.lock()()
+	delete(s.homes, userID)
+	return nil
+}
+
+// SetCalendarToken implements UserStore
+func (s *memoryState) SetCalendarToken(_ context.Context, userID int64, tokenHash string, createdAt time.Time) error {
+	defer s.lock()()
+	s.calendars[userID] = memoryToken{hash: tokenHash, createdAt: createdAt}
+	return nil
+}
+
+// DeleteCalendarToken implements UserStore
+func (s *memoryState) DeleteCalendarToken(_ context.Context, userID int64) error {
+	defer s.lock()()
+	delete(s.calendars, userID)
+	return nil
+}
+
+// CalendarUser implements UserStore
+func (s *memoryState) CalendarUser(_ context.Context, tokenHash string) (models.User, error) {
+	defer s.lock()()
+	for userID, token := range s.calendars {
+		if token.hash == tokenHash {
+			return s.users[userID].user, nil
+		}
+	}
+	return models.User{}, ErrNotFound
+}
+
+// Attended implements UserStore
+func (s *memoryState) Attended(_ context.Context, userID int64) ([]models.Attendance, error) {
+	defer s.lock()()
+	attended := []models.Attendance{}
+	for _, a := range s.attended[userID] {
+		attended = append(attended, a)
+	}
+	sort.Slice(attended, func(i, j int) bool {
+		a, b := attended[i], attended[j]
+		if a.Date != b.Date {
+			return a.Date < b.Date
+		}
+		if a.ArtistID != b.ArtistID {
+			return a.ArtistID < b.ArtistID
+		}
+		return a.Location < b.Location
+	})
+	return attended, nil
+}
+
+// PutAttendance implements UserStore
+func (s *memoryState) PutAttendance(_ context.Context, userID int64, a models.Attendance) (models.Attendance, error) {
+	defer s.lock()()
+	key := attendanceKey{a.ArtistID, a.Location, a.Date}
+	if s.attended[userID] == nil {
+		s.attended[userID] = make(map[attendanceKey]models.Attendance)
+	}
+	if existing, ok := s.attended[userID][key]; ok {
+		a.AddedAt = existing.AddedAt
+	}
+	s.attended[userID][key] = a
+	return a, nil
+}
+
+// DeleteAttendance implements UserStore
+func (s *memoryState) DeleteAttendance(_ context.Context, userID int64, artistID int, location, date string) error {
+	defer s.lock()()
+	delete(s.attended[userID], attendanceKey{artistID, location, date})
+	return nil
+}
+
+// addArtist records an artist for a user unless it already is
+func addArtist(byUser map[int64]map[int]time.Time, userID int64, artistID int, at time.Time) {
+	if byUser[userID] == nil {
+		byUser[userID] = make(map[int]time.Time)
+	}
+	if _, ok := byUser[userID][artistID]; !ok {
+		byUser[userID][artistID] = at
+	}
+}
@@ -0,0 +1,191 @@
+// internal/store/migrate.go
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrSchemaTooNew is returned for databases migrated by a newer release
+var ErrSchemaTooNew = errors.New("database schema is newer than this release")
+
+// Migration is one step of the SQLite schema. Versions start at 1 and
+// increase by one; a released migration is never edited, only followed by
+// another.
+type Migration struct {
+	Version int
+	Name    string
+	SQL     string
+}
+
+// Migrations is the SQLite schema, oldest first. The first three create
+// their tables only when missing, as databases of earlier releases already
+// have them.
+var Migrations = []Migration{
+	{1, "accounts", `
+CREATE TABLE IF NOT EXISTS users (
+	id            INTEGER PRIMARY KEY,
+	username      TEXT NOT NULL UNIQUE,
+	password_hash TEXT NOT NULL,
+	created_at    TIMESTAMP NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS sessions (
+	token_hash TEXT PRIMARY KEY,
+	user_id    INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+	csrf_token TEXT NOT NULL,
+	created_at TIMESTAMP NOT NULL,
+	expires_at TIMESTAMP NOT NULL
+);
+CREATE INDEX IF NOT EXISTS sessions_user ON sessions(user_id);
+
+CREATE TABLE IF NOT EXISTS favorites (
+	user_id   INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+	artist_id INTEGER NOT NULL,
+	added_at  TIMESTAMP NOT NULL,
+	PRIMARY KEY (user_id, artist_id)
+);
+`},
+	{2, "follows", `
+CREATE TABLE IF NOT EXISTS follows (
+	user_id     INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+	artist_id   INTEGER NOT NULL,
+	followed_at TIMESTAMP NOT NULL,
+	PRIMARY KEY (user_id, artist_id)
+);
+
+CREATE TABLE IF NOT EXISTS homes (
+	user_id   INTEGER PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+	lat       REAL NOT NULL,
+	lon       REAL NOT NULL,
+	radius_km REAL NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS calendar_tokens (
+	user_id    INTEGER PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+	token_hash TEXT NOT NULL UNIQUE,
+	created_at TIMESTAMP NOT NULL
+);
+`},
+	{3, "attendance", `
+CREATE TABLE IF NOT EXISTS attendance (
+	user_id   INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+	artist_id INTEGER NOT NULL,
+	location  TEXT NOT NULL,
+	date      TEXT NOT NULL,
+	rating    INTEGER NOT NULL DEFAULT 0 CHECK (rating BETWEEN 0 AND 5),
+	notes     TEXT NOT NULL DEFAULT '',
+	added_at  TIMESTAMP NOT NULL,
+	PRIMARY KEY (user_id, artist_id, location, date)
+);
+`},
+	{4, "geocodes and snapshots", `
+CREATE TABLE geocodes (
+	address TEXT PRIMARY KEY,
+	lat     REAL NOT NULL,
+	lon     REAL NOT NULL
+);
+
+CREATE TABLE snapshots (
+	id         INTEGER PRIMARY KEY,
+	source     TEXT NOT NULL,
+	fetched_at TIMESTAMP NOT NULL,
+	created_at TIMESTAMP NOT NULL,
+	body       BLOB NOT NULL
+);
+`},
+	{5, "saved searches", `
+CREATE TABLE saved_searches (
+	id         TEXT PRIMARY KEY,
+	query      TEXT NOT NULL,
+	filters    TEXT NOT NULL,
+	created_at TIMESTAMP NOT NULL
+);
+`},
+	{6, "announcements", `
+CREATE TABLE announcements (
+	id        INTEGER PRIMARY KEY,
+	artist_id INTEGER NOT NULL,
+	artist    TEXT NOT NULL,
+	location  TEXT NOT NULL,
+	date      TEXT NOT NULL,
+	found_at  TIMESTAMP NOT NULL
+);
+`},
+	{7, "guest users", `
+ALTER TABLE users ADD COLUMN guest INTEGER NOT NULL DEFAULT 0;
+`},
+}
+
+// Migrate applies the migrations newer than the version of db, each in its
+// own transaction, and returns the resulting version
+func Migrate(ctx context.Context, db *sql.DB, migrations []Migration) (int, error) {
+	for i, m := range migrations {
+		if m.Version != i+1 {
+			return 0, fmt.Errorf("migration %q has version %d, want %d", m.Name, m.Version, i+1)
+		}
+	}
+
+	if _, err := db.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS schema_migrations (
+		version    INTEGER PRIMARY KEY,
+		name       TEXT NOT NULL,
+		applied_at TIMESTAMP NOT NULL
+	)`); err != nil {
+		return 0, fmt.Errorf("failed to create migrations table: %w", err)
+	}
+
+	var version int
+	if err := db.QueryRowContext(ctx,
+		`SELECT COALESCE(MAX(version), 0) FROM schema_migrations`).Scan(&version); err != nil {
+		return 0, fmt.Errorf("failed to read schema version: %w", err)
+	}
+	if version > len(migrations) {
+		return version, fmt.Errorf("%w: version %d, latest known is %d", ErrSchemaTooNew, version, len(migrations))
+	}
+
+	for _, m := range migrations[version:] {
+		err := withTx(ctx, db, func(tx *sql.Tx) error {
+			if _, err := tx.ExecContext(ctx, m.SQL); err != nil {
+				return err
+			}
+			_, err := tx.ExecContext(ctx,
+				`INSERT INTO schema_migrations (version, name, applied_at) VALUES (?, ?, ?)`,
+				m.Version, m.Name, time.Now().UTC())
+			return err
+		})
+		if err != nil {
+			return version, fmt.Errorf("failed to apply migration %d (%s): %w", m.Version, m.Name, err)
+		}
+		version = m.Version
+	}
+	return version, nil
+}
+
+// withTx runs fn in a transaction of db, committed when fn returns nil and
+// rolled back otherwise, including when fn panics
+func withTx(ctx context.Context, db *sql.DB, fn func(tx *sql.Tx) error) (err error) {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if p := recover(); p != nil {
+			tx.Rollback()
+			panic(p)
+		}
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if err := fn(tx); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,563 @@
+// internal/store/sqlite.go
+package store
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+
+	"groupie-tracker/internal/models"
+	"groupie-tracker/internal/snapshot"
+
+	// Pure-Go SQLite driver, registered as "sqlite"
+	_ "modernc.org/sqlite"
+)
+
+// SQLite is a Store in a SQLite database file
+type SQLite struct {
+	queries
+	db *sql.DB
+}
+
+// querier is what queries need from a database or a transaction
+type querier interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// queries implements Tx on top of a database or a transaction
+type queries struct {
+	q querier
+}
+
+// OpenSQLite opens the SQLite database at path, creating it when needed,
+// and applies the pending Migrations
+func OpenSQLite(ctx context.Context, path string) (*SQLite, error) {
+	// Pragmas are applied to every pooled connection
+	params := url.Values{}
+	params.Add("_pragma", "foreign_keys(1)")
+	params.Add("_pragma", "busy_timeout(5000)")
+	if path != ":memory:" {
+		params.Add("_pragma", "journal_mode(WAL)")
+	}
+
+	db, err := sql.Open("sqlite", "file:"+path+"?"+params.Encode())
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database %s: %w", path, err)
+	}
+	if path == ":memory:" {
+		// Each connection would get its own empty database
+		db.SetMaxOpenConns(1)
+	}
+
+	if _, err := Migrate(ctx, db, Migrations); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to migrate %s: %w", path, err)
+	}
+	return &SQLite{queries: queries{q: db}, db: db}, nil
+}
+
+// InTx implements Store
+func (s *SQLite) InTx(ctx context.Context, fn func(tx Tx) error) error {
+	return withTx(ctx, s.db, func(tx *sql.Tx) error {
+		return fn(queries{q: tx})
+	})
+}
+
+// SaveSnapshot implements SnapshotStore, replacing the stored snapshot in a
+// transaction so a failed write keeps the previous one
+func (s *SQLite) SaveSnapshot(ctx context.Context, snap snapshot.Snapshot) error {
+	return withTx(ctx, s.db, func(tx *sql.Tx) error {
+		return queries{q: tx}.SaveSnapshot(ctx, snap)
+	})
+}
+
+// Close implements Store
+func (s *SQLite) Close() error {
+	return s.db.Close()
+}
+
+// Geocodes implements GeocodeStore
+func (q queries) Geocodes(ctx context.Context) (map[string]models.GeoLocation, error) {
+	rows, err := q.q.QueryContext(ctx, `SELECT address, lat, lon FROM geocodes`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list geocodes: %w", err)
+	}
+	defer rows.Close()
+
+	geocodes := make(map[string]models.GeoLocation)
+	for rows.Next() {
+		var loc models.GeoLocation
+		if err := rows.Scan(&loc.Address, &loc.Lat, &loc.Lon); err != nil {
+			return nil, fmt.Errorf("failed to list geocodes: %w", err)
+		}
+		geocodes[loc.Address] = loc
+	}
+	return geocodes, rows.Err()
+}
+
+// PutGeocode implements GeocodeStore
+func (q queries) PutGeocode(ctx context.Context, address string, loc models.GeoLocation) error {
+	if _, err := q.q.ExecContext(ctx,
+		`INSERT INTO geocodes (address, lat, lon) VALUES (?, ?, ?)
+		 ON CONFLICT (address) DO UPDATE SET lat = excluded.lat, lon = excluded.lon`,
+		address, loc.Lat, loc.Lon); err != nil {
+		return fmt.Errorf("failed to store geocode: %w", err)
+	}
+	return nil
+}
+
+// SaveSnapshot implements SnapshotStore; the snapshot is kept compressed.
+// Outside transactions, SQLite.SaveSnapshot runs it in one.
+func (q queries) SaveSnapshot(ctx context.Context, s snapshot.Snapshot) error {
+	var body bytes.Buffer
+	if err := snapshot.Write(&body, s); err != nil {
+		return err
+	}
+	if _, err := q.q.ExecContext(ctx, `DELETE FROM snapshots`); err != nil {
+		return fmt.Errorf("failed to replace snapshot: %w", err)
+	}
+	if _, err := q.q.ExecContext(ctx,
+		`INSERT INTO snapshots (source, fetched_at, created_at, body) VALUES (?, ?, ?, ?)`,
+		s.Meta.Source, s.Meta.FetchedAt, s.Meta.CreatedAt, body.Bytes()); err != nil {
+		return fmt.Errorf("failed to store snapshot: %w", err)
+	}
+	return nil
+}
+
+// LatestSnapshot implements SnapshotStore
+func (q queries) LatestSnapshot(ctx context.Context) (snapshot.Snapshot, error) {
+	var body []byte
+	err := q.q.QueryRowContext(ctx, `SELECT body FROM snapshots ORDER BY id DESC LIMIT 1`).Scan(&body)
+	if errors.Is(err, sql.ErrNoRows) {
+		return snapshot.Snapshot{}, ErrNotFound
+	}
+	if err != nil {
+		return snapshot.Snapshot{}, fmt.Errorf("failed to load snapshot: %w", err)
+	}
+	return snapshot.Read(bytes.NewReader(body))
+}
+
+// Announcements implements AnnouncementStore. Rows are numbered in
+// announcement order, so the newest has the highest ID.
+func (q queries) Announcements(ctx context.Context, limit int) ([]models.Announcement, error) {
+	rows, err := q.q.QueryContext(ctx,
+		`SELECT artist_id, artist, location, date, found_at FROM announcements
+		 ORDER BY id DESC LIMIT ?`, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list announcements: %w", err)
+	}
+	defer rows.Close()
+
+	var announcements []models.Announcement
+	for rows.Next() {
+		var a models.Announcement
+		if err := rows.Scan(&a.ArtistID, &a.Artist, &a.Location, &a.Date, &a.FoundAt); err != nil {
+			return nil, fmt.Errorf("failed to list announcements: %w", err)
+		}
+		announcements = append(announcements, a)
+	}
+	return announcements, rows.Err()
+}
+
+// AddAnnouncements implements AnnouncementStore
+func (q queries) AddAnnouncements(ctx context.Context, found []models.Announcement, limit int) error {
+	// Insert oldest first so the newest gets the highest ID
+	for i := len(found) - 1; i >= 0; i-- {
+		a := found[i]
+		if _, err := q.q.ExecContext(ctx,
+			`INSERT INTO announcements (artist_id, artist, location, date, found_at) VALUES (?, ?, ?, ?, ?)`,
+			a.ArtistID, a.Artist, a.Location, a.Date, a.FoundAt); err != nil {
+			return fmt.Errorf("failed to store announcement: %w", err)
+		}
+	}
+	if _, err := q.q.ExecContext(ctx,
+		`DELETE FROM announcements WHERE id NOT IN (SELECT id FROM announcements ORDER BY id DESC LIMIT ?)`,
+		limit); err != nil {
+		return fmt.Errorf("failed to prune announcements: %w", err)
+	}
+	return nil
+}
+
+// PutSavedSearch implements SearchStore
+func (q queries) PutSavedSearch(ctx context.Context, search models.SavedSearch) error {
+	filters, err := json.Marshal(search.Filters)
+	if err != nil {
+		return fmt.Errorf("failed to encode filters: %w", err)
+	}
+	res, err := q.q.ExecContext(ctx,
+		`INSERT INTO saved_searches (id, query, filters, created_at) VALUES (?, ?, ?, ?)
+		 ON CONFLICT (id) DO NOTHING`,
+		search.ID, search.Query, string(filters), search.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to save search: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrConflict
+	}
+	return nil
+}
+
+// SavedSearch implements SearchStore
+func (q queries) SavedSearch(ctx context.Context, id string) (models.SavedSearch, error) {
+	search := models.SavedSearch{ID: id}
+	var filters string
+	err := q.q.QueryRowContext(ctx,
+		`SELECT query, filters, created_at FROM saved_searches WHERE id = ?`, id,
+	).Scan(&search.Query, &filters, &search.CreatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.SavedSearch{}, ErrNotFound
+	}
+	if err != nil {
+		return models.SavedSearch{}, fmt.Errorf("failed to look up saved search: %w", err)
+	}
+	if err := json.Unmarshal([]byte(filters), &search.Filters); err != nil {
+		return models.SavedSearch{}, fmt.Errorf("failed to decode filters of saved search %s: %w", id, err)
+	}
+	return search, nil
+}
+
+// CountSavedSearches implements SearchStore
+func (q queries) CountSavedSearches(ctx context.Context) (int, error) {
+	var n int
+	if err := q.q.QueryRowContext(ctx, `SELECT COUNT(*) FROM saved_searches`).Scan(&n); err != nil {
+		return 0, fmt.Errorf("failed to count saved searches: %w", err)
+	}
+	return n, nil
+}
+
+// CreateUser implements UserStore
+func (q queries) CreateUser(ctx context.Context, username, passwordHash string, createdAt time.Time) (models.User, error) {
+	return q.createUser(ctx, models.User{Username: username, CreatedAt: createdAt}, passwordHash)
+}
+
+// CreateGuest implements UserStore
+func (q queries) CreateGuest(ctx context.Context, username string, createdAt time.Time) (models.User, error) {
+	return q.createUser(ctx, models.User{Username: username, Guest: true, CreatedAt: createdAt}, "")
+}
+
+// createUser inserts user, returning it with its ID
+func (q queries) createUser(ctx context.Context, user models.User, passwordHash string) (models.User, error) {
+	res, err := q.q.ExecContext(ctx,
+		`INSERT INTO users (username, password_hash, guest, created_at) VALUES (?, ?, ?, ?)
+		 ON CONFLICT (username) DO NOTHING`,
+		user.Username, passwordHash, user.Guest, user.CreatedAt)
+	if err != nil {
+		return models.User{}, fmt.Errorf("failed to create user: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return models.User{}, ErrConflict
+	}
+	if user.ID, err = res.LastInsertId(); err != nil {
+		return models.User{}, fmt.Errorf("failed to create user: %w", err)
+	}
+	return user, nil
+}
+
+// UserByName implements UserStore
+func (q queries) UserByName(ctx context.Context, username string) (models.User, string, error) {
+	var user models.User
+	var hash string
+	err := q.q.QueryRowContext(ctx,
+		`SELECT id, username, guest, password_hash, created_at FROM users WHERE username = ?`, username,
+	).Scan(&user.ID, &user.Username, &user.Guest, &hash, &user.CreatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.User{}, "", ErrNotFound
+	}
+	if err != nil {
+		return models.User{}, "", fmt.Errorf("failed to look up user: %w", err)
+	}
+	return user, hash, nil
+}
+
+// ClaimGuest implements UserStore
+func (q queries) ClaimGuest(ctx context.Context, userID int64, username, passwordHash string) error {
+	res, err := q.q.ExecContext(ctx,
+		`UPDATE OR IGNORE users SET username = ?, password_hash = ?, guest = 0 WHERE id = ? AND guest = 1`,
+		username, passwordHash, userID)
+	if err != nil {
+		return fmt.Errorf("failed to claim guest: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n > 0 {
+		return nil
+	}
+
+	// Nothing changed: either the username is taken or there is no guest
+	var guest bool
+	err = q.q.QueryRowContext(ctx, `SELECT guest FROM users WHERE id = ?`, userID).Scan(&guest)
+	if errors.Is(err, sql.ErrNoRows) || (err == nil && !guest) {
+		return ErrNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("failed to claim guest: %w", err)
+	}
+	return ErrConflict
+}
+
+// DeleteUser implements UserStore
+func (q queries) DeleteUser(ctx context.Context, userID int64) error {
+	if _, err := q.q.ExecContext(ctx, `DELETE FROM users WHERE id = ?`, userID); err != nil {
+		return fmt.Errorf("failed to delete user: %w", err)
+	}
+	return nil
+}
+
+// DeleteStaleGuests implements UserStore
+func (q queries) DeleteStaleGuests(ctx context.Context, now time.Time) error {
+	if _, err := q.q.ExecContext(ctx,
+		`DELETE FROM users WHERE guest = 1 AND NOT EXISTS (
+			SELECT 1 FROM sessions s WHERE s.user_id = users.id AND s.expires_at > ?)`, now); err != nil {
+		return fmt.Errorf("failed to prune guests: %w", err)
+	}
+	return nil
+}
+
+// CreateSession implements UserStore
+func (q queries) CreateSession(ctx context.Context, session Session) error {
+	if _, err := q.q.ExecContext(ctx,
+		`INSERT INTO sessions (token_hash, user_id, csrf_token, created_at, expires_at) VALUES (?, ?, ?, ?, ?)`,
+		session.TokenHash, session.UserID, session.CSRFToken, session.CreatedAt, session.ExpiresAt); err != nil {
+		return fmt.Errorf("failed to create session: %w", err)
+	}
+	return nil
+}
+
+// SessionUser implements UserStore
+func (q queries) SessionUser(ctx context.Context, tokenHash string, now time.Time) (models.User, Session, error) {
+	var user models.User
+	session := Session{TokenHash: tokenHash}
+	err := q.q.QueryRowContext(ctx,
+		`SELECT u.id, u.username, u.guest, u.created_at, s.csrf_token, s.created_at, s.expires_at
+		 FROM sessions s JOIN users u ON u.id = s.user_id
+		 WHERE s.token_hash = ? AND s.expires_at > ?`,
+		tokenHash, now,
+	).Scan(&user.ID, &user.Username, &user.Guest, &user.CreatedAt, &session.CSRFToken, &session.CreatedAt, &session.ExpiresAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.User{}, Session{}, ErrNotFound
+	}
+	if err != nil {
+		return models.User{}, Session{}, fmt.Errorf("failed to look up session: %w", err)
+	}
+	session.UserID = user.ID
+	return user, session, nil
+}
+
+// DeleteSession implements UserStore
+func (q queries) DeleteSession(ctx context.Context, tokenHash string) error {
+	if _, err := q.q.ExecContext(ctx, `DELETE FROM sessions WHERE token_hash = ?`, tokenHash); err != nil {
+		return fmt.Errorf("failed to delete session: %w", err)
+	}
+	return nil
+}
+
+// DeleteExpiredSessions implements UserStore
+func (q queries) DeleteExpiredSessions(ctx context.Context, userID int64, now time.Time) error {
+	if _, err := q.q.ExecContext(ctx,
+		`DELETE FROM sessions WHERE user_id = ? AND expires_at <= ?`, userID, now); err != nil {
+		return fmt.Errorf("failed to prune sessions: %w", err)
+	}
+	return nil
+}
+
+// Favorites implements UserStore
+func (q queries) Favorites(ctx context.Context, userID int64) ([]models.Favorite, error) {
+	rows, err := q.q.QueryContext(ctx,
+		`SELECT artist_id, added_at FROM favorites WHERE user_id = ? ORDER BY added_at, artist_id`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list favorites: %w", err)
+	}
+	defer rows.Close()
+
+	favorites := []models.Favorite{}
+	for rows.Next() {
+		var f models.Favorite
+		if err := rows.Scan(&f.ArtistID, &f.AddedAt); err != nil {
+			return nil, fmt.Errorf("failed to list favorites: %w", err)
+		}
+		favorites = append(favorites, f)
+	}
+	return favorites, rows.Err()
+}
+
+// AddFavorite implements UserStore
+func (q queries) AddFavorite(ctx context.Context, userID int64, artistID int, addedAt time.Time) error {
+	if _, err := q.q.ExecContext(ctx,
+		`INSERT INTO favorites (user_id, artist_id, added_at) VALUES (?, ?, ?)
+		 ON CONFLICT (user_id, artist_id) DO NOTHING`,
+		userID, artistID, addedAt); err != nil {
+		return fmt.Errorf("failed to add favorite: %w", err)
+	}
+	return nil
+}
+
+// RemoveFavorite implements UserStore
+func (q queries) RemoveFavorite(ctx context.Context, userID int64, artistID int) error {
+	if _, err := q.q.ExecContext(ctx,
+		`DELETE FROM favorites WHERE user_id = ? AND artist_id = ?`, userID, artistID); err != nil {
+		return fmt.Errorf("failed to remove favorite: %w", err)
+	}
+	return nil
+}
+
+// Follows implements UserStore
+func (q queries) Follows(ctx context.Context, userID int64) ([]models.Follow, error) {
+	rows, err := q.q.QueryContext(ctx,
+		`SELECT artist_id, followed_at FROM follows WHERE user_id = ? ORDER BY followed_at, artist_id`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list follows: %w", err)
+	}
+	defer rows.Close()
+
+	follows := []models.Follow{}
+	for rows.Next() {
+		var f models.Follow
+		if err := rows.Scan(&f.ArtistID, &f.FollowedAt); err != nil {
+			return nil, fmt.Errorf("failed to list follows: %w", err)
+		}
+		follows = append(follows, f)
+	}
+	return follows, rows.Err()
+}
+
+// AddFollow implements UserStore
+func (q queries) AddFollow(ctx context.Context, userID int64, artistID int, followedAt time.Time) error {
+	if _, err := q.q.ExecContext(ctx,
+		`INSERT INTO follows (user_id, artist_id, followed_at) VALUES (?, ?, ?)
+		 ON CONFLICT (user_id, artist_id) DO NOTHING`,
+		userID, artistID, followedAt); err != nil {
+		return fmt.Errorf("failed to follow artist: %w", err)
+	}
+	return nil
+}
+
+// RemoveFollow implements UserStore
+func (q queries) RemoveFollow(ctx context.Context, userID int64, artistID int) error {
+	if _, err := q.q.ExecContext(ctx,
+		`DELETE FROM follows WHERE user_id = ? AND artist_id = ?`, userID, artistID); err != nil {
+		return fmt.Errorf("failed to unfollow artist: %w", err)
+	}
+	return nil
+}
+
+// Home implements UserStore
+func (q queries) Home(ctx context.Context, userID int64) (models.Home, error) {
+	var home models.Home
+	err := q.q.QueryRowContext(ctx,
+		`SELECT lat, lon, radius_km FROM homes WHERE user_id = ?`, userID,
+	).Scan(&home.Lat, &home.Lon, &home.RadiusKm)
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.Home{}, ErrNotFound
+	}
+	if err != nil {
+		return models.Home{}, fmt.Errorf("failed to look up home: %w", err)
+	}
+	return home, nil
+}
+
+// SetHome implements UserStore
+func (q queries) SetHome(ctx context.Context, userID int64, home models.Home) error {
+	if _, err := q.q.ExecContext(ctx,
+		`INSERT INTO homes (user_id, lat, lon, radius_km) VALUES (?, ?, ?, ?)
+		 ON CONFLICT (user_id) DO UPDATE SET lat = excluded.lat, lon = excluded.lon, radius_km = excluded.radius_km`,
+		userID, home.Lat, home.Lon, home.RadiusKm); err != nil {
+		return fmt.Errorf("failed to set home: %w", err)
+	}
+	return nil
+}
+
+// ClearHome implements UserStore
+func (q queries) ClearHome(ctx context.Context, userID int64) error {
+	if _, err := q.q.ExecContext(ctx, `DELETE FROM homes WHERE user_id = ?`, userID); err != nil {
+		return fmt.Errorf("failed to clear home: %w", err)
+	}
+	return nil
+}
+
+// SetCalendarToken implements UserStore
+func (q queries) SetCalendarToken(ctx context.Context, userID int64, tokenHash string, createdAt time.Time) error {
+	if _, err := q.q.ExecContext(ctx,
+		`INSERT INTO calendar_tokens (user_id, token_hash, created_at) VALUES (?, ?, ?)
+		 ON CONFLICT (user_id) DO UPDATE SET token_hash = excluded.token_hash, created_at = excluded.created_at`,
+		userID, tokenHash, createdAt); err != nil {
+		return fmt.Errorf("failed to create calendar token: %w", err)
+	}
+	return nil
+}
+
+// DeleteCalendarToken implements UserStore
+func (q queries) DeleteCalendarToken(ctx context.Context, userID int64) error {
+	if _, err := q.q.ExecContext(ctx, `DELETE FROM calendar_tokens WHERE user_id = ?`, userID); err != nil {
+		return fmt.Errorf("failed to revoke calendar token: %w", err)
+	}
+	return nil
+}
+
+// CalendarUser implements UserStore
+func (q queries) CalendarUser(ctx context.Context, tokenHash string) (models.User, error) {
+	var user models.User
+	err := q.q.QueryRowContext(ctx,
+		`SELECT u.id, u.username, u.guest, u.created_at
+		 FROM calendar_tokens c JOIN users u ON u.id = c.user_id
+		 WHERE c.token_hash = ?`,
+		tokenHash,
+	).Scan(&user.ID, &user.Username, &user.Guest, &user.CreatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.User{}, ErrNotFound
+	}
+	if err != nil {
+		return models.User{}, fmt.Errorf("failed to look up calendar: %w", err)
+	}
+	return user, nil
+}
+
+// Attended implements UserStore
+func (q queries) Attended(ctx context.Context, userID int64) ([]models.Attendance, error) {
+	rows, err := q.q.QueryContext(ctx,
+		`SELECT artist_id, location, date, rating, notes, added_at FROM attendance
+		 WHERE user_id = ? ORDER BY date, artist_id, location`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list attended concerts: %w", err)
+	}
+	defer rows.Close()
+
+	attended := []models.Attendance{}
+	for rows.Next() {
+		var a models.Attendance
+		if err := rows.Scan(&a.ArtistID, &a.Location, &a.Date, &a.Rating, &a.Notes, &a.AddedAt); err != nil {
+			return nil, fmt.Errorf("failed to list attended concerts: %w", err)
+		}
+		attended = append(attended, a)
+	}
+	return attended, rows.Err()
+}
+
+// PutAttendance implements UserStore
+func (q queries) PutAttendance(ctx context.Context, userID int64, a models.Attendance) (models.Attendance, error) {
+	err := q.q.QueryRowContext(ctx,
+		`INSERT INTO attendance (user_id, artist_id, location, date, rating, notes, added_at)
+		 VALUES (?, ?, ?, ?, ?, ?, ?)
+		 ON CONFLICT (user_id, artist_id, location, date) DO UPDATE SET rating = excluded.rating, notes = excluded.notes
+		 RETURNING added_at`,
+		userID, a.ArtistID, a.Location, a.Date, a.Rating, a.Notes, a.AddedAt,
+	).Scan(&a.AddedAt)
+	if err != nil {
+		return models.Attendance{}, fmt.Errorf("failed to mark concert attended: %w", err)
+	}
+	return a, nil
+}
+
+// DeleteAttendance implements UserStore
+func (q queries) DeleteAttendance(ctx context.Context, userID int64, artistID int, location, date string) error {
+	if _, err := q.q.ExecContext(ctx,
+		`DELETE FROM attendance WHERE user_id = ? AND artist_id = ? AND location = ? AND date = ?`,
+		userID, artistID, location, date); err != nil {
+		return fmt.Errorf("failed to remove attended concert: %w", err)
+	}
+	return nil
+}
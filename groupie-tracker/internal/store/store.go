@@ -0,0 +1,140 @@
+// internal/store/store.go
+package store
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"groupie-tracker/internal/models"
+	"groupie-tracker/internal/snapshot"
+)
+
+var (
+	// ErrNotFound is returned when a looked up record does not exist
+	ErrNotFound = errors.New("not found")
+	// ErrConflict is returned when a record with the same key exists
+	ErrConflict = errors.New("already exists")
+)
+
+// Store persists what outlives a process: geocoding results, snapshots of
+// the dataset and the state of user accounts. Implementations are safe for
+// concurrent use.
+type Store interface {
+	Tx
+	// InTx runs fn in a transaction, committed when fn returns nil and
+	// rolled back otherwise
+	InTx(ctx context.Context, fn func(tx Tx) error) error
+	Close() error
+}
+
+// Tx gives access to every kind of record, either directly on a Store or
+// within a transaction
+type Tx interface {
+	GeocodeStore
+	SnapshotStore
+	AnnouncementStore
+	SearchStore
+	UserStore
+}
+
+// GeocodeStore keeps geocoding results by address
+type GeocodeStore interface {
+	Geocodes(ctx context.Context) (map[string]models.GeoLocation, error)
+	PutGeocode(ctx context.Context, address string, loc models.GeoLocation) error
+}
+
+// SnapshotStore keeps the latest snapshot of the upstream dataset
+type SnapshotStore interface {
+	// SaveSnapshot replaces the stored snapshot
+	SaveSnapshot(ctx context.Context, s snapshot.Snapshot) error
+	// LatestSnapshot returns ErrNotFound until a snapshot is saved
+	LatestSnapshot(ctx context.Context) (snapshot.Snapshot, error)
+}
+
+// AnnouncementStore keeps the concerts announced by cache refreshes
+type AnnouncementStore interface {
+	// Announcements returns at most limit announcements, newest first
+	Announcements(ctx context.Context, limit int) ([]models.Announcement, error)
+	// AddAnnouncements stores announcements, ordered newest first, ahead of
+	// the earlier ones and keeps only the newest limit
+	AddAnnouncements(ctx context.Context, found []models.Announcement, limit int) error
+}
+
+// SearchStore keeps saved searches by ID
+type SearchStore interface {
+	// PutSavedSearch returns ErrConflict when the ID is taken
+	PutSavedSearch(ctx context.Context, search models.SavedSearch) error
+	SavedSearch(ctx context.Context, id string) (models.SavedSearch, error)
+	CountSavedSearches(ctx context.Context) (int, error)
+}
+
+// UserStore keeps local accounts and everything they save. Lists are
+// sorted oldest first, attended concerts by date.
+type UserStore interface {
+	// CreateUser returns ErrConflict when the username is taken
+	CreateUser(ctx context.Context, username, passwordHash string, createdAt time.Time) (models.User, error)
+	// UserByName returns a user with their password hash
+	UserByName(ctx context.Context, username string) (models.User, string, error)
+	// CreateGuest creates a user without a password; it returns ErrConflict
+	// when the username is taken
+	CreateGuest(ctx context.Context, username string, createdAt time.Time) (models.User, error)
+	// ClaimGuest turns a guest into an account, keeping what it saved. It
+	// returns ErrConflict when the username is taken and ErrNotFound when
+	// userID is not a guest.
+	ClaimGuest(ctx context.Context, userID int64, username, passwordHash string) error
+	// DeleteUser drops a user with their sessions and everything they saved
+	DeleteUser(ctx context.Context, userID int64) error
+	// DeleteStaleGuests drops the guests without a session valid at now
+	DeleteStaleGuests(ctx context.Context, now time.Time) error
+
+	CreateSession(ctx context.Context, session Session) error
+	// SessionUser looks up a session by token hash, unless it expired
+	// before now
+	SessionUser(ctx context.Context, tokenHash string, now time.Time) (models.User, Session, error)
+	DeleteSession(ctx context.Context, tokenHash string) error
+	// DeleteExpiredSessions drops the sessions of a user expired before now
+	DeleteExpiredSessions(ctx context.Context, userID int64, now time.Time) error
+
+	Favorites(ctx context.Context, userID int64) ([]models.Favorite, error)
+	// AddFavorite keeps the original date of favorites already added
+	AddFavorite(ctx context.Context, userID int64, artistID int, addedAt time.Time) error
+	RemoveFavorite(ctx context.Context, userID int64, artistID int) error
+
+	Follows(ctx context.Context, userID int64) ([]models.Follow, error)
+	// AddFollow keeps the original date of artists already followed
+	AddFollow(ctx context.Context, userID int64, artistID int, followedAt time.Time) error
+	RemoveFollow(ctx context.Context, userID int64, artistID int) error
+
+	Home(ctx context.Context, userID int64) (models.Home, error)
+	SetHome(ctx context.Context, userID int64, home models.Home) error
+	ClearHome(ctx context.Context, userID int64) error
+
+	// SetCalendarToken replaces the calendar token of a user
+	SetCalendarToken(ctx context.Context, userID int64, tokenHash string, createdAt time.Time) error
+	DeleteCalendarToken(ctx context.Context, userID int64) error
+	CalendarUser(ctx context.Context, tokenHash string) (models.User, error)
+
+	Attended(ctx context.Context, userID int64) ([]models.Attendance, error)
+	// PutAttendance replaces the rating and notes of a concert already
+	// attended, keeping its original date, and returns the stored entry
+	PutAttendance(ctx context.Context, userID int64, a models.Attendance) (models.Attendance, error)
+	DeleteAttendance(ctx context.Context, userID int64, artistID int, location, date string) error
+}
+
+// Session is a stored session; only the hash of its token is kept
+type Session struct {
+	TokenHash string
+	UserID    int64
+	CSRFToken string
+	CreatedAt time.Time
+	ExpiresAt time.Time
+}
+
+// Open opens the SQLite database at path, or a Memory store for ":memory:"
+func Open(ctx context.Context, path string) (Store, error) {
+	if path == ":memory:" {
+		return NewMemory(), nil
+	}
+	return OpenSQLite(ctx, path)
+}
@@ -0,0 +1,459 @@
+// internal/store/store_test.go
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"groupie-tracker/internal/models"
+	"groupie-tracker/internal/snapshot"
+)
+
+// base is a fixed instant; SQLite keeps timestamps to the nanosecond in UTC
+var base = time.Date(2026, 3, 14, 15, 9, 26, 0, time.UTC)
+
+// eachStore runs test against an empty store of each implementation
+func eachStore(t *testing.T, test func(t *testing.T, st Store)) {
+	t.Run("memory", func(t *testing.T) {
+		test(t, NewMemory())
+	})
+	t.Run("sqlite", func(t *testing.T) {
+		st, err := OpenSQLite(context.Background(), filepath.Join(t.TempDir(), "test.db"))
+		if err != nil {
+			t.Fatalf("OpenSQLite: %v", err)
+		}
+		t.Cleanup(func() { st.Close() })
+		test(t, st)
+	})
+}
+
+func TestGeocodes(t *testing.T) {
+	eachStore(t, func(t *testing.T, st Store) {
+		ctx := context.Background()
+		paris := models.GeoLocation{Address: "paris-france", Lat: 48.85, Lon: 2.35}
+		if err := st.PutGeocode(ctx, paris.Address, paris); err != nil {
+			t.Fatalf("PutGeocode: %v", err)
+		}
+		paris.Lat = 48.86
+		if err := st.PutGeocode(ctx, paris.Address, paris); err != nil {
+			t.Fatalf("PutGeocode again: %v", err)
+		}
+
+		geocodes, err := st.Geocodes(ctx)
+		if err != nil {
+			t.Fatalf("Geocodes: %v", err)
+		}
+		if len(geocodes) != 1 || geocodes[paris.Address] != paris {
+			t.Errorf("Geocodes = %v, want only %v", geocodes, paris)
+		}
+	})
+}
+
+func TestSnapshots(t *testing.T) {
+	eachStore(t, func(t *testing.T, st Store) {
+		ctx := context.Background()
+		if _, err := st.LatestSnapshot(ctx); !errors.Is(err, ErrNotFound) {
+			t.Fatalf("LatestSnapshot of an empty store: err = %v, want ErrNotFound", err)
+		}
+
+		for _, source := range []string{"first", "second"} {
+			data := models.Datas{ArtistsData: []models.Artist{{ID: 1, Name: source}}}
+			if err := st.SaveSnapshot(ctx, snapshot.New(data, nil, source, base)); err != nil {
+				t.Fatalf("SaveSnapshot(%s): %v", source, err)
+			}
+		}
+
+		latest, err := st.LatestSnapshot(ctx)
+		if err != nil {
+			t.Fatalf("LatestSnapshot: %v", err)
+		}
+		if latest.Meta.Source != "second" || len(latest.Data.ArtistsData) != 1 || latest.Data.ArtistsData[0].Name != "second" {
+			t.Errorf("LatestSnapshot = %+v, want the second snapshot", latest.Meta)
+		}
+		if !latest.Meta.FetchedAt.Equal(base) {
+			t.Errorf("FetchedAt = %v, want %v", latest.Meta.FetchedAt, base)
+		}
+	})
+}
+
+func TestAnnouncements(t *testing.T) {
+	announcement := func(date string) models.Announcement {
+		return models.Announcement{ArtistID: 1, Artist: "Queen", Location: "paris-france", Date: date, FoundAt: base}
+	}
+	dates := func(found []models.Announcement) []string {
+		var dates []string
+		for _, a := range found {
+			dates = append(dates, a.Date)
+		}
+		return dates
+	}
+
+	eachStore(t, func(t *testing.T, st Store) {
+		ctx := context.Background()
+		steps := []struct {
+			found []models.Announcement
+			limit int
+			want  []string
+		}{
+			{[]models.Announcement{announcement("02"), announcement("01")}, 10, []string{"02", "01"}},
+			{[]models.Announcement{announcement("03")}, 10, []string{"03", "02", "01"}},
+			{[]models.Announcement{announcement("05"), announcement("04")}, 3, []string{"05", "04", "03"}},
+		}
+		for i, step := range steps {
+			if err := st.AddAnnouncements(ctx, step.found, step.limit); err != nil {
+				t.Fatalf("step %d: AddAnnouncements: %v", i, err)
+			}
+			found, err := st.Announcements(ctx, 10)
+			if err != nil {
+				t.Fatalf("step %d: Announcements: %v", i, err)
+			}
+			if got := dates(found); !slices.Equal(got, step.want) {
+				t.Errorf("step %d: Announcements = %v, want %v", i, got, step.want)
+			}
+		}
+
+		found, err := st.Announcements(ctx, 2)
+		if err != nil {
+			t.Fatalf("Announcements(2): %v", err)
+		}
+		if got := dates(found); !slices.Equal(got, []string{"05", "04"}) {
+			t.Errorf("Announcements(2) = %v, want [05 04]", got)
+		}
+	})
+}
+
+func TestSavedSearches(t *testing.T) {
+	eachStore(t, func(t *testing.T, st Store) {
+		ctx := context.Background()
+		search := models.SavedSearch{ID: "abc", Query: "queen", CreatedAt: base}
+		search.Filters.CreationYearMin = 1970
+
+		if err := st.PutSavedSearch(ctx, search); err != nil {
+			t.Fatalf("PutSavedSearch: %v", err)
+		}
+		if err := st.PutSavedSearch(ctx, search); !errors.Is(err, ErrConflict) {
+			t.Errorf("PutSavedSearch with a taken ID: err = %v, want ErrConflict", err)
+		}
+
+		got, err := st.SavedSearch(ctx, "abc")
+		if err != nil {
+			t.Fatalf("SavedSearch: %v", err)
+		}
+		if got.Query != search.Query || got.Filters.CreationYearMin != 1970 || !got.CreatedAt.Equal(base) {
+			t.Errorf("SavedSearch = %+v, want %+v", got, search)
+		}
+		if _, err := st.SavedSearch(ctx, "missing"); !errors.Is(err, ErrNotFound) {
+			t.Errorf("SavedSearch(missing): err = %v, want ErrNotFound", err)
+		}
+		if n, err := st.CountSavedSearches(ctx); err != nil || n != 1 {
+			t.Errorf("CountSavedSearches = %d, %v; want 1", n, err)
+		}
+	})
+}
+
+func TestUsersAndSessions(t *testing.T) {
+	eachStore(t, func(t *testing.T, st Store) {
+		ctx := context.Background()
+		user, err := st.CreateUser(ctx, "alice", "hash", base)
+		if err != nil {
+			t.Fatalf("CreateUser: %v", err)
+		}
+		if _, err := st.CreateUser(ctx, "alice", "other", base); !errors.Is(err, ErrConflict) {
+			t.Errorf("CreateUser with a taken name: err = %v, want ErrConflict", err)
+		}
+
+		found, hash, err := st.UserByName(ctx, "alice")
+		if err != nil || found.ID != user.ID || hash != "hash" || found.Guest {
+			t.Errorf("UserByName = %+v, %q, %v; want %+v, hash", found, hash, err, user)
+		}
+		if _, _, err := st.UserByName(ctx, "bob"); !errors.Is(err, ErrNotFound) {
+			t.Errorf("UserByName(bob): err = %v, want ErrNotFound", err)
+		}
+
+		live := Session{TokenHash: "live", UserID: user.ID, CSRFToken: "csrf", CreatedAt: base, ExpiresAt: base.Add(time.Hour)}
+		expired := Session{TokenHash: "expired", UserID: user.ID, CSRFToken: "old", CreatedAt: base, ExpiresAt: base.Add(-time.Hour)}
+		for _, session := range []Session{live, expired} {
+			if err := st.CreateSession(ctx, session); err != nil {
+				t.Fatalf("CreateSession(%s): %v", session.TokenHash, err)
+			}
+		}
+
+		got, session, err := st.SessionUser(ctx, "live", base)
+		if err != nil || got.ID != user.ID || session.CSRFToken != "csrf" {
+			t.Errorf("SessionUser(live) = %+v, %+v, %v", got, session, err)
+		}
+		if _, _, err := st.SessionUser(ctx, "expired", base); !errors.Is(err, ErrNotFound) {
+			t.Errorf("SessionUser(expired): err = %v, want ErrNotFound", err)
+		}
+
+		// Pruned sessions are gone even when looked up at an earlier time
+		if err := st.DeleteExpiredSessions(ctx, user.ID, base); err != nil {
+			t.Fatalf("DeleteExpiredSessions: %v", err)
+		}
+		if _, _, err := st.SessionUser(ctx, "expired", base.Add(-2*time.Hour)); !errors.Is(err, ErrNotFound) {
+			t.Errorf("SessionUser of a pruned session: err = %v, want ErrNotFound", err)
+		}
+		if err := st.DeleteSession(ctx, "live"); err != nil {
+			t.Fatalf("DeleteSession: %v", err)
+		}
+		if _, _, err := st.SessionUser(ctx, "live", base); !errors.Is(err, ErrNotFound) {
+			t.Errorf("SessionUser of a deleted session: err = %v, want ErrNotFound", err)
+		}
+	})
+}
+
+func TestUserLists(t *testing.T) {
+	eachStore(t, func(t *testing.T, st Store) {
+		ctx := context.Background()
+		user, err := st.CreateUser(ctx, "alice", "hash", base)
+		if err != nil {
+			t.Fatalf("CreateUser: %v", err)
+		}
+
+		// Adding again keeps the original date; lists are oldest first
+		for _, add := range []struct {
+			artistID int
+			at       time.Time
+		}{{2, base.Add(time.Hour)}, {1, base}, {2, base.Add(2 * time.Hour)}} {
+			if err := st.AddFavorite(ctx, user.ID, add.artistID, add.at); err != nil {
+				t.Fatalf("AddFavorite: %v", err)
+			}
+			if err := st.AddFollow(ctx, user.ID, add.artistID, add.at); err != nil {
+				t.Fatalf("AddFollow: %v", err)
+			}
+		}
+		favorites, err := st.Favorites(ctx, user.ID)
+		if err != nil || len(favorites) != 2 || favorites[0].ArtistID != 1 || !favorites[1].AddedAt.Equal(base.Add(time.Hour)) {
+			t.Errorf("Favorites = %+v, %v", favorites, err)
+		}
+		follows, err := st.Follows(ctx, user.ID)
+		if err != nil || len(follows) != 2 || follows[0].ArtistID != 1 || !follows[1].FollowedAt.Equal(base.Add(time.Hour)) {
+			t.Errorf("Follows = %+v, %v", follows, err)
+		}
+		if err := st.RemoveFavorite(ctx, user.ID, 1); err != nil {
+			t.Fatalf("RemoveFavorite: %v", err)
+		}
+		if err := st.RemoveFollow(ctx, user.ID, 2); err != nil {
+			t.Fatalf("RemoveFollow: %v", err)
+		}
+		if favorites, _ := st.Favorites(ctx, user.ID); len(favorites) != 1 || favorites[0].ArtistID != 2 {
+			t.Errorf("Favorites after removal = %+v", favorites)
+		}
+		if follows, _ := st.Follows(ctx, user.ID); len(follows) != 1 || follows[0].ArtistID != 1 {
+			t.Errorf("Follows after removal = %+v", follows)
+		}
+
+		if _, err := st.Home(ctx, user.ID); !errors.Is(err, ErrNotFound) {
+			t.Errorf("Home before SetHome: err = %v, want ErrNotFound", err)
+		}
+		home := models.Home{GeoPoint: models.GeoPoint{Lat: 1, Lon: 2}, RadiusKm: 50}
+		if err := st.SetHome(ctx, user.ID, home); err != nil {
+			t.Fatalf("SetHome: %v", err)
+		}
+		if got, err := st.Home(ctx, user.ID); err != nil || got != home {
+			t.Errorf("Home = %+v, %v; want %+v", got, err, home)
+		}
+		if err := st.ClearHome(ctx, user.ID); err != nil {
+			t.Fatalf("ClearHome: %v", err)
+		}
+		if _, err := st.Home(ctx, user.ID); !errors.Is(err, ErrNotFound) {
+			t.Errorf("Home after ClearHome: err = %v, want ErrNotFound", err)
+		}
+
+		if err := st.SetCalendarToken(ctx, user.ID, "first", base); err != nil {
+			t.Fatalf("SetCalendarToken: %v", err)
+		}
+		if err := st.SetCalendarToken(ctx, user.ID, "second", base); err != nil {
+			t.Fatalf("SetCalendarToken again: %v", err)
+		}
+		if _, err := st.CalendarUser(ctx, "first"); !errors.Is(err, ErrNotFound) {
+			t.Errorf("CalendarUser of a replaced token: err = %v, want ErrNotFound", err)
+		}
+		if got, err := st.CalendarUser(ctx, "second"); err != nil || got.ID != user.ID {
+			t.Errorf("CalendarUser = %+v, %v", got, err)
+		}
+		if err := st.DeleteCalendarToken(ctx, user.ID); err != nil {
+			t.Fatalf("DeleteCalendarToken: %v", err)
+		}
+		if _, err := st.CalendarUser(ctx, "second"); !errors.Is(err, ErrNotFound) {
+			t.Errorf("CalendarUser of a deleted token: err = %v, want ErrNotFound", err)
+		}
+	})
+}
+
+func TestAttendance(t *testing.T) {
+	eachStore(t, func(t *testing.T, st Store) {
+		ctx := context.Background()
+		user, err := st.CreateUser(ctx, "alice", "hash", base)
+		if err != nil {
+			t.Fatalf("CreateUser: %v", err)
+		}
+
+		later := models.Attendance{ArtistID: 1, Location: "paris-france", Date: "2020-05-02", Rating: 3, AddedAt: base}
+		earlier := models.Attendance{ArtistID: 2, Location: "lyon-france", Date: "2019-01-01", AddedAt: base}
+		for _, a := range []models.Attendance{later, earlier} {
+			if _, err := st.PutAttendance(ctx, user.ID, a); err != nil {
+				t.Fatalf("PutAttendance: %v", err)
+			}
+		}
+
+		// Replacing keeps the original date
+		later.Rating, later.Notes, later.AddedAt = 5, "encore", base.Add(time.Hour)
+		stored, err := st.PutAttendance(ctx, user.ID, later)
+		if err != nil {
+			t.Fatalf("PutAttendance again: %v", err)
+		}
+		if stored.Rating != 5 || stored.Notes != "encore" || !stored.AddedAt.Equal(base) {
+			t.Errorf("PutAttendance = %+v, want rating 5, notes and the original date", stored)
+		}
+
+		attended, err := st.Attended(ctx, user.ID)
+		if err != nil || len(attended) != 2 || attended[0].Date != earlier.Date || attended[1].Rating != 5 {
+			t.Errorf("Attended = %+v, %v; want sorted by date", attended, err)
+		}
+
+		if err := st.DeleteAttendance(ctx, user.ID, 2, "lyon-france", "2019-01-01"); err != nil {
+			t.Fatalf("DeleteAttendance: %v", err)
+		}
+		if attended, _ := st.Attended(ctx, user.ID); len(attended) != 1 {
+			t.Errorf("Attended after deletion = %+v", attended)
+		}
+	})
+}
+
+func TestGuests(t *testing.T) {
+	eachStore(t, func(t *testing.T, st Store) {
+		ctx := context.Background()
+		account, err := st.CreateUser(ctx, "alice", "hash", base)
+		if err != nil {
+			t.Fatalf("CreateUser: %v", err)
+		}
+		guest, err := st.CreateGuest(ctx, "guest-1", base)
+		if err != nil || !guest.Guest {
+			t.Fatalf("CreateGuest = %+v, %v", guest, err)
+		}
+		stale, err := st.CreateGuest(ctx, "guest-2", base)
+		if err != nil {
+			t.Fatalf("CreateGuest: %v", err)
+		}
+		if err := st.CreateSession(ctx, Session{TokenHash: "guest", UserID: guest.ID, CreatedAt: base, ExpiresAt: base.Add(time.Hour)}); err != nil {
+			t.Fatalf("CreateSession: %v", err)
+		}
+		if err := st.AddFollow(ctx, stale.ID, 1, base); err != nil {
+			t.Fatalf("AddFollow: %v", err)
+		}
+
+		if err := st.DeleteStaleGuests(ctx, base); err != nil {
+			t.Fatalf("DeleteStaleGuests: %v", err)
+		}
+		if _, _, err := st.UserByName(ctx, "guest-2"); !errors.Is(err, ErrNotFound) {
+			t.Errorf("guest without a session: err = %v, want ErrNotFound", err)
+		}
+		if follows, _ := st.Follows(ctx, stale.ID); len(follows) != 0 {
+			t.Errorf("follows of a deleted guest = %+v", follows)
+		}
+		for _, name := range []string{"alice", "guest-1"} {
+			if _, _, err := st.UserByName(ctx, name); err != nil {
+				t.Errorf("UserByName(%s) after DeleteStaleGuests: %v", name, err)
+			}
+		}
+
+		if err := st.ClaimGuest(ctx, account.ID, "carol", "hash"); !errors.Is(err, ErrNotFound) {
+			t.Errorf("ClaimGuest of an account: err = %v, want ErrNotFound", err)
+		}
+		if err := st.ClaimGuest(ctx, guest.ID, "alice", "hash"); !errors.Is(err, ErrConflict) {
+			t.Errorf("ClaimGuest with a taken name: err = %v, want ErrConflict", err)
+		}
+		if err := st.ClaimGuest(ctx, guest.ID, "bob", "bob-hash"); err != nil {
+			t.Fatalf("ClaimGuest: %v", err)
+		}
+		bob, hash, err := st.UserByName(ctx, "bob")
+		if err != nil || bob.ID != guest.ID || bob.Guest || hash != "bob-hash" {
+			t.Errorf("claimed guest = %+v, %q, %v", bob, hash, err)
+		}
+		if got, _, err := st.SessionUser(ctx, "guest", base); err != nil || got.Username != "bob" {
+			t.Errorf("session of the claimed guest = %+v, %v", got, err)
+		}
+
+		if err := st.DeleteUser(ctx, bob.ID); err != nil {
+			t.Fatalf("DeleteUser: %v", err)
+		}
+		if _, _, err := st.SessionUser(ctx, "guest", base); !errors.Is(err, ErrNotFound) {
+			t.Errorf("session of a deleted user: err = %v, want ErrNotFound", err)
+		}
+	})
+}
+
+func TestInTx(t *testing.T) {
+	eachStore(t, func(t *testing.T, st Store) {
+		ctx := context.Background()
+		failure := errors.New("failure")
+		err := st.InTx(ctx, func(tx Tx) error {
+			if err := tx.PutGeocode(ctx, "rolled-back", models.GeoLocation{Address: "rolled-back"}); err != nil {
+				return err
+			}
+			return failure
+		})
+		if !errors.Is(err, failure) {
+			t.Errorf("InTx = %v, want the error of fn", err)
+		}
+
+		err = st.InTx(ctx, func(tx Tx) error {
+			return tx.PutGeocode(ctx, "committed", models.GeoLocation{Address: "committed"})
+		})
+		if err != nil {
+			t.Fatalf("InTx: %v", err)
+		}
+
+		geocodes, err := st.Geocodes(ctx)
+		if err != nil {
+			t.Fatalf("Geocodes: %v", err)
+		}
+		if _, ok := geocodes["rolled-back"]; ok {
+			t.Error("a rolled back write was kept")
+		}
+		if _, ok := geocodes["committed"]; !ok {
+			t.Error("a committed write was lost")
+		}
+	})
+}
+
+func TestMigrate(t *testing.T) {
+	ctx := context.Background()
+	db, err := sql.Open("sqlite", filepath.Join(t.TempDir(), "empty.db"))
+	if err != nil {
+		t.Fatalf("sql.Open: %v", err)
+	}
+	defer db.Close()
+
+	version, err := Migrate(ctx, db, Migrations)
+	if err != nil || version != len(Migrations) {
+		t.Fatalf("Migrate of an empty database = %d, %v; want %d", version, err, len(Migrations))
+	}
+	if version, err = Migrate(ctx, db, Migrations); err != nil || version != len(Migrations) {
+		t.Errorf("Migrate of a migrated database = %d, %v; want %d", version, err, len(Migrations))
+	}
+
+	// Every table the queries use exists
+	for _, table := range []string{"users", "sessions", "favorites", "follows", "homes", "calendar_tokens",
+		"attendance", "geocodes", "snapshots", "saved_searches", "announcements"} {
+		var name string
+		if err := db.QueryRowContext(ctx,
+			`SELECT name FROM sqlite_master WHERE type = 'table' AND name = ?`, table).Scan(&name); err != nil {
+			t.Errorf("table %s: %v", table, err)
+		}
+	}
+
+	if _, err := Migrate(ctx, db, Migrations[:len(Migrations)-1]); !errors.Is(err, ErrSchemaTooNew) {
+		t.Errorf("Migrate with fewer migrations: err = %v, want ErrSchemaTooNew", err)
+	}
+	gap := []Migration{{1, "first", ""}, {3, "third", ""}}
+	if _, err := Migrate(ctx, db, gap); err == nil {
+		t.Error("Migrate accepted migrations with a gap in their versions")
+	}
+}
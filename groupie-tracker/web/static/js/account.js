@@ -0,0 +1,68 @@
+// web/static/js/account.js
+
+// Sign-in and registration; favorites kept in localStorage while signed out
+// are sent along and merged into the account
+const elements = {
+    form: document.getElementById('account-form'),
+    title: document.getElementById('account-title'),
+    username: document.getElementById('username'),
+    password: document.getElementById('password'),
+    submit: document.getElementById('account-submit'),
+    switchText: document.getElementById('account-switch-text'),
+    switchLink: document.getElementById('account-switch'),
+    errorMessage: document.getElementById('error-message')
+};
+
+let registering = false;
+
+function showError(message) {
+    elements.errorMessage.textContent = message;
+    elements.errorMessage.style.display = 'block';
+    setTimeout(() => {
+        elements.errorMessage.style.display = 'none';
+    }, 5000);
+}
+
+function setMode(register) {
+    registering = register;
+    const label = register ? 'Register' : 'Sign In';
+    elements.title.textContent = label;
+    elements.submit.textContent = label;
+    elements.password.autocomplete = register ? 'new-password' : 'current-password';
+    elements.switchText.textContent = register ? 'Already registered?' : 'No account yet?';
+    elements.switchLink.textContent = register ? 'Sign In' : 'Register';
+}
+
+async function submit(event) {
+    event.preventDefault();
+    const favorites = JSON.parse(localStorage.getItem('favorites')) || [];
+
+    try {
+        const response = await fetch(registering ? '/api/auth/register' : '/api/auth/login', {
+            method: 'POST',
+            headers: { 'Content-Type': 'application/json' },
+            body: JSON.stringify({
+                username: elements.username.value,
+                password: elements.password.value,
+                favorites
+            })
+        });
+        if (!response.ok) {
+            const error = await response.json().catch(() => ({}));
+            throw new Error(error.message || 'Failed to sign in');
+        }
+
+        // The account now holds the local favorites
+        localStorage.removeItem('favorites');
+        const next = new URLSearchParams(window.location.search).get('next');
+        window.location.href = next && next.startsWith('/') && !next.startsWith('//') ? next : '/';
+    } catch (error) {
+        showError(error.message);
+    }
+}
+
+elements.form.addEventListener('submit', submit);
+elements.switchLink.addEventListener('click', (event) => {
+    event.preventDefault();
+    setMode(!registering);
+});
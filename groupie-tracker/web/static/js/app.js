@@ -5,13 +5,36 @@ mapboxgl.accessToken = MAPBOX_TOKEN;
 
 // Constants and API
 const API = {
-    async search(query, filters) {
-        const response = await fetch(`/api/search?q=${encodeURIComponent(query)}`, {
+    // params holds q and the filters in the URL form /api/search accepts
+    async search(params) {
+        const response = await fetch(`/api/search?${params}`, { method: 'POST' });
+        if (!response.ok) throw new Error('Failed to search artists');
+        return response.json();
+    },
+
+    async saveSearch(params) {
+        const response = await fetch(`/api/searches?${params}`, { method: 'POST' });
+        if (!response.ok) throw new Error('Failed to save search');
+        return response.json();
+    },
+
+    async getAccount() {
+        const response = await fetch('/api/me');
+        return response.ok ? response.json() : null;
+    },
+
+    async logout(csrfToken) {
+        const response = await fetch('/api/auth/logout', {
             method: 'POST',
-            headers: { 'Content-Type': 'application/json' },
-            body: JSON.stringify(filters)
+            headers: { 'X-CSRF-Token': csrfToken }
         });
-        if (!response.ok) throw new Error('Failed to search artists');
+        if (!response.ok) throw new Error('Failed to sign out');
+    },
+
+    // Year ranges, member counts and locations the filters accept
+    async getFilterMeta() {
+        const response = await fetch('/api/filters/meta');
+        if (!response.ok) throw new Error('Failed to get filter metadata');
         return response.json();
     },
 
@@ -26,46 +49,65 @@ const API = {
 class FilterManager {
     constructor(elements) {
         this.elements = elements;
-        this.currentYear = new Date().getFullYear();
+
+        // Filters in the page URL without a control here, such as
+        // countries or near, are kept as they are
+        this.extra = new URLSearchParams(window.location.search);
+        FilterManager.CONTROLLED.forEach(name => this.extra.delete(name));
     }
 
-    getValues() {
-        return {
-            creationYearMin: parseInt(this.elements.creationYearSlider.value),
-            creationYearMax: this.currentYear,
-            firstAlbumYearMin: parseInt(this.elements.firstAlbumYearSlider.value),
-            firstAlbumYearMax: this.currentYear,
-            members: Array.from(this.elements.memberCheckboxes.querySelectorAll('input:checked'))
-                .map(cb => parseInt(cb.value)),
-            locations: Array.from(this.elements.locationCheckboxes.querySelectorAll('input:checked'))
-                .map(cb => cb.value)
-        };
+    // Query parameters set by the filter controls
+    static CONTROLLED = ['q', 'creationYearMin', 'firstAlbumYearMin', 'members', 'locations'];
+
+    // Encode the search text and filter state as URL query parameters,
+    // leaving out filters at their defaults
+    toParams(query) {
+        const params = new URLSearchParams(this.extra);
+        if (query) params.set('q', query);
+
+        const { creationYearSlider, firstAlbumYearSlider } = this.elements;
+        if (parseInt(creationYearSlider.value) > parseInt(creationYearSlider.min)) {
+            params.set('creationYearMin', creationYearSlider.value);
+        }
+        if (parseInt(firstAlbumYearSlider.value) > parseInt(firstAlbumYearSlider.min)) {
+            params.set('firstAlbumYearMin', firstAlbumYearSlider.value);
+        }
+
+        const members = this.checkedValues(this.elements.memberCheckboxes);
+        if (members.length) params.set('members', members.join(','));
+        const locations = this.checkedValues(this.elements.locationCheckboxes);
+        if (locations.length) params.set('locations', locations.join(','));
+        return params;
     }
 
-    initialize(data) {
-        if (!data || !data.length) return;
+    checkedValues(container) {
+        return Array.from(container.querySelectorAll('input:checked')).map(cb => cb.value);
+    }
 
-        // Setup year sliders
-        const creationYears = data.map(artist => artist.creationDate);
-        const albumYears = data.map(artist => parseInt(artist.firstAlbum.split('-')[2]));
+    // Attach listeners to filters rendered by the server
+    attach() {
+        this.addFilterChangeListeners(this.elements.memberCheckboxes);
+        this.addFilterChangeListeners(this.elements.locationCheckboxes);
+    }
 
+    // Build the filter controls from /api/filters/meta
+    initialize(meta) {
         this.setupYearSlider(
             this.elements.creationYearSlider,
             this.elements.creationYearDisplay,
-            Math.min(...creationYears),
-            Math.max(...creationYears)
+            meta.creationYear.min,
+            meta.creationYear.max
         );
 
         this.setupYearSlider(
             this.elements.firstAlbumYearSlider,
             this.elements.firstAlbumYearDisplay,
-            Math.min(...albumYears),
-            Math.max(...albumYears)
+            meta.firstAlbumYear.min,
+            meta.firstAlbumYear.max
         );
 
-        // Setup filters
-        this.setupMemberCheckboxes(data);
-        this.setupLocationCheckboxes(data);
+        this.setupMemberCheckboxes(meta.memberCounts);
+        this.setupLocationCheckboxes(meta.locations);
     }
 
     setupYearSlider(slider, display, min, max) {
@@ -75,43 +117,60 @@ class FilterManager {
         display.textContent = min;
     }
 
-    setupMemberCheckboxes(artists) {
-        const maxMembers = Math.max(...artists.map(artist => artist.members.length));
+    setupMemberCheckboxes(memberCounts) {
         this.elements.memberCheckboxes.innerHTML = '';
-        
-        for (let i = 1; i <= maxMembers; i++) {
-            const label = document.createElement('label');
-            label.innerHTML = `<input type="checkbox" value="${i}"> ${i}`;
-            this.elements.memberCheckboxes.appendChild(label);
-        }
-        
+        memberCounts.forEach(count => {
+            this.elements.memberCheckboxes.appendChild(this.checkbox('members', count, count));
+        });
         this.addFilterChangeListeners(this.elements.memberCheckboxes);
     }
 
-    setupLocationCheckboxes(artists) {
-        const locationSet = new Set();
-
-        // Get unique locations from the LocationsData
-        artists.forEach(artist => {
-            if (artist.locations && Array.isArray(artist.locations)) {
-                artist.locations.forEach(location => {
-                    const city = location.split('-')[0].trim();
-                    if (city) locationSet.add(city);
-                });
-            }
-        });
-
-        // Create checkboxes
+    // Locations come sorted by name, filtering by slug like the server
+    // rendered checkboxes
+    setupLocationCheckboxes(locations) {
         this.elements.locationCheckboxes.innerHTML = '';
-        Array.from(locationSet).sort().forEach(location => {
-            const label = document.createElement('label');
-            label.innerHTML = `<input type="checkbox" value="${location}"> ${location}`;
-            this.elements.locationCheckboxes.appendChild(label);
+        locations.forEach(place => {
+            this.elements.locationCheckboxes.appendChild(this.checkbox('locations', place.slug, place.name));
         });
-
         this.addFilterChangeListeners(this.elements.locationCheckboxes);
     }
 
+    // Checkboxes are named after their query parameter, like the server
+    // rendered ones the search form submits without JavaScript
+    checkbox(name, value, text) {
+        const label = document.createElement('label');
+        const input = document.createElement('input');
+        input.type = 'checkbox';
+        input.name = name;
+        input.value = value;
+        label.append(input, ` ${text}`);
+        return label;
+    }
+
+    // Show how many of the current results match each checkbox, dimming
+    // choices that match none
+    applyFacets(facets) {
+        if (!facets) return;
+        this.applyFacetCounts(this.elements.memberCheckboxes, facets.members);
+        this.applyFacetCounts(this.elements.locationCheckboxes, facets.locations);
+    }
+
+    applyFacetCounts(container, counts) {
+        const byValue = new Map((counts || []).map(facet => [facet.value, facet.count]));
+        container.querySelectorAll('label').forEach(label => {
+            const input = label.querySelector('input');
+            const count = byValue.get(input.value) || 0;
+            let badge = label.querySelector('.facet-count');
+            if (!badge) {
+                badge = document.createElement('span');
+                badge.className = 'facet-count';
+                label.appendChild(badge);
+            }
+            badge.textContent = `(${count})`;
+            label.classList.toggle('facet-empty', count === 0 && !input.checked);
+        });
+    }
+
     addFilterChangeListeners(container) {
         container.querySelectorAll('input').forEach(input => {
             input.addEventListener('change', () => this.onFilterChange());
@@ -127,6 +186,8 @@ class FilterManager {
 class App {
     constructor() {
         this.elements = {
+            searchForm: document.getElementById('search-form'),
+            searchSubmit: document.getElementById('search-submit'),
             searchInput: document.getElementById('search-input'),
             suggestionsContainer: document.getElementById('suggestions'),
             creationYearSlider: document.getElementById('creation-year'),
@@ -135,6 +196,11 @@ class App {
             firstAlbumYearDisplay: document.getElementById('first-album-year-display'),
             memberCheckboxes: document.getElementById('member-checkboxes'),
             locationCheckboxes: document.getElementById('location-checkboxes'),
+            saveSearchButton: document.getElementById('save-search'),
+            savedSearchURL: document.getElementById('saved-search-url'),
+            accountLink: document.getElementById('account-link'),
+            myConcertsLink: document.getElementById('my-concerts-link'),
+            myShowsLink: document.getElementById('my-shows-link'),
             resultsContainer: document.getElementById('results-container'),
             loading: document.getElementById('loading'),
             errorMessage: document.getElementById('error-message')
@@ -146,6 +212,14 @@ class App {
     }
 
     setupEventListeners() {
+        // Results update as filters change, so the form's submit button
+        // is only needed without JavaScript
+        this.elements.searchSubmit.hidden = true;
+        this.elements.searchForm.addEventListener('submit', (e) => {
+            e.preventDefault();
+            this.searchArtists(this.elements.searchInput.value);
+        });
+
         // Search input with debounce
         this.elements.searchInput.addEventListener('input', 
             this.debounce(() => this.handleSearchInput(), 300)
@@ -159,6 +233,8 @@ class App {
             () => this.handleYearInput('firstAlbum')
         );
 
+        this.elements.saveSearchButton.addEventListener('click', () => this.saveSearch());
+
         // Close suggestions on outside click
         document.addEventListener('click', (e) => {
             if (!this.elements.searchInput.contains(e.target) && 
@@ -210,16 +286,18 @@ class App {
 
     // Search and Display
     async searchArtists(query) {
+        const params = this.filterManager.toParams(query.trim());
+
+        // Keep the page URL in sync so reloading or sharing it restores
+        // the search
+        const search = params.toString();
+        history.replaceState(null, '', search ? `/?${search}` : '/');
+
         this.showLoading();
         try {
-            const data = await API.search(query, this.filterManager.getValues());
+            const data = await API.search(params);
             this.displayResults(data.artists);
-            
-            // Initialize filters if first time
-            if (!this.initialized) {
-                this.filterManager.initialize(data.artists);
-                this.initialized = true;
-            }
+            this.filterManager.applyFacets(data.facets);
         } catch (error) {
             console.error('Error:', error);
             this.showError('Failed to search artists');
@@ -228,6 +306,46 @@ class App {
         }
     }
 
+    async saveSearch() {
+        const query = this.elements.searchInput.value.trim();
+        try {
+            const saved = await API.saveSearch(this.filterManager.toParams(query));
+            const output = this.elements.savedSearchURL;
+            output.value = saved.url;
+            output.hidden = false;
+            output.select();
+            if (navigator.clipboard) {
+                navigator.clipboard.writeText(saved.url).catch(() => {});
+            }
+        } catch (error) {
+            console.error('Error:', error);
+            this.showError('Failed to save search');
+        }
+    }
+
+    // Turn the sign-in link into a sign-out one for signed-in users; guests
+    // who follow artists only get their concerts
+    async loadAccount() {
+        const account = await API.getAccount().catch(() => null);
+        if (!account) return;
+
+        this.elements.myConcertsLink.hidden = false;
+        if (account.user.guest) return;
+        this.elements.myShowsLink.hidden = false;
+        const link = this.elements.accountLink;
+        link.textContent = `Sign Out (${account.user.username})`;
+        link.addEventListener('click', async (event) => {
+            event.preventDefault();
+            try {
+                await API.logout(account.csrfToken);
+                window.location.reload();
+            } catch (error) {
+                console.error('Error:', error);
+                this.showError('Failed to sign out');
+            }
+        });
+    }
+
     displaySuggestions(suggestions) {
         this.elements.suggestionsContainer.innerHTML = '';
         
@@ -271,15 +389,15 @@ class App {
     }
 
     createArtistCard(artist) {
-        const card = document.createElement('div');
+        const card = document.createElement('a');
         card.className = 'artist-card';
+        card.href = `/artist/${artist.id}`;
         card.innerHTML = `
             <img src="placeholder.jpg" data-src="${artist.image}" alt="${artist.name}" class="lazy-image">
             <h3>${artist.name}</h3>
             <p><i class="fas fa-calendar-alt"></i> Created: ${artist.creationDate}</p>
             <p><i class="fas fa-compact-disc"></i> First Album: ${artist.firstAlbum}</p>
         `;
-        card.onclick = () => window.location.href = `/artist/${artist.id}`;
         return card;
     }
 
@@ -313,7 +431,21 @@ class App {
 }
 
 // Initialize the app
-document.addEventListener('DOMContentLoaded', () => {
+document.addEventListener('DOMContentLoaded', async () => {
     const app = new App();
+    app.loadAccount();
+
+    // The server already rendered the grid and filters; only enhance them
+    if (app.elements.resultsContainer.dataset.ssr === 'true') {
+        app.filterManager.attach();
+        return;
+    }
+
+    try {
+        app.filterManager.initialize(await API.getFilterMeta());
+    } catch (error) {
+        console.error('Error:', error);
+        app.showError('Failed to load filters');
+    }
     app.searchArtists('');
 });
\ No newline at end of file
@@ -5,11 +5,19 @@ const MAPBOX_TOKEN = 'pk.eyJ1Ijoic3RlbGxhYWNoYXJvaXJvIiwiYSI6ImNtMWhmZHNlODBlc3c
 let map;
 let activePopup = null;
 let favorites = JSON.parse(localStorage.getItem('favorites')) || [];
+// Session account, a guest's for visitors who only follow artists;
+// favorites are kept server-side for registered accounts
+let account = null;
+// Artists the visitor follows
+let follows = [];
+// Concerts the signed-in user attended, as "artistId/location/date" keys
+let attended = [];
 
 // DOM Elements
 const elements = {
     artistDetails: document.getElementById('artist-details'),
     map: document.getElementById('map'),
+    accountLink: document.getElementById('account-link'),
     loading: document.getElementById('loading'),
     errorMessage: document.getElementById('error-message')
 };
@@ -34,23 +42,146 @@ function showError(message) {
     }, 5000);
 }
 
+function signedIn() {
+    return account !== null && !account.user.guest;
+}
+
 function getArtistId() {
     const pathParts = window.location.pathname.split('/');
     return pathParts[pathParts.length - 1];
 }
 
+// Account
+async function loadAccount() {
+    try {
+        const response = await fetch('/api/me');
+        if (!response.ok) return;
+        account = await response.json();
+
+        const followsResponse = await fetch('/api/me/follows');
+        if (followsResponse.ok) {
+            follows = (await followsResponse.json()).map(follow => follow.artistId);
+        }
+        if (!signedIn()) return;
+
+        const favoritesResponse = await fetch('/api/me/favorites');
+        if (favoritesResponse.ok) {
+            favorites = (await favoritesResponse.json()).map(favorite => favorite.artistId);
+        }
+        const attendedResponse = await fetch('/api/me/attendance');
+        if (attendedResponse.ok) {
+            attended = (await attendedResponse.json()).map(a => `${a.artistId}/${a.location}/${a.date}`);
+        }
+        elements.accountLink.textContent = `Signed in as ${account.user.username}`;
+        elements.accountLink.removeAttribute('href');
+    } catch (error) {
+        console.error('Error:', error);
+    }
+}
+
 // Favorites Management
-function toggleFavorite(artistId) {
+async function toggleFavorite(artistId) {
     const index = favorites.indexOf(artistId);
+    if (signedIn()) {
+        try {
+            const response = await fetch(`/api/me/favorites/${artistId}`, {
+                method: index === -1 ? 'PUT' : 'DELETE',
+                headers: { 'X-CSRF-Token': account.csrfToken }
+            });
+            if (!response.ok) throw new Error('Failed to update favorites');
+        } catch (error) {
+            console.error('Error:', error);
+            showError('Failed to update favorites');
+            return;
+        }
+    }
+
     if (index === -1) {
         favorites.push(artistId);
     } else {
         favorites.splice(index, 1);
     }
-    localStorage.setItem('favorites', JSON.stringify(favorites));
+    if (!signedIn()) {
+        localStorage.setItem('favorites', JSON.stringify(favorites));
+    }
     updateFavoriteButton(artistId);
 }
 
+// Following; visitors without an account get a guest session first
+async function toggleFollow(artistId) {
+    const following = follows.includes(artistId);
+    try {
+        if (!account) {
+            const guestResponse = await fetch('/api/auth/guest', { method: 'POST' });
+            if (!guestResponse.ok) throw new Error('Failed to start session');
+            account = await guestResponse.json();
+        }
+        const response = await fetch(`/api/me/follows/${artistId}`, {
+            method: following ? 'DELETE' : 'PUT',
+            headers: { 'X-CSRF-Token': account.csrfToken }
+        });
+        if (!response.ok) throw new Error('Failed to update followed artists');
+    } catch (error) {
+        console.error('Error:', error);
+        showError('Failed to update followed artists');
+        return;
+    }
+
+    follows = following ? follows.filter(id => id !== artistId) : [...follows, artistId];
+    updateFollowButton(artistId);
+}
+
+function updateFollowButton(artistId) {
+    const button = document.getElementById(`follow-${artistId}`);
+    if (button) {
+        button.innerHTML = follows.includes(artistId)
+            ? '<i class="fas fa-bell-slash"></i> Unfollow'
+            : '<i class="fas fa-bell"></i> Follow';
+    }
+}
+
+// Attendance Log
+async function logShow(artistId) {
+    const concert = document.getElementById('attend-concert').value;
+    try {
+        const response = await fetch(`/api/me/attendance/${artistId}/${concert}`, {
+            method: 'PUT',
+            headers: { 'Content-Type': 'application/json', 'X-CSRF-Token': account.csrfToken },
+            body: JSON.stringify({
+                rating: parseInt(document.getElementById('attend-rating').value),
+                notes: document.getElementById('attend-notes').value
+            })
+        });
+        if (!response.ok) throw new Error('Failed to log show');
+    } catch (error) {
+        console.error('Error:', error);
+        showError('Failed to log show');
+        return;
+    }
+
+    const key = `${artistId}/${concert}`;
+    if (!attended.includes(key)) attended.push(key);
+    document.getElementById('attend-notes').value = '';
+    updateAttendForm(artistId);
+}
+
+// Show the attendance form to signed-in users, ticking attended concerts
+function updateAttendForm(artistId) {
+    const form = document.getElementById('attend-form');
+    if (!form) return;
+    form.hidden = !signedIn();
+    for (const option of document.getElementById('attend-concert').options) {
+        const label = option.textContent.replace(/^\u2713 /, '');
+        option.textContent = attended.includes(`${artistId}/${option.value}`) ? `\u2713 ${label}` : label;
+    }
+}
+
+// Turn an upstream date such as "*23-08-2019" into 2019-08-23
+function isoDate(raw) {
+    const [day, month, year] = raw.replace('*', '').split('-');
+    return `${year}-${month}-${day}`;
+}
+
 function updateFavoriteButton(artistId) {
     const button = document.getElementById(`favorite-${artistId}`);
     if (button) {
@@ -237,36 +368,94 @@ function displayArtistDetails(details) {
             </div>
         </div>
 
+        <form id="attend-form" class="filter-row attend-form" hidden>
+            <label for="attend-concert"><i class="fas fa-ticket-alt"></i> I was there:</label>
+            <select id="attend-concert">
+                ${Object.entries(details.relations)
+                    .flatMap(([loc, dates]) => dates.map(date => ({ loc, date: isoDate(date) })))
+                    .sort((a, b) => a.date.localeCompare(b.date))
+                    .map(({ loc, date }) => `<option value="${loc}/${date}">${date} &middot; ${loc}</option>`)
+                    .join('')}
+            </select>
+            <select id="attend-rating" aria-label="Rating">
+                <option value="0">No rating</option>
+                ${[5, 4, 3, 2, 1].map(n => `<option value="${n}">${n} ${n === 1 ? 'star' : 'stars'}</option>`).join('')}
+            </select>
+            <input type="text" id="attend-notes" maxlength="2000" placeholder="Notes" aria-label="Notes">
+            <button type="submit" class="save-search-button">Log Show</button>
+            <a href="/me/shows">My Shows</a>
+        </form>
+
         <div class="action-buttons">
             <button id="favorite-${details.artist.id}" 
-                    onclick="toggleFavorite(${details.artist.id})" 
                     class="favorite-button">
                 ${favorites.includes(details.artist.id) 
                     ? '<i class="fas fa-star"></i> Remove from Favorites' 
                     : '<i class="far fa-star"></i> Add to Favorites'}
             </button>
-            <button onclick='shareArtist(${JSON.stringify(details.artist)})' 
+            <button id="follow-${details.artist.id}" class="share-button">
+                <i class="fas fa-bell"></i> Follow
+            </button>
+            <button id="share-${details.artist.id}" 
                     class="share-button">
                 <i class="fas fa-share-alt"></i> Share
             </button>
+            <a href="/artist/${details.artist.id}/concerts.ics" class="calendar-button">
+                <i class="fas fa-calendar-plus"></i> Subscribe to Concerts
+            </a>
         </div>
     `;
 
+    bindActionButtons(details.artist);
     displayMap(details.locations);
 }
 
+// Attach handlers here rather than inline so the page works under CSP
+function bindActionButtons(artist) {
+    document.getElementById(`favorite-${artist.id}`)
+        .addEventListener('click', () => toggleFavorite(artist.id));
+    document.getElementById(`follow-${artist.id}`)
+        .addEventListener('click', () => toggleFollow(artist.id));
+    document.getElementById(`share-${artist.id}`)
+        .addEventListener('click', () => shareArtist(artist));
+    document.getElementById('attend-form').addEventListener('submit', (event) => {
+        event.preventDefault();
+        logShow(artist.id);
+    });
+    updateFavoriteButton(artist.id);
+    updateFollowButton(artist.id);
+    updateAttendForm(artist.id);
+}
+
+// Enhance the server-rendered details and fetch the geocoded locations for the map
+function enhanceRenderedDetails() {
+    const artist = {
+        id: parseInt(elements.artistDetails.dataset.artistId),
+        name: elements.artistDetails.querySelector('h2').textContent
+    };
+    bindActionButtons(artist);
+    return artist.id;
+}
+
 // Initial Load
 window.addEventListener('load', async () => {
-    const artistId = getArtistId();
+    await loadAccount();
+    const artistId = elements.artistDetails.dataset.ssr === 'true'
+        ? enhanceRenderedDetails()
+        : getArtistId();
     if (artistId) {
         showLoading();
         try {
-            const response = await fetch(`/api/artist/${artistId}`);
+            const response = await fetch(`/api/artists/${artistId}`);
             if (!response.ok) {
                 throw new Error('Network response was not ok');
             }
             const data = await response.json();
-            displayArtistDetails(data);
+            if (elements.artistDetails.dataset.ssr === 'true') {
+                displayMap(data.locations);
+            } else {
+                displayArtistDetails(data);
+            }
         } catch (error) {
             console.error('Error:', error);
             showError('An error occurred while fetching artist details. Please try again later.');
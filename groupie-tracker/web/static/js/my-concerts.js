@@ -0,0 +1,113 @@
+// web/static/js/my-concerts.js
+
+// Manage followed artists, the home location and the private calendar link
+const csrfToken = document.querySelector('meta[name="csrf-token"]').content;
+
+const elements = {
+    follows: document.getElementById('follows'),
+    homeForm: document.getElementById('home-form'),
+    homeLat: document.getElementById('home-lat'),
+    homeLon: document.getElementById('home-lon'),
+    homeRadius: document.getElementById('home-radius'),
+    homeLocate: document.getElementById('home-locate'),
+    homeClear: document.getElementById('home-clear'),
+    calendarCreate: document.getElementById('calendar-create'),
+    calendarRevoke: document.getElementById('calendar-revoke'),
+    calendarURL: document.getElementById('calendar-url'),
+    errorMessage: document.getElementById('error-message')
+};
+
+function showError(message) {
+    elements.errorMessage.textContent = message;
+    elements.errorMessage.style.display = 'block';
+    setTimeout(() => {
+        elements.errorMessage.style.display = 'none';
+    }, 5000);
+}
+
+// Send a request that changes state, with the session's CSRF token
+async function send(method, url, body) {
+    const headers = { 'X-CSRF-Token': csrfToken };
+    if (body !== undefined) headers['Content-Type'] = 'application/json';
+    const response = await fetch(url, {
+        method,
+        headers,
+        body: body === undefined ? undefined : JSON.stringify(body)
+    });
+    if (!response.ok) {
+        const error = await response.json().catch(() => ({}));
+        throw new Error(error.message || 'Request failed');
+    }
+    return response.status === 204 ? null : response.json();
+}
+
+elements.follows.addEventListener('click', async (event) => {
+    const button = event.target.closest('.unfollow-button');
+    if (!button) return;
+    try {
+        await send('DELETE', `/api/me/follows/${button.dataset.artistId}`);
+        window.location.reload();
+    } catch (error) {
+        showError(error.message);
+    }
+});
+
+elements.homeForm.addEventListener('submit', async (event) => {
+    event.preventDefault();
+    try {
+        await send('PUT', '/api/me/home', {
+            lat: parseFloat(elements.homeLat.value),
+            lon: parseFloat(elements.homeLon.value),
+            radiusKm: parseFloat(elements.homeRadius.value) || 0
+        });
+        window.location.reload();
+    } catch (error) {
+        showError(error.message);
+    }
+});
+
+elements.homeLocate.addEventListener('click', () => {
+    if (!navigator.geolocation) {
+        showError('Geolocation is not available');
+        return;
+    }
+    navigator.geolocation.getCurrentPosition(
+        (position) => {
+            elements.homeLat.value = position.coords.latitude.toFixed(4);
+            elements.homeLon.value = position.coords.longitude.toFixed(4);
+        },
+        () => showError('Could not get your location')
+    );
+});
+
+if (elements.homeClear) {
+    elements.homeClear.addEventListener('click', async () => {
+        try {
+            await send('DELETE', '/api/me/home');
+            window.location.reload();
+        } catch (error) {
+            showError(error.message);
+        }
+    });
+}
+
+elements.calendarCreate.addEventListener('click', async () => {
+    try {
+        const calendar = await send('POST', '/api/me/calendar');
+        elements.calendarURL.value = calendar.url;
+        elements.calendarURL.hidden = false;
+        elements.calendarURL.select();
+    } catch (error) {
+        showError(error.message);
+    }
+});
+
+elements.calendarRevoke.addEventListener('click', async () => {
+    try {
+        await send('DELETE', '/api/me/calendar');
+        elements.calendarURL.value = '';
+        elements.calendarURL.hidden = true;
+    } catch (error) {
+        showError(error.message);
+    }
+});
@@ -0,0 +1,70 @@
+// web/static/js/my-shows.js
+
+// Edit, remove, export and import the concerts the user attended
+const csrfToken = document.querySelector('meta[name="csrf-token"]').content;
+
+const elements = {
+    shows: document.getElementById('shows'),
+    importFile: document.getElementById('import-file'),
+    importButton: document.getElementById('import-button'),
+    errorMessage: document.getElementById('error-message')
+};
+
+function showError(message) {
+    elements.errorMessage.textContent = message;
+    elements.errorMessage.style.display = 'block';
+    setTimeout(() => {
+        elements.errorMessage.style.display = 'none';
+    }, 5000);
+}
+
+// Send a request that changes state, with the session's CSRF token and an
+// optional JSON body
+async function send(method, url, body) {
+    const headers = { 'X-CSRF-Token': csrfToken };
+    if (body !== undefined) headers['Content-Type'] = 'application/json';
+    const response = await fetch(url, { method, headers, body });
+    if (!response.ok) {
+        const error = await response.json().catch(() => ({}));
+        throw new Error(error.message || 'Request failed');
+    }
+    return response.status === 204 ? null : response.json();
+}
+
+elements.shows.addEventListener('click', async (event) => {
+    const entry = event.target.closest('.show-entry');
+    if (!entry) return;
+    const url = `/api/me/attendance/${entry.dataset.artistId}/${entry.dataset.concert}`;
+
+    try {
+        if (event.target.closest('.show-save')) {
+            await send('PUT', url, JSON.stringify({
+                rating: parseInt(entry.querySelector('.show-rating').value),
+                notes: entry.querySelector('.show-notes').value
+            }));
+            window.location.reload();
+        } else if (event.target.closest('.show-remove')) {
+            await send('DELETE', url);
+            window.location.reload();
+        }
+    } catch (error) {
+        showError(error.message);
+    }
+});
+
+elements.importButton.addEventListener('click', async () => {
+    const file = elements.importFile.files[0];
+    if (!file) {
+        showError('Choose an exported file first');
+        return;
+    }
+    try {
+        const result = await send('POST', '/api/me/attendance/import', await file.text());
+        if (result.skipped) {
+            alert(`Imported ${result.imported} shows; ${result.skipped.length} matched no known concert.`);
+        }
+        window.location.reload();
+    } catch (error) {
+        showError(error.message);
+    }
+});
@@ -0,0 +1,15 @@
+// web/web.go
+package web
+
+import (
+	"embed"
+	"io/fs"
+)
+
+//go:embed templates static
+var files embed.FS
+
+// Files returns the templates and static assets embedded into the binary
+func Files() fs.FS {
+	return files
+}